@@ -39,13 +39,52 @@ func init() {
 	viper.SetDefault("proxy.listenAddress", "localhost")
 	viper.SetDefault("proxy.port", 8090)
 	viper.SetDefault("proxy.mode", "release")
+	viper.SetDefault("proxy.basePath", "")
 
 	viper.SetDefault("spark.history.scheme", "http")
 	viper.SetDefault("spark.history.service", "localhost")
 	viper.SetDefault("spark.history.port", 18080)
 
 	viper.SetDefault("spark.ui.proxyBase", "/sparkui")
+	viper.SetDefault("spark.ui.upstream", "direct")
+	viper.SetDefault("spark.ui.startTimeout", "5m")
+	viper.SetDefault("spark.ui.rewriteLinks", true)
+	viper.SetDefault("spark.ui.healthProbe.enabled", true)
+	viper.SetDefault("spark.ui.healthProbe.interval", "30s")
+	viper.SetDefault("spark.ui.healthProbe.timeout", "5s")
+	viper.SetDefault("spark.ui.healthProbe.concurrency", 10)
+	viper.SetDefault("spark.ui.healthProbe.failureThreshold", 3)
 	viper.SetDefault("spark.jobNamespaces", "default")
+	viper.SetDefault("spark.operator.enabled", false)
+	viper.SetDefault("spark.discovery.labelSelectors", []string{"spark-role=driver"})
+	viper.SetDefault("spark.discovery.probeInterval", "30s")
+	viper.SetDefault("spark.discovery.ghostGracePeriod", "2m")
+	viper.SetDefault("spark.discovery.history.enabled", true)
+	viper.SetDefault("spark.discovery.history.pollInterval", "30s")
+	viper.SetDefault("spark.discovery.eventLog.enabled", false)
+	viper.SetDefault("spark.discovery.eventLog.directory", "/var/log/spark-events")
+	viper.SetDefault("spark.discovery.eventLog.scanInterval", "1m")
+	viper.SetDefault("spark.standalone.pollInterval", "15s")
+	viper.SetDefault("spark.discovery.registration.enabled", false)
+	viper.SetDefault("spark.discovery.registration.tokenFile", "/etc/spark-web-proxy/registration/tokens")
+	viper.SetDefault("spark.discovery.registration.heartbeatTimeout", "2m")
+	viper.SetDefault("spark.discovery.executors.enabled", true)
+	viper.SetDefault("spark.discovery.executors.labelSelector", "spark-role=executor")
+	viper.SetDefault("spark.discovery.executors.maxDeletedPods", 100)
+	viper.SetDefault("spark.discovery.events.enabled", true)
+	viper.SetDefault("spark.discovery.events.maxPerApp", 20)
+
+	viper.SetDefault("store.maxSize", 10000)
+	viper.SetDefault("store.ttl.unknown", "10m")
+	viper.SetDefault("store.ttl.lost", "10m")
+	viper.SetDefault("store.ttl.succeeded", "1h")
+	viper.SetDefault("store.ttl.failed", "1h")
+	viper.SetDefault("store.janitorInterval", "1m")
+	viper.SetDefault("store.shared.enabled", false)
+	viper.SetDefault("store.shared.name", "spark-web-proxy-state")
+	viper.SetDefault("store.shared.syncInterval", "1s")
+	viper.SetDefault("store.persistence.enabled", false)
+	viper.SetDefault("store.persistence.path", "/var/lib/spark-web-proxy/registry.db")
 
 	viper.SetDefault("logging.level", "info")
 	viper.SetDefault("logging.format", "console")
@@ -64,6 +103,22 @@ func init() {
 	if err := viper.BindPFlag("config", RootCmd.PersistentFlags().Lookup("config")); err != nil {
 		panic("Unable to read server configuration: " + err.Error())
 	}
+
+	RootCmd.PersistentFlags().String("kubeconfig", "", "Path to the kubeconfig file (defaults to in-cluster config)")
+	if err := viper.BindPFlag("kubernetes.kubeconfig", RootCmd.PersistentFlags().Lookup("kubeconfig")); err != nil {
+		panic("Unable to read kubeconfig flag: " + err.Error())
+	}
+	if err := viper.BindEnv("kubernetes.kubeconfig", "SPARK_WEB_PROXY_KUBECONFIG"); err != nil {
+		panic("Unable to read kubeconfig environment variable: " + err.Error())
+	}
+
+	RootCmd.PersistentFlags().String("context", "", "Name of the kubeconfig context to use")
+	if err := viper.BindPFlag("kubernetes.context", RootCmd.PersistentFlags().Lookup("context")); err != nil {
+		panic("Unable to read context flag: " + err.Error())
+	}
+	if err := viper.BindEnv("kubernetes.context", "SPARK_WEB_PROXY_KUBE_CONTEXT"); err != nil {
+		panic("Unable to read context environment variable: " + err.Error())
+	}
 }
 
 // Execute runs the root command and exits with a non-zero status on failure.
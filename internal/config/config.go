@@ -20,21 +20,27 @@ package config
 
 import (
 	"fmt"
+	"net"
 	"os"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/fsnotify/fsnotify"
 	"github.com/spf13/viper"
 
+	"github.com/okdp/spark-web-proxy/internal/constants"
 	"github.com/okdp/spark-web-proxy/internal/utils"
 )
 
 // ApplicationConfig represents the root configuration of the application.
 type ApplicationConfig struct {
-	Proxy    Proxy    `mapstructure:"proxy"`
-	Spark    Spark    `mapstructure:"spark"`
-	Security Security `mapstructure:"security"`
-	Logging  Logging  `mapstructure:"logging"`
+	Proxy      Proxy      `mapstructure:"proxy"`
+	Kubernetes Kubernetes `mapstructure:"kubernetes"`
+	Spark      Spark      `mapstructure:"spark"`
+	Store      Store      `mapstructure:"store"`
+	Security   Security   `mapstructure:"security"`
+	Logging    Logging    `mapstructure:"logging"`
 }
 
 // Proxy defines the reverse proxy server configuration.
@@ -42,13 +48,152 @@ type Proxy struct {
 	ListenAddress string `mapstructure:"listenAddress"`
 	Port          int    `mapstructure:"port"`
 	Mode          string `mapstructure:"mode"`
+	// BasePath is the external path under which the proxy is published (e.g. /spark
+	// for https://platform/spark/). The routes are registered under it, except the
+	// health and metrics endpoints.
+	BasePath string `mapstructure:"basePath"`
+}
+
+// Kubernetes defines the Kubernetes API server connection configuration.
+// When no kubeconfig is set, the in-cluster configuration is used.
+type Kubernetes struct {
+	Kubeconfig string `yaml:"kubeconfig"`
+	Context    string `yaml:"context"`
 }
 
 // Spark defines Spark-related configuration.
 type Spark struct {
-	History       History  `mapstructure:"history"`
-	UI            UI       `mapstructure:"ui"`
+	History       History    `mapstructure:"history"`
+	UI            UI         `mapstructure:"ui"`
+	Operator      Operator   `mapstructure:"operator"`
+	Discovery     Discovery  `mapstructure:"discovery"`
+	Standalone    Standalone `mapstructure:"standalone"`
+	JobNamespaces []string   `json:"jobNamespaces"`
+	Clusters      []Cluster  `json:"clusters"`
+}
+
+// Discovery defines how the Spark driver pods are discovered.
+type Discovery struct {
+	// LabelSelectors is the list of label selectors matching the Spark driver pods.
+	// A pod is discovered when it matches any of the selectors.
+	LabelSelectors []string `json:"labelSelectors"`
+	// ProbeInterval is the interval at which the pods hosting several Spark
+	// applications are probed for started or stopped applications.
+	ProbeInterval time.Duration `yaml:"probeInterval"`
+	// Namespaces defines the dynamic discovery of the namespaces where Spark jobs run.
+	Namespaces NamespaceDiscovery `yaml:"namespaces"`
+	// GhostGracePeriod is the time given to the event logs of a deleted driver pod
+	// to reach Spark History before the application is reported as a ghost.
+	GhostGracePeriod time.Duration `yaml:"ghostGracePeriod"`
+	// History defines the discovery of the running applications from Spark History.
+	History HistoryDiscovery `yaml:"history"`
+	// EventLog defines the discovery of the running applications from their event logs.
+	EventLog EventLogDiscovery `yaml:"eventLog"`
+	// Registration defines the registration API of the applications pushed by their launchers.
+	Registration Registration `yaml:"registration"`
+	// Executors defines the tracking of the Spark executor pods.
+	Executors ExecutorDiscovery `yaml:"executors"`
+	// Events defines the tracking of the Kubernetes events of the Spark pods.
+	Events EventDiscovery `yaml:"events"`
+}
+
+// EventDiscovery defines the tracking of the Kubernetes events of the driver and
+// executor pods (e.g. FailedScheduling, ErrImagePull), recorded on the applications.
+type EventDiscovery struct {
+	Enabled bool `yaml:"enabled"`
+	// MaxPerApp is the number of the recent events kept per application.
+	MaxPerApp int `yaml:"maxPerApp"`
+}
+
+// ExecutorDiscovery defines the tracking of the Spark executor pods, exposing
+// their state (e.g. Pending, Evicted, OOMKilled) through the proxy API.
+type ExecutorDiscovery struct {
+	Enabled bool `yaml:"enabled"`
+	// LabelSelector is the label selector matching the Spark executor pods.
+	LabelSelector string `yaml:"labelSelector"`
+	// MaxDeletedPods is the number of the deleted executor pods kept per application.
+	MaxDeletedPods int `yaml:"maxDeletedPods"`
+}
+
+// Registration defines the registration API letting the launchers and Spark
+// listeners register the applications which are not discovered from Kubernetes.
+type Registration struct {
+	Enabled bool `yaml:"enabled"`
+	// TokenFile is the path of the file holding the bearer tokens allowed to
+	// register the applications, one per line (e.g. a mounted Secret).
+	TokenFile string `yaml:"tokenFile"`
+	// HeartbeatTimeout is the time after which a registered application expires
+	// when it is not registered again.
+	HeartbeatTimeout time.Duration `yaml:"heartbeatTimeout"`
+	// AllowedHosts are the hosts allowed in the Spark UI URLs of the registered
+	// applications: host names, wildcard domains (e.g. *.example.com), IP addresses
+	// or CIDRs. No host is allowed when empty, and the hosts resolving to a
+	// loopback, link-local or unspecified address are always rejected.
+	AllowedHosts []string `yaml:"allowedHosts"`
+}
+
+// HistoryDiscovery defines the polling of the running applications of Spark History,
+// registering the applications not discovered from Kubernetes (e.g. client mode drivers).
+type HistoryDiscovery struct {
+	Enabled bool `yaml:"enabled"`
+	// PollInterval is the interval at which the running applications are listed.
+	PollInterval time.Duration `yaml:"pollInterval"`
+}
+
+// Standalone defines the discovery of the applications of Spark standalone clusters.
+type Standalone struct {
+	// PollInterval is the interval at which the masters are polled.
+	PollInterval time.Duration `yaml:"pollInterval"`
+	// Clusters is the list of the Spark standalone clusters.
+	Clusters []StandaloneCluster `json:"clusters"`
+}
+
+// StandaloneCluster defines a Spark standalone cluster.
+type StandaloneCluster struct {
+	Name string `yaml:"name"`
+	// MasterURLs are the web UI URLs of the masters (e.g. http://spark-master-0:8080).
+	// The alive master is polled, the standby masters are used on failover.
+	MasterURLs []string `json:"masterUrls"`
+}
+
+// EventLogDiscovery defines the discovery of the running applications from the
+// in-progress event logs of a locally mounted event log directory (spark.eventLog.dir).
+type EventLogDiscovery struct {
+	Enabled bool `yaml:"enabled"`
+	// Directory is the local path of the event log directory.
+	Directory string `yaml:"directory"`
+	// ScanInterval is the interval at which the whole directory is scanned, in
+	// addition to the file system notifications.
+	ScanInterval time.Duration `yaml:"scanInterval"`
+}
+
+// NamespaceDiscovery defines how the namespaces where Spark jobs run are
+// discovered at runtime. When enabled, spark.jobNamespaces is ignored.
+type NamespaceDiscovery struct {
+	// LabelSelector is the label selector matching the namespaces.
+	LabelSelector string `yaml:"labelSelector"`
+	// Include is the list of glob patterns the namespace names should match (all if empty).
+	Include []string `json:"include"`
+	// Exclude is the list of glob patterns of the namespace names to ignore.
+	Exclude []string `json:"exclude"`
+}
+
+// IsEnabled returns true if the namespaces are discovered dynamically.
+func (n NamespaceDiscovery) IsEnabled() bool {
+	return n.LabelSelector != "" || len(n.Include) != 0 || len(n.Exclude) != 0
+}
+
+// Cluster defines a Kubernetes cluster where Spark jobs run.
+// When the kubeconfig is not set, the Kubernetes config fallback order applies.
+type Cluster struct {
+	Name          string   `yaml:"name"`
+	Kubeconfig    string   `yaml:"kubeconfig"`
+	Context       string   `yaml:"context"`
 	JobNamespaces []string `json:"jobNamespaces"`
+	// Upstream overrides spark.ui.upstream for the cluster.
+	Upstream string `yaml:"upstream"`
+	// Namespaces overrides spark.discovery.namespaces for the cluster.
+	Namespaces NamespaceDiscovery `yaml:"namespaces"`
 }
 
 // History defines Spark History Server configuration.
@@ -61,6 +206,74 @@ type History struct {
 // UI defines Spark UI configuration
 type UI struct {
 	ProxyBase string `yaml:"proxyBase"`
+	// Upstream defines how the driver UIs are reached, one of "direct" (pod IP)
+	// or "apiServer" (Kubernetes API server pods/proxy subresource).
+	Upstream string `yaml:"upstream"`
+	// StartTimeout is the time given to a starting driver to bind its Spark UI,
+	// during which a holding page is served instead of redirecting to Spark History.
+	StartTimeout time.Duration `yaml:"startTimeout"`
+	// RewriteLinks enables the rewriting of the absolute links of the HTML and
+	// JavaScript responses of the Spark UIs which do not honour the X-Forwarded-Context header.
+	RewriteLinks bool `yaml:"rewriteLinks"`
+	// HealthProbe defines the background health probes of the driver UIs.
+	HealthProbe HealthProbe `yaml:"healthProbe"`
+}
+
+// HealthProbe defines the background health probes of the driver UIs of the
+// running applications, detecting the unreachable driver UIs before a user does.
+type HealthProbe struct {
+	Enabled bool `yaml:"enabled"`
+	// Interval is the interval between two probes of a driver UI.
+	Interval time.Duration `yaml:"interval"`
+	// Timeout is the timeout of a single probe.
+	Timeout time.Duration `yaml:"timeout"`
+	// Concurrency is the maximum number of driver UIs probed concurrently.
+	Concurrency int `yaml:"concurrency"`
+	// FailureThreshold is the number of consecutive failed probes after which
+	// the application is marked as lost.
+	FailureThreshold int `yaml:"failureThreshold"`
+}
+
+// Operator defines the Spark Operator (sparkoperator.k8s.io) discovery configuration.
+type Operator struct {
+	Enabled bool `yaml:"enabled"`
+}
+
+// Store defines the bounds of the store of the discovered Spark applications.
+type Store struct {
+	// MaxSize is the maximum number of stored applications (unbounded if 0).
+	// The least recently used completed applications are evicted first.
+	MaxSize int `yaml:"maxSize"`
+	// TTL is the time to live of the stored applications by status (e.g. unknown: 10m),
+	// since their last update. The statuses without TTL never expire.
+	TTL map[string]time.Duration `json:"ttl"`
+	// JanitorInterval is the interval at which the expired applications are evicted.
+	JanitorInterval time.Duration `yaml:"janitorInterval"`
+	// Shared defines the state shared between the proxy replicas.
+	Shared SharedStore `yaml:"shared"`
+	// Persistence defines the on-disk registry of the applications.
+	Persistence Persistence `yaml:"persistence"`
+}
+
+// Persistence defines the embedded on-disk registry persisting the applications
+// across the proxy restarts.
+type Persistence struct {
+	Enabled bool `yaml:"enabled"`
+	// Path is the path of the database file.
+	Path string `yaml:"path"`
+}
+
+// SharedStore defines the state shared between the proxy replicas through a
+// Kubernetes ConfigMap: the application statuses set by the proxy and the
+// applications resolved from Spark History.
+type SharedStore struct {
+	Enabled bool `yaml:"enabled"`
+	// Namespace of the ConfigMap, defaults to the POD_NAMESPACE environment variable.
+	Namespace string `yaml:"namespace"`
+	// Name of the ConfigMap.
+	Name string `yaml:"name"`
+	// SyncInterval is the interval at which the local changes are written to the ConfigMap.
+	SyncInterval time.Duration `yaml:"syncInterval"`
 }
 
 // Logging configuration
@@ -119,6 +332,11 @@ func GetAppConfig() *ApplicationConfig {
 			panic(err)
 		}
 
+		if err := instance.Validate(); err != nil {
+			fmt.Println("invalid configuration file")
+			panic(err)
+		}
+
 		printConfig(configFile)
 	})
 	return instance
@@ -140,6 +358,71 @@ func (c ApplicationConfig) GetSparkHistoryBaseURL() string {
 	return sparkHistoryBaseURL
 }
 
+// GetBasePath returns the external base path of the proxy with a leading slash
+// and without trailing slash, or "" when the proxy is published at the root.
+func (c ApplicationConfig) GetBasePath() string {
+	basePath := strings.Trim(strings.TrimSpace(c.Proxy.BasePath), "/")
+	if basePath == "" {
+		return ""
+	}
+	return "/" + basePath
+}
+
+// Validate checks the consistency of the configuration. The Kubernetes and the
+// Spark standalone clusters share the same namespace of names, as the applications
+// are routed and filtered by their cluster name.
+func (c ApplicationConfig) Validate() error {
+	clusterNames := make(map[string]bool)
+	for _, cluster := range c.GetClusters() {
+		if clusterNames[cluster.Name] {
+			return fmt.Errorf("duplicate Kubernetes cluster name '%s'", cluster.Name)
+		}
+		clusterNames[cluster.Name] = true
+	}
+	for _, cluster := range c.Spark.Standalone.Clusters {
+		if clusterNames[cluster.Name] {
+			return fmt.Errorf("the Spark standalone cluster name '%s' is already used by another cluster", cluster.Name)
+		}
+		clusterNames[cluster.Name] = true
+	}
+	for _, host := range c.Spark.Discovery.Registration.AllowedHosts {
+		if strings.Contains(host, "/") {
+			if _, _, err := net.ParseCIDR(host); err != nil {
+				return fmt.Errorf("invalid registration allowed host '%s': %w", host, err)
+			}
+		}
+	}
+	return nil
+}
+
+// GetClusters returns the Kubernetes clusters where Spark jobs run.
+// When no cluster is configured, a single cluster named "default" is built
+// from the kubernetes and spark.jobNamespaces configuration.
+// The clusters without upstream mode inherit spark.ui.upstream, and the clusters
+// without namespace discovery inherit spark.discovery.namespaces.
+func (c ApplicationConfig) GetClusters() []Cluster {
+	clusters := []Cluster{{
+		Name:          constants.DefaultCluster,
+		Kubeconfig:    c.Kubernetes.Kubeconfig,
+		Context:       c.Kubernetes.Context,
+		JobNamespaces: c.Spark.JobNamespaces,
+	}}
+	if len(c.Spark.Clusters) != 0 {
+		clusters = make([]Cluster, len(c.Spark.Clusters))
+		copy(clusters, c.Spark.Clusters)
+	}
+
+	for i := range clusters {
+		if clusters[i].Upstream == "" {
+			clusters[i].Upstream = c.Spark.UI.Upstream
+		}
+		if !clusters[i].Namespaces.IsEnabled() {
+			clusters[i].Namespaces = c.Spark.Discovery.Namespaces
+		}
+	}
+	return clusters
+}
+
 func printConfig(fileConfigPath string) {
 	content, err := os.ReadFile(fileConfigPath)
 	if err != nil {
@@ -18,6 +18,7 @@ package config
 
 import (
 	"testing"
+	"time"
 
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
@@ -34,6 +35,29 @@ func Test_LoadConfig_Server(t *testing.T) {
 	assert.Equal(t, "debug", proxy.Mode, "Mode")
 }
 
+func Test_GetBasePath(t *testing.T) {
+	tests := []struct {
+		basePath string
+		expected string
+	}{
+		{basePath: "", expected: ""},
+		{basePath: "/", expected: ""},
+		{basePath: "spark", expected: "/spark"},
+		{basePath: " /platform/spark/ ", expected: "/platform/spark"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.basePath, func(t *testing.T) {
+			// Given
+			config := ApplicationConfig{Proxy: Proxy{BasePath: tt.basePath}}
+			// When
+			basePath := config.GetBasePath()
+			// Then
+			assert.Equal(t, tt.expected, basePath, "GetBasePath")
+		})
+	}
+}
+
 func Test_LoadConfig_Server_Logging(t *testing.T) {
 	// Given
 	viper.Set("config", "testdata/application.yaml")
@@ -67,6 +91,16 @@ func Test_LoadConfig_Server_Headers(t *testing.T) {
 	assert.Equal(t, map[string]string{"x-frame-options": "DENY", "x-content-type-options": "nosniff"}, security.Headers, "Headers")
 }
 
+func Test_LoadConfig_Kubernetes(t *testing.T) {
+	// Given
+	viper.Set("config", "testdata/application.yaml")
+	// When
+	kubernetes := GetAppConfig().Kubernetes
+	// Then
+	assert.Equal(t, "/etc/okdp/kubeconfig", kubernetes.Kubeconfig, "kubernetes.kubeconfig")
+	assert.Equal(t, "dev", kubernetes.Context, "kubernetes.context")
+}
+
 func Test_LoadConfig_Spark(t *testing.T) {
 	// Given
 	viper.Set("config", "testdata/application.yaml")
@@ -79,4 +113,94 @@ func Test_LoadConfig_Spark(t *testing.T) {
 
 	assert.Equal(t, "/sparkui", spark.UI.ProxyBase, "spark.ui.proxyBase")
 	assert.Equal(t, []string{"default", "dev"}, spark.JobNamespaces, "spark.jobNamespaces")
+	assert.Equal(t, "okdp.io/spark-jobs=true", spark.Discovery.Namespaces.LabelSelector, "spark.discovery.namespaces.labelSelector")
+	assert.Equal(t, []string{"tenant-*"}, spark.Discovery.Namespaces.Include, "spark.discovery.namespaces.include")
+	assert.Equal(t, []string{"tenant-sandbox-*"}, spark.Discovery.Namespaces.Exclude, "spark.discovery.namespaces.exclude")
+}
+
+func Test_LoadConfig_Store(t *testing.T) {
+	// Given
+	viper.Set("config", "testdata/application.yaml")
+	// When
+	store := GetAppConfig().Store
+	// Then
+	assert.Equal(t, 500, store.MaxSize, "store.maxSize")
+	assert.Equal(t, 5*time.Minute, store.TTL["unknown"], "store.ttl.unknown")
+	assert.Equal(t, 2*time.Hour, store.TTL["failed"], "store.ttl.failed")
+	assert.Equal(t, 30*time.Second, store.JanitorInterval, "store.janitorInterval")
+}
+
+func Test_LoadConfig_Default_Cluster(t *testing.T) {
+	// Given
+	viper.Set("config", "testdata/application.yaml")
+	// When
+	clusters := GetAppConfig().GetClusters()
+	// Then
+	assert.Len(t, clusters, 1, "clusters")
+	assert.Equal(t, "default", clusters[0].Name, "clusters[0].name")
+	assert.Equal(t, "/etc/okdp/kubeconfig", clusters[0].Kubeconfig, "clusters[0].kubeconfig")
+	assert.Equal(t, "dev", clusters[0].Context, "clusters[0].context")
+	assert.Equal(t, []string{"default", "dev"}, clusters[0].JobNamespaces, "clusters[0].jobNamespaces")
+	assert.True(t, clusters[0].Namespaces.IsEnabled(), "clusters[0].namespaces")
+}
+
+func Test_Validate_Cluster_Names(t *testing.T) {
+	tests := []struct {
+		name   string
+		spark  Spark
+		hasErr bool
+	}{
+		{
+			name:  "distinct names",
+			spark: Spark{Standalone: Standalone{Clusters: []StandaloneCluster{{Name: "standalone"}}}},
+		},
+		{
+			name:   "standalone cluster named like the default cluster",
+			spark:  Spark{Standalone: Standalone{Clusters: []StandaloneCluster{{Name: "default"}}}},
+			hasErr: true,
+		},
+		{
+			name: "standalone cluster named like a Kubernetes cluster",
+			spark: Spark{
+				Clusters:   []Cluster{{Name: "dev"}, {Name: "prod"}},
+				Standalone: Standalone{Clusters: []StandaloneCluster{{Name: "prod"}}},
+			},
+			hasErr: true,
+		},
+		{
+			name:   "duplicate Kubernetes clusters",
+			spark:  Spark{Clusters: []Cluster{{Name: "dev"}, {Name: "dev"}}},
+			hasErr: true,
+		},
+		{
+			name:   "duplicate standalone clusters",
+			spark:  Spark{Standalone: Standalone{Clusters: []StandaloneCluster{{Name: "sa"}, {Name: "sa"}}}},
+			hasErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			config := ApplicationConfig{Spark: tt.spark}
+			// When
+			err := config.Validate()
+			// Then
+			assert.Equal(t, tt.hasErr, err != nil, "Validate: %v", err)
+		})
+	}
+}
+
+func Test_Validate_Registration_Allowed_Hosts(t *testing.T) {
+	// Given
+	valid := ApplicationConfig{Spark: Spark{Discovery: Discovery{Registration: Registration{
+		AllowedHosts: []string{"10.0.0.0/8", "spark.example.com", "*.example.com", "fd00::/8"},
+	}}}}
+	invalid := ApplicationConfig{Spark: Spark{Discovery: Discovery{Registration: Registration{
+		AllowedHosts: []string{"10.0.0.0/33"},
+	}}}}
+
+	// Then
+	assert.NoError(t, valid.Validate())
+	assert.Error(t, invalid.Validate())
 }
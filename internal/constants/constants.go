@@ -26,6 +26,53 @@ const (
 	HealthzURI = "/healthz"
 	// ReadinessURI is the readiness probe endpoint.
 	ReadinessURI = "/readiness"
+	// MetricsURI is the Prometheus metrics endpoint.
+	MetricsURI = "/metrics"
+	// StandaloneBase is the base path of the Spark standalone worker UIs.
+	StandaloneBase = "/standalone"
+	// SparkReverseProxyBase is the path prefix of the links of the Spark UIs with spark.ui.reverseProxy enabled.
+	SparkReverseProxyBase = "/proxy"
+	// ProxyAPIBase is the base path of the proxy REST API.
+	ProxyAPIBase = "/proxy-api/v1"
+	// DefaultCluster is the name of the Kubernetes cluster used when no cluster is configured.
+	DefaultCluster = "default"
+	// UpstreamDirect reaches the Spark driver UIs using the pod IPs.
+	UpstreamDirect = "direct"
+	// UpstreamAPIServer reaches the Spark driver UIs through the Kubernetes API server proxy subresources.
+	UpstreamAPIServer = "apiServer"
+	// DefaultDriverLabelSelector is the label selector of the Spark driver pods set by spark-submit.
+	DefaultDriverLabelSelector = "spark-role=driver"
+	// DefaultExecutorLabelSelector is the label selector of the Spark executor pods set by the driver.
+	DefaultExecutorLabelSelector = "spark-role=executor"
+	// SparkAppSelectorLabel is the label of the Spark pods holding the Spark application ID.
+	SparkAppSelectorLabel = "spark-app-selector"
+	// SparkExecIDLabel is the label of the Spark executor pods holding the executor ID.
+	SparkExecIDLabel = "spark-exec-id"
+	// UnknownSparkAppID is the Spark application ID of a driver pod without SPARK_APPLICATION_ID.
+	UnknownSparkAppID = "-1"
+	// DefaultSparkUIPort is the default Spark UI port (spark.ui.port).
+	DefaultSparkUIPort = 4040
 	// True represents the string value "true".
 	True = "true"
 )
+
+// Driver pod annotations overriding the Spark UI and application discovery heuristics.
+const (
+	// AnnotationUIPort overrides the Spark UI port of the driver pod.
+	AnnotationUIPort = "spark-web-proxy.okdp.io/ui-port"
+	// AnnotationUIPorts lists the Spark UI ports of a pod hosting several Spark applications
+	// (e.g. "4040-4045,4050"). Each port is probed for live Spark applications.
+	AnnotationUIPorts = "spark-web-proxy.okdp.io/ui-ports"
+	// AnnotationUIScheme overrides the Spark UI scheme (http or https) of the driver pod.
+	AnnotationUIScheme = "spark-web-proxy.okdp.io/ui-scheme"
+	// AnnotationUIBasePath sets the path under which the Spark UI is served by the driver pod.
+	AnnotationUIBasePath = "spark-web-proxy.okdp.io/ui-base-path"
+	// AnnotationAppID overrides the Spark application ID of the driver pod.
+	AnnotationAppID = "spark-web-proxy.okdp.io/app-id"
+	// AnnotationAppName sets the display name of the Spark application.
+	AnnotationAppName = "spark-web-proxy.okdp.io/app-name"
+	// AnnotationUIReverseProxy tells whether the Spark UI runs with spark.ui.reverseProxy enabled ("true" or "false").
+	AnnotationUIReverseProxy = "spark-web-proxy.okdp.io/ui-reverse-proxy"
+	// AnnotationUIProxyBase sets the spark.ui.proxyBase of the Spark UI.
+	AnnotationUIProxyBase = "spark-web-proxy.okdp.io/ui-proxy-base"
+)
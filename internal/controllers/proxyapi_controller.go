@@ -0,0 +1,121 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/okdp/spark-web-proxy/internal/config"
+	"github.com/okdp/spark-web-proxy/internal/discovery"
+	log "github.com/okdp/spark-web-proxy/internal/logging"
+	"github.com/okdp/spark-web-proxy/internal/model"
+	"github.com/okdp/spark-web-proxy/internal/store"
+)
+
+// ProxyAPIController serves the proxy REST API exposing the applications tracked
+// by the proxy, with their lifecycle state and the driver UI health probe results.
+type ProxyAPIController struct {
+	store        store.Store
+	executorPods *store.ExecutorPodStore
+}
+
+// NewProxyAPIController creates a ProxyAPIController using the application configuration,
+// the store of the discovered applications and the store of their executor pods.
+func NewProxyAPIController(_ *config.ApplicationConfig, sparkApps store.Store, executorPods *store.ExecutorPodStore) *ProxyAPIController {
+	return &ProxyAPIController{
+		store:        sparkApps,
+		executorPods: executorPods,
+	}
+}
+
+// ListApplications returns the tracked applications sorted by application ID,
+// optionally filtered by the cluster, namespace, user, source and status query
+// parameters (e.g. ?namespace=spark&status=Lost).
+func (r ProxyAPIController) ListApplications(c *gin.Context) {
+	filter := store.Filter{
+		Cluster:   c.Query("cluster"),
+		Namespace: c.Query("namespace"),
+		User:      c.Query("user"),
+		Source:    c.Query("source"),
+	}
+	status := c.Query("status")
+
+	sparkApps := r.store.List(filter)
+	filtered := sparkApps[:0]
+	for _, sparkApp := range sparkApps {
+		if status == "" || sparkApp.Status == status {
+			filtered = append(filtered, sparkApp)
+		}
+	}
+	sort.Slice(filtered, func(i, j int) bool { return filtered[i].AppID < filtered[j].AppID })
+
+	c.JSON(http.StatusOK, filtered)
+}
+
+// GetApplication returns the tracked application with the appID path parameter.
+func (r ProxyAPIController) GetApplication(c *gin.Context) {
+	appID := c.Param("appID")
+	sparkApp, found := r.store.Get(appID)
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("The application '%s' is not tracked by the proxy", appID)})
+		return
+	}
+	c.JSON(http.StatusOK, sparkApp)
+}
+
+// ListExecutors returns the executors of the tracked application with the appID
+// path parameter, joining the executors reported by the driver UI with the state
+// of their pods. The executor pods are returned alone when the driver UI does not answer.
+func (r ProxyAPIController) ListExecutors(c *gin.Context) {
+	appID := c.Param("appID")
+	sparkApp, found := r.store.Get(appID)
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("The application '%s' is not tracked by the proxy", appID)})
+		return
+	}
+
+	executors, err := discovery.ResolveExecutors(sparkApp, r.executorPods)
+	if err != nil {
+		log.Warn("Unable to get the executors of the application '%s' from its spark ui at %s: %v", appID, sparkApp.BaseURL, err)
+	}
+	c.JSON(http.StatusOK, executors)
+}
+
+// ListEvents returns the recent Kubernetes events of the driver and executor pods
+// of the tracked application with the appID path parameter, oldest first,
+// optionally filtered by the type query parameter (e.g. ?type=Warning).
+func (r ProxyAPIController) ListEvents(c *gin.Context) {
+	appID := c.Param("appID")
+	sparkApp, found := r.store.Get(appID)
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("The application '%s' is not tracked by the proxy", appID)})
+		return
+	}
+
+	eventType := c.Query("type")
+	events := make([]model.PodEvent, 0, len(sparkApp.Events))
+	for _, event := range sparkApp.Events {
+		if eventType == "" || event.Type == eventType {
+			events = append(events, event)
+		}
+	}
+	c.JSON(http.StatusOK, events)
+}
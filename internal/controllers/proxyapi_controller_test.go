@@ -0,0 +1,94 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/okdp/spark-web-proxy/internal/config"
+	"github.com/okdp/spark-web-proxy/internal/model"
+	"github.com/okdp/spark-web-proxy/internal/store"
+)
+
+func Test_ProxyAPIController(t *testing.T) {
+	// Given: the applications tracked by the proxy, one of them unreachable
+	sparkApps := store.NewMemoryStore()
+	sparkApps.Put(&model.SparkAppInstance{AppID: "spark-3", Status: "Running", Namespace: "batch", Source: model.SourcePod})
+	sparkApps.Put(&model.SparkAppInstance{AppID: "spark-1", Status: "Running", Namespace: "spark", Source: model.SourcePod,
+		Health: &model.Health{Reachable: true, LatencyMillis: 12}})
+	sparkApps.Put(&model.SparkAppInstance{AppID: "spark-2", Status: "Lost", Namespace: "spark", Source: model.SourcePod,
+		Health: &model.Health{ConsecutiveFailures: 3, LastError: "connection refused"}})
+	proxyAPI := NewProxyAPIController(&config.ApplicationConfig{}, sparkApps, nil)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/applications", proxyAPI.ListApplications)
+	r.GET("/applications/:appID", proxyAPI.GetApplication)
+
+	tests := []struct {
+		name     string
+		path     string
+		expected []string
+	}{
+		{"all the applications", "/applications", []string{"spark-1", "spark-2", "spark-3"}},
+		{"applications of a namespace", "/applications?namespace=spark", []string{"spark-1", "spark-2"}},
+		{"applications of a status", "/applications?status=Lost", []string{"spark-2"}},
+		{"applications of a namespace and a status", "/applications?namespace=batch&status=Lost", []string{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// When
+			response := httptest.NewRecorder()
+			r.ServeHTTP(response, httptest.NewRequest(http.MethodGet, test.path, nil))
+
+			// Then: the applications are sorted by application ID
+			assert.Equal(t, http.StatusOK, response.Code, "status code")
+			listed := []*model.SparkAppInstance{}
+			assert.NoError(t, json.Unmarshal(response.Body.Bytes(), &listed))
+			appIDs := make([]string, 0, len(listed))
+			for _, sparkApp := range listed {
+				appIDs = append(appIDs, sparkApp.AppID)
+			}
+			assert.Equal(t, test.expected, appIDs, "applications")
+		})
+	}
+
+	// When
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/applications/spark-2", nil))
+
+	// Then: the health probe results are returned with the application
+	assert.Equal(t, http.StatusOK, response.Code, "status code")
+	sparkApp := &model.SparkAppInstance{}
+	assert.NoError(t, json.Unmarshal(response.Body.Bytes(), sparkApp))
+	assert.Equal(t, "Lost", sparkApp.Status, "Status")
+	assert.Equal(t, &model.Health{ConsecutiveFailures: 3, LastError: "connection refused"}, sparkApp.Health, "Health")
+
+	// When
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/applications/spark-4", nil))
+
+	// Then
+	assert.Equal(t, http.StatusNotFound, response.Code, "status code")
+}
@@ -0,0 +1,102 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/okdp/spark-web-proxy/internal/discovery"
+	"github.com/okdp/spark-web-proxy/internal/model"
+	"github.com/okdp/spark-web-proxy/internal/security"
+)
+
+// RegistrationsController handles the registrations of the applications pushed
+// by their launchers or Spark listeners.
+type RegistrationsController struct {
+	registrations *discovery.Registrations
+}
+
+// registrationResponse is a registered application with its expiry time.
+type registrationResponse struct {
+	*model.SparkAppInstance
+	ExpiresAtEpoch int64 `json:"expiresAtEpoch"`
+}
+
+// NewRegistrationsController creates a RegistrationsController using the given registrations.
+func NewRegistrationsController(registrations *discovery.Registrations) *RegistrationsController {
+	return &RegistrationsController{
+		registrations: registrations,
+	}
+}
+
+// Register registers the application of the JSON body, or refreshes its heartbeat
+// if it is already registered. It returns 201 when the application is created,
+// 200 on a heartbeat, 403 when the application was registered with another token,
+// and 409 when the application is tracked from another source.
+func (r RegistrationsController) Register(c *gin.Context) {
+	registration := discovery.Registration{}
+	if err := c.ShouldBindJSON(&registration); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid registration: %v", err)})
+		return
+	}
+	registration.Registrant = c.GetString(security.TokenFingerprintKey)
+
+	sparkApp, created, err := r.registrations.Register(registration)
+	switch {
+	case errors.Is(err, discovery.ErrInvalidRegistration):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, discovery.ErrRegistrationForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, discovery.ErrRegistrationConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	c.JSON(status, registrationResponse{
+		SparkAppInstance: sparkApp,
+		ExpiresAtEpoch:   r.registrations.ExpiresAt(sparkApp).UnixMilli(),
+	})
+}
+
+// Unregister removes the registered application with the appID path parameter.
+// It returns 403 when the application was registered with another token.
+func (r RegistrationsController) Unregister(c *gin.Context) {
+	appID := c.Param("appID")
+	registered, err := r.registrations.Unregister(appID, c.GetString(security.TokenFingerprintKey))
+	switch {
+	case !registered:
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("The application '%s' is not registered", appID)})
+		return
+	case err != nil:
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
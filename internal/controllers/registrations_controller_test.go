@@ -0,0 +1,93 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/okdp/spark-web-proxy/internal/config"
+	"github.com/okdp/spark-web-proxy/internal/discovery"
+	log "github.com/okdp/spark-web-proxy/internal/logging"
+	"github.com/okdp/spark-web-proxy/internal/model"
+	"github.com/okdp/spark-web-proxy/internal/security"
+	"github.com/okdp/spark-web-proxy/internal/store"
+)
+
+func Test_RegistrationsController(t *testing.T) {
+	// Given: the registration API and an application discovered from its driver pod
+	log.SetupGlobalLogger(config.Logging{Level: "info"})
+	sparkApps := store.NewMemoryStore()
+	sparkApps.Put(&model.SparkAppInstance{AppID: "spark-2", Status: string(model.AppRunning), Source: model.SourcePod})
+	registrations := discovery.NewRegistrations(config.Registration{AllowedHosts: []string{"10.0.0.0/8"}}, sparkApps)
+	registrationsAPI := NewRegistrationsController(registrations)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	authenticated := r.Group("/registrations", security.BearerTokenAuth([]string{"token-a", "token-b"}))
+	authenticated.POST("", registrationsAPI.Register)
+	authenticated.DELETE("/:appID", registrationsAPI.Unregister)
+
+	tests := []struct {
+		name          string
+		method        string
+		path          string
+		body          string
+		authorization string
+		expected      int
+	}{
+		{"register", http.MethodPost, "/registrations", `{"appId": "spark-1", "uiUrl": "http://10.0.0.1:4040"}`, "Bearer token-a", http.StatusCreated},
+		{"heartbeat", http.MethodPost, "/registrations", `{"appId": "spark-1", "uiUrl": "http://10.0.0.1:4040"}`, "Bearer token-a", http.StatusOK},
+		{"register with another token", http.MethodPost, "/registrations", `{"appId": "spark-1", "uiUrl": "http://10.0.0.9:4040"}`, "Bearer token-b", http.StatusForbidden},
+		{"register a discovered application", http.MethodPost, "/registrations", `{"appId": "spark-2", "uiUrl": "http://10.0.0.2:4040"}`, "Bearer token-a", http.StatusConflict},
+		{"register a host out of the allowed hosts", http.MethodPost, "/registrations", `{"appId": "spark-3", "uiUrl": "http://192.168.0.1:4040"}`, "Bearer token-a", http.StatusBadRequest},
+		{"register an invalid body", http.MethodPost, "/registrations", `{"appId":`, "Bearer token-a", http.StatusBadRequest},
+		{"register without token", http.MethodPost, "/registrations", `{"appId": "spark-3", "uiUrl": "http://10.0.0.3:4040"}`, "", http.StatusUnauthorized},
+		{"unregister without token", http.MethodDelete, "/registrations/spark-1", "", "", http.StatusUnauthorized},
+		{"unregister with another token", http.MethodDelete, "/registrations/spark-1", "", "Bearer token-b", http.StatusForbidden},
+		{"unregister", http.MethodDelete, "/registrations/spark-1", "", "Bearer token-a", http.StatusNoContent},
+		{"unregister an unknown application", http.MethodDelete, "/registrations/spark-1", "", "Bearer token-a", http.StatusNotFound},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// When
+			request := httptest.NewRequest(test.method, test.path, strings.NewReader(test.body))
+			if test.authorization != "" {
+				request.Header.Set("Authorization", test.authorization)
+			}
+			response := httptest.NewRecorder()
+			r.ServeHTTP(response, request)
+
+			// Then
+			assert.Equal(t, test.expected, response.Code, "status code: %s", response.Body.String())
+			if test.expected == http.StatusCreated || test.expected == http.StatusOK {
+				registered := map[string]any{}
+				assert.NoError(t, json.Unmarshal(response.Body.Bytes(), &registered))
+				assert.Equal(t, "spark-1", registered["appId"], "appId")
+				assert.Contains(t, registered, "expiresAtEpoch", "expiresAtEpoch")
+				assert.NotContains(t, registered, "registrant", "registrant")
+			}
+		})
+	}
+}
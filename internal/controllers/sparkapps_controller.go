@@ -20,25 +20,35 @@ package controllers
 import (
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
 	"github.com/okdp/spark-web-proxy/internal/config"
+	"github.com/okdp/spark-web-proxy/internal/discovery"
 	sparkclient "github.com/okdp/spark-web-proxy/internal/discovery/resolvers/rest"
 	log "github.com/okdp/spark-web-proxy/internal/logging"
 	"github.com/okdp/spark-web-proxy/internal/model"
+	"github.com/okdp/spark-web-proxy/internal/store"
 	"github.com/okdp/spark-web-proxy/internal/utils"
 )
 
 // SparkAppsController handles requests related to Spark applications.
 type SparkAppsController struct {
 	sparkHistoryBaseURL string
+	ghostGracePeriod    time.Duration
+	store               store.Store
+	resolver            *discovery.SparkAppResolver
 }
 
-// NewSparkAppsController creates a SparkAppsController using the application configuration.
-func NewSparkAppsController(config *config.ApplicationConfig) *SparkAppsController {
+// NewSparkAppsController creates a SparkAppsController using the application configuration
+// and the store of the discovered applications.
+func NewSparkAppsController(config *config.ApplicationConfig, sparkApps store.Store) *SparkAppsController {
 	return &SparkAppsController{
 		sparkHistoryBaseURL: config.GetSparkHistoryBaseURL(),
+		ghostGracePeriod:    config.Spark.Discovery.GhostGracePeriod,
+		store:               sparkApps,
+		resolver:            discovery.NewSparkAppResolver(sparkApps),
 	}
 }
 
@@ -51,9 +61,11 @@ func NewSparkAppsController(config *config.ApplicationConfig) *SparkAppsControll
 //
 // The handler:
 //  1. Fetches applications from Spark History Server
-//  2. Discovers running Spark applications from Kubernetes
+//  2. Discovers running Spark applications from all the Kubernetes clusters
 //  3. Queries each running application's Spark UI for live application metadata
 //  4. Merges history and live applications into a single list, de-duplicated by app ID
+//  5. Adds the "ghost" applications whose driver pod was deleted but which never
+//     reached Spark History, with the driver termination
 //
 // If an application exists in both Spark History and the live runtime, the Spark History
 // representation is preferred.
@@ -76,7 +88,7 @@ func (r SparkAppsController) HandleIncompleteApplications(c *gin.Context) {
 		return
 	}
 
-	runningApps := model.GetRunningSparkApps()
+	runningApps := r.store.List(store.Filter{Running: true})
 	uncompletedApps := make([]model.SparkApp, 0, len(runningApps))
 	for _, running := range runningApps {
 		sparkClient, err := sparkclient.NewSparkRestClient(c.Request, running.BaseURL)
@@ -84,6 +96,7 @@ func (r SparkAppsController) HandleIncompleteApplications(c *gin.Context) {
 			log.Warn("Unable to create new spark app client: %+v", err)
 			continue
 		}
+		sparkClient.Client.Transport = discovery.SparkUITransport(running)
 
 		app, err := sparkClient.GetApplicationInfo(running.AppID)
 		if err != nil {
@@ -95,5 +108,15 @@ func (r SparkAppsController) HandleIncompleteApplications(c *gin.Context) {
 
 	running := utils.MergeByKey(*historyApps, uncompletedApps, func(a model.SparkApp) string { return a.ID })
 
+	ghosts, err := r.resolver.ResolveGhostSparkApps(c.Request, r.sparkHistoryBaseURL, r.ghostGracePeriod)
+	if err != nil {
+		log.Warn("Unable to cross-check the deleted applications with spark history from upstream URL %s: %v", r.sparkHistoryBaseURL, err)
+	}
+	ghostApps := make([]model.SparkApp, 0, len(ghosts))
+	for _, ghost := range ghosts {
+		ghostApps = append(ghostApps, ghost.GhostSparkApp())
+	}
+	running = utils.MergeByKey(running, ghostApps, func(a model.SparkApp) string { return a.ID })
+
 	c.JSON(http.StatusOK, running)
 }
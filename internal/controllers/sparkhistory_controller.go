@@ -29,24 +29,31 @@ import (
 	"github.com/okdp/spark-web-proxy/internal/constants"
 	"github.com/okdp/spark-web-proxy/internal/discovery"
 	log "github.com/okdp/spark-web-proxy/internal/logging"
-	"github.com/okdp/spark-web-proxy/internal/model"
 	"github.com/okdp/spark-web-proxy/internal/spark"
+	"github.com/okdp/spark-web-proxy/internal/store"
 )
 
 // SparkHistoryController handles requests that are routed to the Spark History Server
 // and manages redirects to the Spark UI when an application is still running.
 type SparkHistoryController struct {
+	basePath            string
 	sparkHistoryBaseURL string
 	sparkHistoryBase    string
 	sparkUIProxyBase    string
+	store               store.Store
+	resolver            *discovery.SparkAppResolver
 }
 
-// NewSparkHistoryController creates a SparkHistoryController using the application configuration.
-func NewSparkHistoryController(config *config.ApplicationConfig) *SparkHistoryController {
+// NewSparkHistoryController creates a SparkHistoryController using the application configuration
+// and the store of the discovered applications.
+func NewSparkHistoryController(config *config.ApplicationConfig, sparkApps store.Store) *SparkHistoryController {
 	controller := &SparkHistoryController{
+		basePath:            config.GetBasePath(),
 		sparkHistoryBaseURL: config.GetSparkHistoryBaseURL(),
 		sparkHistoryBase:    constants.SparkHistoryBase,
 		sparkUIProxyBase:    strings.TrimSpace(config.Spark.UI.ProxyBase),
+		store:               sparkApps,
+		resolver:            discovery.NewSparkAppResolver(sparkApps),
 	}
 
 	log.Info("Spark History K8S Service URL: %s, Spark UI Proxy base: %s", controller.sparkHistoryBaseURL, controller.sparkUIProxyBase)
@@ -54,17 +61,20 @@ func NewSparkHistoryController(config *config.ApplicationConfig) *SparkHistoryCo
 }
 
 // HandleHistoryApp handles Spark History application routes (e.g. /history/:appID/*path).
-// If the application is still running, it redirects to the Spark UI; otherwise it
-// proxies the request to the Spark History Server.
+// If the application is not completed yet (including a starting driver), it redirects
+// to the Spark UI; otherwise it proxies the request to the Spark History Server.
 func (r SparkHistoryController) HandleHistoryApp(c *gin.Context) {
 
 	appID := c.Param("appID")
 	jobPath := c.Param("path")
 
-	sparkApp, found := model.GetSparkApp(appID)
+	sparkApp, found := r.store.Get(appID)
+	if found {
+		r.store.Touch(appID)
+	}
 
-	// The application was started in cluster mode and is running
-	if found && sparkApp.IsRunning() {
+	// The application was started in cluster mode and is not completed yet
+	if found && !sparkApp.IsCompleted() {
 		r.redirectToSparkUI(c, appID)
 		return
 	}
@@ -72,7 +82,7 @@ func (r SparkHistoryController) HandleHistoryApp(c *gin.Context) {
 	// The application was started in client or cluster mode and was not present locally
 	if !found {
 		log.Debug("The application '%s' was not found locally, checking in spark history ...", appID)
-		sparkApp, _ := discovery.ResolveSparkAppFromHistory(c.Request, r.sparkHistoryBaseURL, appID)
+		sparkApp, _ := r.resolver.ResolveSparkAppFromHistory(c.Request, r.sparkHistoryBaseURL, appID)
 		if sparkApp.IsRunning() {
 			r.redirectToSparkUI(c, appID)
 			return
@@ -87,7 +97,8 @@ func (r SparkHistoryController) HandleHistoryApp(c *gin.Context) {
 		return
 	}
 
-	spark.ServeSparkHistory(c, upstreamURL, appID)
+	r.forwardBasePath(c)
+	spark.ServeSparkHistory(c, upstreamURL, appID, r.basePath)
 }
 
 // HandleDefault proxies non-application Spark History routes to the Spark History Server.
@@ -103,8 +114,8 @@ func (r SparkHistoryController) HandleIncompleteApps(c *gin.Context) {
 
 // serveSparkHistory proxies the current request path to the Spark History Server
 // using the provided serve function.
-func (r SparkHistoryController) serveSparkHistory(c *gin.Context, serve func(*gin.Context, *url.URL, string)) {
-	path := c.Request.URL.Path
+func (r SparkHistoryController) serveSparkHistory(c *gin.Context, serve func(*gin.Context, *url.URL, string, string)) {
+	path := strings.TrimPrefix(c.Request.URL.Path, r.basePath)
 
 	upstreamURL, err := url.Parse(r.sparkHistoryBaseURL + path)
 	if err != nil {
@@ -114,12 +125,21 @@ func (r SparkHistoryController) serveSparkHistory(c *gin.Context, serve func(*gi
 		return
 	}
 
-	serve(c, upstreamURL, "")
+	r.forwardBasePath(c)
+	serve(c, upstreamURL, "", r.basePath)
+}
+
+// forwardBasePath sets the X-Forwarded-Context header to the external base path,
+// so that Spark History generates its links under it.
+func (r SparkHistoryController) forwardBasePath(c *gin.Context) {
+	if r.basePath != "" {
+		c.Request.Header.Set("X-Forwarded-Context", r.basePath)
+	}
 }
 
 // redirectToSparkUI redirects the client to the proxied Spark UI jobs page for the given app ID.
 func (r SparkHistoryController) redirectToSparkUI(c *gin.Context, appID string) {
-	c.Request.URL.Path = fmt.Sprintf("%s/%s/jobs/", r.sparkUIProxyBase, appID)
+	c.Request.URL.Path = fmt.Sprintf("%s%s/%s/jobs/", r.basePath, r.sparkUIProxyBase, appID)
 	log.Debug("The application '%s' is running, redirect to spark ui '%s'", appID, c.Request.URL.String())
 	c.Redirect(http.StatusFound, c.Request.URL.String())
 }
@@ -22,6 +22,7 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
@@ -31,33 +32,50 @@ import (
 	log "github.com/okdp/spark-web-proxy/internal/logging"
 	"github.com/okdp/spark-web-proxy/internal/model"
 	"github.com/okdp/spark-web-proxy/internal/spark"
+	"github.com/okdp/spark-web-proxy/internal/spark/proxy"
+	"github.com/okdp/spark-web-proxy/internal/store"
 )
 
 // SparkUIController handles requests routed to running Spark application UIs
 // and redirects completed applications to Spark History.
 type SparkUIController struct {
+	basePath            string
 	sparkHistoryBaseURL string
 	sparkHistoryBase    string
 	sparkUIProxyBase    string
+	startTimeout        time.Duration
+	rewriteLinks        bool
+	store               store.Store
+	resolver            *discovery.SparkAppResolver
 }
 
-// NewSparkUIController creates a SparkUIController using the application configuration.
-func NewSparkUIController(config *config.ApplicationConfig) *SparkUIController {
+// NewSparkUIController creates a SparkUIController using the application configuration
+// and the store of the discovered applications.
+func NewSparkUIController(config *config.ApplicationConfig, sparkApps store.Store) *SparkUIController {
 	return &SparkUIController{
+		basePath:            config.GetBasePath(),
 		sparkHistoryBaseURL: config.GetSparkHistoryBaseURL(),
 		sparkHistoryBase:    constants.SparkHistoryBase,
 		sparkUIProxyBase:    strings.TrimSpace(config.Spark.UI.ProxyBase),
+		startTimeout:        config.Spark.UI.StartTimeout,
+		rewriteLinks:        config.Spark.UI.RewriteLinks,
+		store:               sparkApps,
+		resolver:            discovery.NewSparkAppResolver(sparkApps),
 	}
 }
 
 // HandleRunningApp handles Spark UI routes for running applications.
 // If the application is completed, the request is redirected to Spark History;
-// otherwise, it is proxied to the live Spark UI.
+// if its driver pod is not running yet, the "Spark UI is starting" page is served;
+// otherwise, it is proxied to the live Spark UI through the cluster where the driver runs.
 func (r SparkUIController) HandleRunningApp(c *gin.Context) {
 	appID := c.Param("appID")
 	sparkAppPath := strings.TrimPrefix(c.Param("path"), "/")
 
-	sparkApp, found := model.GetSparkApp(appID)
+	sparkApp, found := r.store.Get(appID)
+	if found {
+		r.store.Touch(appID)
+	}
 
 	// The application was started in cluster or client mode and was completed
 	if found && sparkApp.IsCompleted() {
@@ -68,34 +86,50 @@ func (r SparkUIController) HandleRunningApp(c *gin.Context) {
 	// The application was started in client or cluster mode and was not present locally
 	if !found {
 		log.Debug("The application '%s' was not found locally, checking in spark history ...", appID)
-		sparkApp, _ = discovery.ResolveSparkAppFromHistory(c.Request, r.sparkHistoryBaseURL, appID)
+		sparkApp, _ = r.resolver.ResolveSparkAppFromHistory(c.Request, r.sparkHistoryBaseURL, appID)
 		if sparkApp.IsCompleted() {
 			r.redirectToSparkHistory(c, appID)
 			return
 		}
 	}
 
+	// The driver pod is not running yet
+	if sparkApp.Status == string(model.AppPending) || (sparkApp.IsStarting() && sparkApp.BaseURL == "") {
+		log.Debug("The application '%s' is %s, the spark ui is not ready yet", appID, sparkApp.Status)
+		proxy.ServeStartingPage(c.Writer, c.Request, sparkApp)
+		return
+	}
+
 	sparkkUI := fmt.Sprintf("%s/%s", sparkApp.BaseURL, sparkAppPath)
 	upstreamURL, err := url.Parse(sparkkUI)
 	if err != nil {
 		log.Error("Invalid spark ui URL '%s' for the application '%s', redirect to spark history", sparkkUI, appID)
-		model.MakeSparkAppCompleted(appID)
+		store.MarkLost(r.store, appID)
 		r.redirectToSparkHistory(c, appID)
 		return
 	}
 
-	if r.sparkUIProxyBase != "/proxy" {
-		sparkUIRoot := fmt.Sprintf("%s/%s", r.sparkUIProxyBase, appID)
-		c.Request.Header.Add("X-Forwarded-Context", sparkUIRoot)
-	}
+	// The Spark UIs without spark.ui.reverseProxy nor spark.ui.proxyBase prefix their links with the forwarded context
+	sparkUIRoot := fmt.Sprintf("%s%s/%s", r.basePath, r.sparkUIProxyBase, appID)
+	c.Request.Header.Set("X-Forwarded-Context", sparkUIRoot)
+
+	handler := spark.NewSparkUIHandler(sparkApp.UIRoot(), sparkUIRoot, r.rewriteLinks)
+	spark.ServeSparkUI(c, upstreamURL, appID, r.basePath, handler, discovery.SparkUITransport(sparkApp), r.store, r.startTimeout)
+}
 
-	spark.ServeSparkUI(c, upstreamURL, appID)
+// HandleReverseProxyLink redirects the links of the Spark UIs running with
+// spark.ui.reverseProxy (/proxy/<app-id>/...) to the proxy base, when the proxy
+// base is not /proxy, so that both styles of Spark UIs are served under it.
+func (r SparkUIController) HandleReverseProxyLink(c *gin.Context) {
+	c.Request.URL.Path = fmt.Sprintf("%s%s/%s%s", r.basePath, r.sparkUIProxyBase, c.Param("appID"), c.Param("path"))
+	log.Debug("Redirecting the reverse proxy link of the application '%s' to '%s'", c.Param("appID"), c.Request.URL.String())
+	c.Redirect(http.StatusTemporaryRedirect, c.Request.URL.String())
 }
 
 // redirectToSparkHistory redirects the client to the Spark History page
 // for the given application ID.
 func (r SparkUIController) redirectToSparkHistory(c *gin.Context, appID string) {
-	c.Request.URL.Path = strings.ReplaceAll(c.Request.URL.Path, r.sparkUIProxyBase, r.sparkHistoryBase)
+	c.Request.URL.Path = strings.Replace(c.Request.URL.Path, r.basePath+r.sparkUIProxyBase, r.basePath+r.sparkHistoryBase, 1)
 	log.Debug("The application '%s' was completed, redirect to spark history '%s'", appID, c.Request.URL.String())
 	c.Redirect(http.StatusFound, c.Request.URL.String())
 }
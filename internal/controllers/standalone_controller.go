@@ -0,0 +1,81 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/okdp/spark-web-proxy/internal/config"
+	"github.com/okdp/spark-web-proxy/internal/constants"
+	"github.com/okdp/spark-web-proxy/internal/discovery"
+	log "github.com/okdp/spark-web-proxy/internal/logging"
+	"github.com/okdp/spark-web-proxy/internal/spark"
+)
+
+// StandaloneController handles the requests routed to the worker UIs of the
+// Spark standalone clusters.
+type StandaloneController struct {
+	basePath string
+	clusters map[string]*discovery.StandaloneDiscovery
+}
+
+// NewStandaloneController creates a StandaloneController proxying the worker UIs
+// reported by the given Spark standalone cluster discoveries.
+func NewStandaloneController(config *config.ApplicationConfig, clusters ...*discovery.StandaloneDiscovery) *StandaloneController {
+	controller := &StandaloneController{
+		basePath: config.GetBasePath(),
+		clusters: make(map[string]*discovery.StandaloneDiscovery, len(clusters)),
+	}
+	for _, cluster := range clusters {
+		controller.clusters[cluster.Cluster()] = cluster
+	}
+	return controller
+}
+
+// HandleWorkerUI proxies the request to the UI of the worker with the workerID path
+// parameter of the Spark standalone cluster with the cluster path parameter.
+func (r StandaloneController) HandleWorkerUI(c *gin.Context) {
+	cluster := c.Param("cluster")
+	workerID := c.Param("workerID")
+	workerPath := strings.TrimPrefix(c.Param("path"), "/")
+
+	standalone, found := r.clusters[cluster]
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("The spark standalone cluster '%s' is not configured", cluster)})
+		return
+	}
+	workerUIURL, found := standalone.WorkerUIURL(workerID)
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("The worker '%s' is not reported by the spark standalone cluster '%s'", workerID, cluster)})
+		return
+	}
+
+	upstreamURL, err := url.Parse(fmt.Sprintf("%s/%s", workerUIURL, workerPath))
+	if err != nil {
+		log.Error("Invalid worker ui URL '%s' for the worker '%s': %v", workerUIURL, workerID, err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": fmt.Sprintf("Invalid worker ui URL for the worker '%s'", workerID)})
+		return
+	}
+
+	c.Request.Header.Add("X-Forwarded-Context", fmt.Sprintf("%s%s/%s/workers/%s", r.basePath, constants.StandaloneBase, cluster, workerID))
+	spark.ServeSparkWorkerUI(c, upstreamURL, workerID, r.basePath)
+}
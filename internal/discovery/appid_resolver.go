@@ -0,0 +1,118 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	kubeclient "github.com/okdp/spark-web-proxy/internal/discovery/resolvers/k8s/client"
+	sparkclient "github.com/okdp/spark-web-proxy/internal/discovery/resolvers/rest"
+	log "github.com/okdp/spark-web-proxy/internal/logging"
+	"github.com/okdp/spark-web-proxy/internal/model"
+	"github.com/okdp/spark-web-proxy/internal/store"
+)
+
+// sparkAppIDBackoff is the retry policy used while the driver UI is starting.
+// The retries span about 4 minutes.
+var sparkAppIDBackoff = wait.Backoff{
+	Duration: 2 * time.Second,
+	Factor:   1.5,
+	Jitter:   0.1,
+	Steps:    10,
+}
+
+// sparkAppIDResolutions holds the pods whose Spark application ID is being resolved.
+var sparkAppIDResolutions sync.Map
+
+// pendingSparkAppID returns the Spark application ID of a driver pod without
+// SPARK_APPLICATION_ID: the ID already resolved from its driver UI if any,
+// otherwise the pod placeholder key.
+func (r SparkAppResolver) pendingSparkAppID(cluster string, pod *corev1.Pod) string {
+	podKey := model.SparkPodAppKey(cluster, pod.Namespace, pod.Name)
+	for _, sparkApp := range r.store.List(store.Filter{Cluster: cluster, Namespace: pod.Namespace, PodName: pod.Name}) {
+		if sparkApp.AppID != podKey {
+			return sparkApp.AppID
+		}
+	}
+	return podKey
+}
+
+// resolveSparkAppIDAsync asynchronously resolves the real Spark application ID
+// of a running driver pod stored under its placeholder key, by querying the
+// driver UI /api/v1/applications endpoint with retries while the UI is starting.
+// Once resolved, the application is re-keyed with its real ID.
+func (r SparkAppResolver) resolveSparkAppIDAsync(cluster string, pod *corev1.Pod, sparkUIBaseURL string) {
+	podKey := model.SparkPodAppKey(cluster, pod.Namespace, pod.Name)
+	if _, inProgress := sparkAppIDResolutions.LoadOrStore(podKey, true); inProgress {
+		return
+	}
+
+	go func() {
+		defer sparkAppIDResolutions.Delete(podKey)
+
+		var appID string
+		err := wait.ExponentialBackoffWithContext(context.Background(), sparkAppIDBackoff, func(context.Context) (bool, error) {
+			// The pod was removed or re-keyed in the meantime
+			if _, found := r.store.Get(podKey); !found {
+				return false, fmt.Errorf("the pod %s is no longer tracked", podKey)
+			}
+
+			sparkClient, err := sparkclient.NewSparkUIRestClient(sparkUIBaseURL, kubeclient.GetTransport(cluster), sparkUIProbeTimeout)
+			if err != nil {
+				return false, err
+			}
+			apps, err := sparkClient.GetApplications()
+			if err != nil || len(*apps) == 0 {
+				log.Debug("The spark ui of the pod %s is not ready yet at %s: %v", podKey, sparkUIBaseURL, err)
+				return false, nil
+			}
+			appID = (*apps)[0].ID
+			return true, nil
+		})
+		if err != nil {
+			log.Warn("Unable to resolve the application ID of the pod %s from the spark ui at %s: %v", podKey, sparkUIBaseURL, err)
+			return
+		}
+
+		pending, found := r.store.Get(podKey)
+		if !found {
+			return
+		}
+		store.Upsert(r.store, appID, func(*model.SparkAppInstance) *model.SparkAppInstance {
+			sparkApp := *pending
+			sparkApp.TransitionTo(model.AppRunning, time.Now())
+			return &sparkApp
+		})
+		// Keep the events recorded on the placeholder in the meantime
+		if pending, found = r.store.Delete(podKey); found {
+			r.store.Update(appID, func(sparkApp *model.SparkAppInstance) bool {
+				recorded := false
+				for _, event := range pending.Events {
+					recorded = sparkApp.RecordEvent(event, max(len(sparkApp.Events), len(pending.Events))) || recorded
+				}
+				return recorded
+			})
+		}
+		log.Info("The application '%s' (%s) was resolved from the spark ui at %s", appID, podKey, sparkUIBaseURL)
+	}()
+}
@@ -0,0 +1,96 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package discovery
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/okdp/spark-web-proxy/internal/config"
+	log "github.com/okdp/spark-web-proxy/internal/logging"
+	"github.com/okdp/spark-web-proxy/internal/model"
+	"github.com/okdp/spark-web-proxy/internal/store"
+)
+
+func Test_ResolveSparkAppFromPod_Without_SparkAppID(t *testing.T) {
+	// Given: a driver UI which is starting
+	log.SetupGlobalLogger(config.Logging{Level: "info"})
+	withFastSparkAppIDBackoff(t)
+
+	var requests atomic.Int32
+	sparkUI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if requests.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"id":"spark-client-123","name":"notebook","attempts":[{"completed":false}]}]`))
+	}))
+	defer sparkUI.Close()
+	sparkUIURL, _ := url.Parse(sparkUI.URL)
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "jupyter-kernel",
+			Namespace:   "spark",
+			Annotations: map[string]string{"spark-web-proxy.okdp.io/ui-port": sparkUIURL.Port()},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning, PodIP: sparkUIURL.Hostname()},
+	}
+	podKey := model.SparkPodAppKey("default", "spark", "jupyter-kernel")
+	sparkApps := store.NewMemoryStore()
+	resolver := NewSparkAppResolver(sparkApps)
+
+	// When
+	sparkApp, err := resolver.ResolveSparkAppFromPod("default", pod)
+
+	// Then: the application is stored under the pod placeholder key
+	assert.NoError(t, err)
+	assert.Equal(t, podKey, sparkApp.AppID, "AppID")
+
+	// Then: the application is re-keyed once the driver UI is ready
+	assert.Eventually(t, func() bool {
+		_, found := sparkApps.Get("spark-client-123")
+		return found
+	}, 5*time.Second, 10*time.Millisecond, "resolved application ID")
+	_, found := sparkApps.Get(podKey)
+	assert.False(t, found, "placeholder key should be removed")
+
+	// When: the pod is updated again
+	sparkApp, _ = resolver.ResolveSparkAppFromPod("default", pod)
+
+	// Then: the resolved application ID is kept
+	assert.Equal(t, "spark-client-123", sparkApp.AppID, "AppID")
+	_, found = sparkApps.Get(podKey)
+	assert.False(t, found, "placeholder key should not be recreated")
+}
+
+// withFastSparkAppIDBackoff shortens the driver UI retry policy for the duration of the test.
+func withFastSparkAppIDBackoff(t *testing.T) {
+	backoff := sparkAppIDBackoff
+	t.Cleanup(func() { sparkAppIDBackoff = backoff })
+	sparkAppIDBackoff.Duration = 10 * time.Millisecond
+	sparkAppIDBackoff.Factor = 1
+}
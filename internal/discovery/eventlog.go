@@ -0,0 +1,212 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package discovery
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/okdp/spark-web-proxy/internal/constants"
+	"github.com/okdp/spark-web-proxy/internal/model"
+)
+
+const (
+	// eventLogInProgressSuffix is the suffix of the event logs of the running applications.
+	eventLogInProgressSuffix = ".inprogress"
+	// rollingEventLogDirPrefix is the prefix of the rolling event log directories
+	// (spark.eventLog.rolling.enabled).
+	rollingEventLogDirPrefix = "eventlog_v2_"
+	// rollingEventLogStatusPrefix is the prefix of the application status file of a rolling event log.
+	rollingEventLogStatusPrefix = "appstatus_"
+	// rollingEventLogFilePrefix is the prefix of the event files of a rolling event log.
+	rollingEventLogFilePrefix = "events_"
+	// eventLogMaxHeaderEvents is the maximum number of events read to find the
+	// application start and environment events.
+	eventLogMaxHeaderEvents = 1000
+	// eventLogMaxEventSize is the maximum size of an event (the environment event
+	// holds the whole class path).
+	eventLogMaxEventSize = 64 * 1024 * 1024
+)
+
+// errEventLogIncomplete is returned when the application start and environment
+// events are not written yet to the event log.
+var errEventLogIncomplete = errors.New("the application start and environment events are not written yet")
+
+// eventLogCompressionCodecs are the file extensions of the compressed event logs
+// (spark.eventLog.compress), which are not supported.
+var eventLogCompressionCodecs = []string{".lz4", ".lzf", ".snappy", ".zstd"}
+
+// sparkListenerEvent holds the fields of the SparkListenerApplicationStart and
+// SparkListenerEnvironmentUpdate events used to resolve the Spark UI.
+type sparkListenerEvent struct {
+	Event           string            `json:"Event"`
+	AppID           string            `json:"App ID"`
+	AppName         string            `json:"App Name"`
+	Timestamp       int64             `json:"Timestamp"`
+	User            string            `json:"User"`
+	SparkProperties map[string]string `json:"Spark Properties"`
+}
+
+// isRollingEventLog reports whether the path is a rolling event log directory.
+func isRollingEventLog(path string) bool {
+	return strings.HasPrefix(filepath.Base(path), rollingEventLogDirPrefix)
+}
+
+// eventLogKey returns the event log a path of the event log directory belongs to:
+// the rolling event log directory, or the single event log file without the
+// in-progress suffix, so that an event log keeps the same key once completed.
+func eventLogKey(directory string, path string) (string, bool) {
+	relative, err := filepath.Rel(directory, path)
+	if err != nil || relative == "." || strings.HasPrefix(relative, "..") {
+		return "", false
+	}
+
+	name := strings.Split(relative, string(filepath.Separator))[0]
+	if strings.HasPrefix(name, ".") {
+		return "", false
+	}
+	return filepath.Join(directory, strings.TrimSuffix(name, eventLogInProgressSuffix)), true
+}
+
+// inProgressEventFile returns the file holding the first events of the event
+// log with the given key, if the application is still in progress.
+func inProgressEventFile(key string) (string, bool) {
+	if !isRollingEventLog(key) {
+		path := key + eventLogInProgressSuffix
+		info, err := os.Stat(path)
+		return path, err == nil && info.Mode().IsRegular()
+	}
+
+	entries, err := os.ReadDir(key)
+	if err != nil {
+		return "", false
+	}
+
+	inProgress := false
+	firstEvents, firstIndex := "", -1
+	for _, entry := range entries {
+		name := entry.Name()
+		switch {
+		case strings.HasPrefix(name, rollingEventLogStatusPrefix):
+			inProgress = inProgress || strings.HasSuffix(name, eventLogInProgressSuffix)
+		case strings.HasPrefix(name, rollingEventLogFilePrefix):
+			// events_<index>_<appId>[.<codec>]
+			index, err := strconv.Atoi(strings.SplitN(strings.TrimPrefix(name, rollingEventLogFilePrefix), "_", 2)[0])
+			if err == nil && (firstIndex < 0 || index < firstIndex) {
+				firstEvents, firstIndex = filepath.Join(key, name), index
+			}
+		}
+	}
+	return firstEvents, inProgress && firstEvents != ""
+}
+
+// isCompressedEventLog reports whether the event file is compressed.
+func isCompressedEventLog(path string) bool {
+	name := strings.TrimSuffix(filepath.Base(path), eventLogInProgressSuffix)
+	for _, codec := range eventLogCompressionCodecs {
+		if strings.HasSuffix(name, codec) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseEventLog reads the SparkListenerApplicationStart and SparkListenerEnvironmentUpdate
+// events of an event file, and returns the Spark application with its Spark UI
+// resolved from the driver host and Spark UI port properties.
+func parseEventLog(path string) (*model.SparkAppInstance, error) {
+	if isCompressedEventLog(path) {
+		return nil, fmt.Errorf("the compressed event logs are not supported")
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+
+	var appStart, environment *sparkListenerEvent
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), eventLogMaxEventSize)
+	for events := 0; scanner.Scan() && events < eventLogMaxHeaderEvents && (appStart == nil || environment == nil); events++ {
+		// The event name comes first, skip the other events without decoding them
+		line := scanner.Bytes()
+		head := string(line[:min(len(line), 64)])
+		if !strings.Contains(head, "SparkListenerApplicationStart") && !strings.Contains(head, "SparkListenerEnvironmentUpdate") {
+			continue
+		}
+
+		event := &sparkListenerEvent{}
+		if err := json.Unmarshal(line, event); err != nil {
+			// The last event may be partially written
+			continue
+		}
+		switch event.Event {
+		case "SparkListenerApplicationStart":
+			appStart = event
+		case "SparkListenerEnvironmentUpdate":
+			environment = event
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if appStart == nil || environment == nil {
+		return nil, errEventLogIncomplete
+	}
+
+	properties := environment.SparkProperties
+	if properties["spark.ui.enabled"] == "false" {
+		return nil, fmt.Errorf("the spark ui of the application '%s' is disabled", appStart.AppID)
+	}
+	driverHost := properties["spark.driver.host"]
+	if driverHost == "" {
+		return nil, fmt.Errorf("the spark.driver.host property of the application '%s' is not set", appStart.AppID)
+	}
+	uiPort := properties["spark.ui.port"]
+	if uiPort == "" {
+		uiPort = strconv.Itoa(constants.DefaultSparkUIPort)
+	}
+	appID := appStart.AppID
+	if appID == "" {
+		appID = properties["spark.app.id"]
+	}
+	if appID == "" {
+		return nil, fmt.Errorf("the application ID of the event log %s is not set", path)
+	}
+
+	return &model.SparkAppInstance{
+		BaseURL:        fmt.Sprintf("http://%s:%s", driverHost, uiPort),
+		PodName:        properties["spark.kubernetes.driver.pod.name"],
+		AppID:          appID,
+		AppName:        appStart.AppName,
+		Namespace:      properties["spark.kubernetes.namespace"],
+		StartTimeEpoch: appStart.Timestamp,
+		User:           appStart.User,
+		Source:         model.SourceEventLog,
+		UI: sparkUISettings(func(key string) (string, bool) {
+			value, found := properties[key]
+			return value, found
+		}),
+	}, nil
+}
@@ -0,0 +1,203 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package discovery
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/okdp/spark-web-proxy/internal/config"
+	log "github.com/okdp/spark-web-proxy/internal/logging"
+	"github.com/okdp/spark-web-proxy/internal/model"
+	"github.com/okdp/spark-web-proxy/internal/store"
+)
+
+// EventLogWatcher discovers the running applications from the in-progress event
+// logs (*.inprogress files and eventlog_v2_* rolling event log directories) of a
+// locally mounted event log directory, as soon as the application start and
+// environment events are written, without waiting for Spark History to list them.
+//
+// The directory is watched for file system notifications and scanned every scan
+// interval, as the notifications are not delivered by all the file systems
+// (e.g. NFS). The discovered applications are removed from the store once their
+// event log is completed or removed.
+type EventLogWatcher struct {
+	store        store.Store
+	directory    string
+	scanInterval time.Duration
+
+	mu sync.Mutex
+	// known holds the application IDs of the parsed in-progress event logs by
+	// event log key, empty for the event logs without a Spark UI to register.
+	known map[string]string
+}
+
+// NewEventLogWatcher creates an EventLogWatcher registering the applications in
+// the given store according to the event log discovery configuration.
+func NewEventLogWatcher(conf config.EventLogDiscovery, sparkApps store.Store) *EventLogWatcher {
+	scanInterval := conf.ScanInterval
+	if scanInterval <= 0 {
+		scanInterval = time.Minute
+	}
+	return &EventLogWatcher{
+		store:        sparkApps,
+		directory:    filepath.Clean(conf.Directory),
+		scanInterval: scanInterval,
+		known:        make(map[string]string),
+	}
+}
+
+// Run watches and scans the event log directory until the context is done.
+func (w *EventLogWatcher) Run(ctx context.Context) {
+	log.Info("Watching the spark event logs of the directory %s (scan interval: %s)", w.directory, w.scanInterval)
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		log.Warn("Unable to watch the event log directory %s, falling back on the scans: %v", w.directory, err)
+		watcher = nil
+	} else {
+		defer func() { _ = watcher.Close() }()
+		if err := watcher.Add(w.directory); err != nil {
+			log.Warn("Unable to watch the event log directory %s, falling back on the scans: %v", w.directory, err)
+		}
+	}
+
+	ticker := time.NewTicker(w.scanInterval)
+	defer ticker.Stop()
+
+	w.scan(watcher)
+	for {
+		var (
+			events <-chan fsnotify.Event
+			errs   <-chan error
+		)
+		if watcher != nil {
+			events, errs = watcher.Events, watcher.Errors
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.scan(watcher)
+		case event := <-events:
+			if event.Has(fsnotify.Create) && isRollingEventLog(event.Name) {
+				if err := watcher.Add(event.Name); err != nil {
+					log.Warn("Unable to watch the rolling event log %s: %v", event.Name, err)
+				}
+			}
+			if key, found := eventLogKey(w.directory, event.Name); found {
+				w.sync(key)
+			}
+		case err := <-errs:
+			log.Warn("Error while watching the event log directory %s: %v", w.directory, err)
+		}
+	}
+}
+
+// Scan scans the event log directory, registers the applications of the new
+// in-progress event logs and removes the applications of the completed event logs.
+func (w *EventLogWatcher) Scan() {
+	w.scan(nil)
+}
+
+// scan scans the event log directory, and watches the rolling event log
+// directories with the given watcher, if any.
+func (w *EventLogWatcher) scan(watcher *fsnotify.Watcher) {
+	entries, err := os.ReadDir(w.directory)
+	if err != nil {
+		log.Warn("Unable to scan the event log directory %s: %v", w.directory, err)
+		return
+	}
+
+	keys := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		key, found := eventLogKey(w.directory, filepath.Join(w.directory, entry.Name()))
+		if !found {
+			continue
+		}
+		if _, inProgress := inProgressEventFile(key); !inProgress {
+			continue
+		}
+		if watcher != nil && entry.IsDir() && isRollingEventLog(key) {
+			_ = watcher.Add(key)
+		}
+		keys[key] = true
+		w.sync(key)
+	}
+
+	w.mu.Lock()
+	stale := make([]string, 0)
+	for key := range w.known {
+		if !keys[key] {
+			stale = append(stale, key)
+		}
+	}
+	w.mu.Unlock()
+
+	for _, key := range stale {
+		w.sync(key)
+	}
+}
+
+// sync registers the application of an in-progress event log, or removes the
+// application of a completed or removed event log.
+func (w *EventLogWatcher) sync(key string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	eventFile, inProgress := inProgressEventFile(key)
+	appID, parsed := w.known[key]
+
+	if !inProgress {
+		if !parsed {
+			return
+		}
+		delete(w.known, key)
+		if sparkApp, found := w.store.Get(appID); found && sparkApp.Source == model.SourceEventLog {
+			w.store.Delete(appID)
+			log.Info("The event log of the application '%s' is completed: %s", appID, key)
+		}
+		return
+	}
+	if parsed {
+		return
+	}
+
+	sparkApp, err := parseEventLog(eventFile)
+	if errors.Is(err, errEventLogIncomplete) {
+		return
+	}
+	if err != nil {
+		log.Debug("Ignoring the event log %s: %v", eventFile, err)
+		w.known[key] = ""
+		return
+	}
+	w.known[key] = sparkApp.AppID
+
+	sparkApp.TransitionTo(model.AppRunning, time.Now())
+	if !store.Add(w.store, sparkApp) {
+		return
+	}
+	log.Info("The application '%s' with an in-progress event log %s is served at %s", sparkApp.AppID, key, sparkApp.BaseURL)
+}
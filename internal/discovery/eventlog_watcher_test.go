@@ -0,0 +1,148 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/okdp/spark-web-proxy/internal/config"
+	log "github.com/okdp/spark-web-proxy/internal/logging"
+	"github.com/okdp/spark-web-proxy/internal/model"
+	"github.com/okdp/spark-web-proxy/internal/store"
+)
+
+// eventLog returns the first events of the event log of an application.
+func eventLog(appID string, driverHost string) string {
+	return `{"Event":"SparkListenerLogStart","Spark Version":"3.5.5"}
+{"Event":"SparkListenerEnvironmentUpdate","JVM Information":{"Java Version":"17"},"Spark Properties":{` +
+		fmt.Sprintf(`"spark.app.id":"%s","spark.driver.host":"%s","spark.ui.port":"4041","spark.kubernetes.namespace":"jupyter"`, appID, driverHost) +
+		`},"Hadoop Properties":{},"System Properties":{},"Classpath Entries":{}}
+{"Event":"SparkListenerApplicationStart","App Name":"notebook","App ID":"` + appID + `","Timestamp":1000,"User":"alice"}
+{"Event":"SparkListenerJobStart","Job ID":0`
+}
+
+func writeFile(t *testing.T, path string, content string) {
+	t.Helper()
+	assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+}
+
+func Test_EventLogWatcher_Scan(t *testing.T) {
+	// Given: an in-progress event log, an in-progress rolling event log, a completed and a compressed event log
+	log.SetupGlobalLogger(config.Logging{Level: "info"})
+	directory := t.TempDir()
+	writeFile(t, filepath.Join(directory, "spark-1.inprogress"), eventLog("spark-1", "10.0.0.1"))
+	writeFile(t, filepath.Join(directory, "eventlog_v2_spark-2", "appstatus_spark-2.inprogress"), "")
+	writeFile(t, filepath.Join(directory, "eventlog_v2_spark-2", "events_1_spark-2"), eventLog("spark-2", "10.0.0.2"))
+	writeFile(t, filepath.Join(directory, "spark-3"), eventLog("spark-3", "10.0.0.3"))
+	writeFile(t, filepath.Join(directory, "spark-4.zstd.inprogress"), "compressed")
+
+	sparkApps := store.NewMemoryStore()
+	watcher := NewEventLogWatcher(config.EventLogDiscovery{Directory: directory}, sparkApps)
+
+	// When
+	watcher.Scan()
+
+	// Then: the in-progress applications are registered
+	sparkApp, found := sparkApps.Get("spark-1")
+	if assert.True(t, found, "spark-1 registered") {
+		assert.Equal(t, "http://10.0.0.1:4041", sparkApp.BaseURL, "BaseURL")
+		assert.Equal(t, "notebook", sparkApp.AppName, "AppName")
+		assert.Equal(t, "alice", sparkApp.User, "User")
+		assert.Equal(t, "jupyter", sparkApp.Namespace, "Namespace")
+		assert.Equal(t, int64(1000), sparkApp.StartTimeEpoch, "StartTimeEpoch")
+		assert.Equal(t, model.SourceEventLog, sparkApp.Source, "Source")
+		assert.Equal(t, string(model.AppRunning), sparkApp.Status, "Status")
+	}
+	sparkApp, found = sparkApps.Get("spark-2")
+	if assert.True(t, found, "spark-2 registered") {
+		assert.Equal(t, "http://10.0.0.2:4041", sparkApp.BaseURL, "BaseURL")
+	}
+	assert.Len(t, sparkApps.List(store.Filter{}), 2, "registered applications")
+
+	// When: the applications complete
+	assert.NoError(t, os.Rename(filepath.Join(directory, "spark-1.inprogress"), filepath.Join(directory, "spark-1")))
+	assert.NoError(t, os.Rename(filepath.Join(directory, "eventlog_v2_spark-2", "appstatus_spark-2.inprogress"),
+		filepath.Join(directory, "eventlog_v2_spark-2", "appstatus_spark-2")))
+	watcher.Scan()
+
+	// Then
+	assert.Empty(t, sparkApps.List(store.Filter{}), "registered applications")
+}
+
+func Test_ParseEventLog_AppID(t *testing.T) {
+	// Given: an event log without the App ID of the application start event
+	directory := t.TempDir()
+	path := filepath.Join(directory, "spark-1.inprogress")
+	writeFile(t, path, strings.Replace(eventLog("spark-1", "10.0.0.1"), `"App ID":"spark-1",`, "", 1))
+
+	// When
+	sparkApp, err := parseEventLog(path)
+
+	// Then: the application ID falls back on spark.app.id
+	assert.NoError(t, err)
+	assert.Equal(t, "spark-1", sparkApp.AppID, "AppID")
+
+	// Given: an event log without any application ID
+	path = filepath.Join(directory, "spark-2.inprogress")
+	writeFile(t, path, eventLog("", "10.0.0.2"))
+
+	// When
+	_, err = parseEventLog(path)
+
+	// Then
+	assert.Error(t, err)
+}
+
+func Test_EventLogWatcher_Run(t *testing.T) {
+	// Given: a watched event log directory
+	log.SetupGlobalLogger(config.Logging{Level: "info"})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	directory := t.TempDir()
+	sparkApps := store.NewMemoryStore()
+	watcher := NewEventLogWatcher(config.EventLogDiscovery{Directory: directory, ScanInterval: time.Hour}, sparkApps)
+	go watcher.Run(ctx)
+
+	// When: an application starts writing its event log
+	path := filepath.Join(directory, "spark-1.inprogress")
+	writeFile(t, path, `{"Event":"SparkListenerLogStart","Spark Version":"4.0.0"}`+"\n")
+	writeFile(t, path, eventLog("spark-1", "10.0.0.1"))
+
+	// Then: the application is registered without waiting for the scan
+	assert.Eventually(t, func() bool {
+		_, found := sparkApps.Get("spark-1")
+		return found
+	}, 5*time.Second, 10*time.Millisecond, "spark-1 registered")
+
+	// When: the event log is completed
+	assert.NoError(t, os.Rename(path, filepath.Join(directory, "spark-1")))
+
+	// Then
+	assert.Eventually(t, func() bool {
+		_, found := sparkApps.Get("spark-1")
+		return !found
+	}, 5*time.Second, 10*time.Millisecond, "spark-1 removed")
+}
@@ -0,0 +1,134 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package discovery
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/okdp/spark-web-proxy/internal/config"
+	"github.com/okdp/spark-web-proxy/internal/model"
+	"github.com/okdp/spark-web-proxy/internal/store"
+)
+
+// eventClockSkew is the tolerated delay between a pod event and the first
+// observation of its application by the proxy.
+const eventClockSkew = time.Minute
+
+// PodEventRecorder records the Kubernetes events of the driver and executor pods
+// on their applications, so that the reason of a driver stuck Pending (e.g.
+// FailedScheduling, ErrImagePull) is shown before its Spark UI is available.
+type PodEventRecorder struct {
+	store        store.Store
+	executorPods *store.ExecutorPodStore
+	maxEvents    int
+}
+
+// NewPodEventRecorder creates a PodEventRecorder recording the events on the
+// applications of the given store. The executor pods are correlated using the
+// given executor pod store.
+func NewPodEventRecorder(conf config.EventDiscovery, sparkApps store.Store, executorPods *store.ExecutorPodStore) *PodEventRecorder {
+	maxEvents := conf.MaxPerApp
+	if maxEvents <= 0 {
+		maxEvents = 20
+	}
+	return &PodEventRecorder{
+		store:        sparkApps,
+		executorPods: executorPods,
+		maxEvents:    maxEvents,
+	}
+}
+
+// Record records a pod event on the applications of the pod and returns the
+// updated applications. The events of the untracked pods are ignored, as well
+// as the events older than the application, which belong to a previous pod
+// with the same name.
+func (r PodEventRecorder) Record(cluster string, event *corev1.Event) []*model.SparkAppInstance {
+	if event.InvolvedObject.Kind != "Pod" {
+		return nil
+	}
+	podEvent := podEventFromEvent(event)
+
+	sparkApps := r.store.List(store.Filter{Cluster: cluster, Namespace: event.InvolvedObject.Namespace, PodName: event.InvolvedObject.Name})
+	if executorPod, found := r.executorPods.GetPod(cluster, event.InvolvedObject.Namespace, event.InvolvedObject.Name); found {
+		podEvent.ExecutorID = executorPod.ExecutorID
+		if sparkApp, found := r.store.Get(executorPod.AppID); found {
+			sparkApps = append(sparkApps, sparkApp)
+		}
+	}
+
+	updated := make([]*model.SparkAppInstance, 0, len(sparkApps))
+	for _, sparkApp := range sparkApps {
+		// The application may be changed concurrently by the informers
+		sparkApp, recorded := r.store.Update(sparkApp.AppID, func(app *model.SparkAppInstance) bool {
+			if firstSeenAt, found := firstSeenAt(app); found && time.UnixMilli(podEvent.LastSeenEpoch).Before(firstSeenAt.Add(-eventClockSkew)) {
+				return false
+			}
+			return app.RecordEvent(podEvent, r.maxEvents)
+		})
+		if recorded {
+			updated = append(updated, sparkApp)
+		}
+	}
+	return updated
+}
+
+// firstSeenAt returns the time of the first transition of an application.
+func firstSeenAt(sparkApp *model.SparkAppInstance) (time.Time, bool) {
+	if len(sparkApp.Transitions) == 0 {
+		return time.Time{}, false
+	}
+	return time.UnixMilli(sparkApp.Transitions[0].TimeEpoch), true
+}
+
+// podEventFromEvent returns the pod event of a Kubernetes event, using the
+// event series and event time of the events.k8s.io/v1 events when the
+// deprecated timestamps and count are not set.
+func podEventFromEvent(event *corev1.Event) model.PodEvent {
+	podEvent := model.PodEvent{
+		PodName: event.InvolvedObject.Name,
+		Type:    event.Type,
+		Reason:  event.Reason,
+		Message: event.Message,
+		Count:   event.Count,
+	}
+
+	firstSeen := event.FirstTimestamp.Time
+	lastSeen := event.LastTimestamp.Time
+	if firstSeen.IsZero() {
+		firstSeen = event.EventTime.Time
+	}
+	if event.Series != nil {
+		podEvent.Count = event.Series.Count
+		if lastSeen.IsZero() {
+			lastSeen = event.Series.LastObservedTime.Time
+		}
+	}
+	if firstSeen.IsZero() {
+		firstSeen = event.CreationTimestamp.Time
+	}
+	if lastSeen.IsZero() {
+		lastSeen = firstSeen
+	}
+	if podEvent.Count <= 0 {
+		podEvent.Count = 1
+	}
+	podEvent.FirstSeenEpoch = firstSeen.UnixMilli()
+	podEvent.LastSeenEpoch = lastSeen.UnixMilli()
+	return podEvent
+}
@@ -0,0 +1,120 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package discovery
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/okdp/spark-web-proxy/internal/config"
+	log "github.com/okdp/spark-web-proxy/internal/logging"
+	"github.com/okdp/spark-web-proxy/internal/model"
+	"github.com/okdp/spark-web-proxy/internal/store"
+)
+
+func podEvent(podName string, reason string, lastSeen time.Time) *corev1.Event {
+	return &corev1.Event{
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "spark", Name: podName},
+		Type:           corev1.EventTypeWarning,
+		Reason:         reason,
+		Message:        reason + " of " + podName,
+		Count:          2,
+		FirstTimestamp: metav1.NewTime(lastSeen.Add(-time.Second)),
+		LastTimestamp:  metav1.NewTime(lastSeen),
+	}
+}
+
+func Test_PodEventRecorder_Record(t *testing.T) {
+	// Given: an application with a driver pod and an executor pod
+	log.SetupGlobalLogger(config.Logging{Level: "info"})
+	now := time.Now()
+	sparkApps := store.NewMemoryStore()
+	sparkApp := &model.SparkAppInstance{AppID: "spark-1", Cluster: "default", Namespace: "spark", PodName: "spark-1-driver"}
+	sparkApp.TransitionTo(model.AppPending, now)
+	sparkApps.Put(sparkApp)
+	executorPods := store.NewExecutorPodStore(10)
+	executorPods.Put(&model.ExecutorPod{AppID: "spark-1", ExecutorID: "1", Cluster: "default", Namespace: "spark", PodName: "spark-1-exec-1"})
+	recorder := NewPodEventRecorder(config.EventDiscovery{MaxPerApp: 10}, sparkApps, executorPods)
+
+	// When
+	updated := recorder.Record("default", podEvent("spark-1-driver", "FailedScheduling", now))
+	recorder.Record("default", podEvent("spark-1-exec-1", "BackOff", now.Add(time.Second)))
+	recorder.Record("default", podEvent("other-driver", "Failed", now))
+	recorder.Record("default", podEvent("spark-1-driver", "Killing", now.Add(-time.Hour)))
+
+	// Then: the events of the driver and executor pods are recorded, not the events of the previous pod
+	assert.Len(t, updated, 1, "updated applications")
+	stored, _ := sparkApps.Get("spark-1")
+	if assert.Len(t, stored.Events, 2, "Events") {
+		assert.Equal(t, "FailedScheduling", stored.Events[0].Reason, "driver event")
+		assert.Equal(t, "spark-1-driver", stored.Events[0].PodName, "driver event pod")
+		assert.Equal(t, int32(2), stored.Events[0].Count, "driver event count")
+		assert.Equal(t, now.Add(-time.Second).UnixMilli(), stored.Events[0].FirstSeenEpoch, "driver event first seen")
+		assert.Equal(t, "BackOff", stored.Events[1].Reason, "executor event")
+		assert.Equal(t, "1", stored.Events[1].ExecutorID, "executor event executor ID")
+	}
+
+	// When: the driver is resolved again
+	resolved := &model.SparkAppInstance{AppID: "spark-1", Cluster: "default", Namespace: "spark", PodName: "spark-1-driver"}
+	resolved.InheritState(stored)
+
+	// Then: the events are kept
+	assert.Len(t, resolved.Events, 2, "inherited events")
+}
+
+func Test_PodEventRecorder_Record_Concurrent_Resync(t *testing.T) {
+	// Given: a pending driver pod
+	log.SetupGlobalLogger(config.Logging{Level: "info"})
+	now := time.Now()
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "spark-1-driver", Namespace: "spark"},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Env: []corev1.EnvVar{{Name: "SPARK_APPLICATION_ID", Value: "spark-1"}}}}},
+		Status:     corev1.PodStatus{Phase: corev1.PodPending},
+	}
+	sparkApps := store.NewMemoryStore()
+	resolver := NewSparkAppResolver(sparkApps)
+	_, err := resolver.ResolveSparkAppFromPod("default", pod)
+	assert.NoError(t, err)
+	recorder := NewPodEventRecorder(config.EventDiscovery{MaxPerApp: 100}, sparkApps, store.NewExecutorPodStore(10))
+
+	// When: the events are recorded while the pod informer resyncs
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 200; i++ {
+			_, _ = resolver.ResolveSparkAppFromPod("default", pod)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			recorder.Record("default", podEvent("spark-1-driver", fmt.Sprintf("Reason%d", i), now))
+		}
+	}()
+	wg.Wait()
+
+	// Then: no event is lost
+	stored, _ := sparkApps.Get("spark-1")
+	assert.Len(t, stored.Events, 50, "Events")
+}
@@ -0,0 +1,164 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package discovery
+
+import (
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/okdp/spark-web-proxy/internal/constants"
+	sparkclient "github.com/okdp/spark-web-proxy/internal/discovery/resolvers/rest"
+	"github.com/okdp/spark-web-proxy/internal/model"
+	"github.com/okdp/spark-web-proxy/internal/store"
+)
+
+const (
+	// sparkExecutorContainer is the name of the executor container set by the driver.
+	sparkExecutorContainer = "spark-kubernetes-executor"
+	// executorsTimeout is the timeout of the executors request to the driver UI.
+	executorsTimeout = 10 * time.Second
+)
+
+// ExecutorPodFromPod returns the executor pod of a Spark executor pod, and false
+// if the pod misses the spark-app-selector or spark-exec-id labels.
+func ExecutorPodFromPod(cluster string, pod *corev1.Pod) (*model.ExecutorPod, bool) {
+	appID := pod.Labels[constants.SparkAppSelectorLabel]
+	executorID := pod.Labels[constants.SparkExecIDLabel]
+	if appID == "" || executorID == "" {
+		return nil, false
+	}
+
+	executorPod := &model.ExecutorPod{
+		AppID:          appID,
+		ExecutorID:     executorID,
+		Cluster:        cluster,
+		Namespace:      pod.Namespace,
+		PodName:        pod.Name,
+		Phase:          string(pod.Status.Phase),
+		NodeName:       pod.Spec.NodeName,
+		PodIP:          pod.Status.PodIP,
+		Reason:         pod.Status.Reason,
+		Message:        pod.Status.Message,
+		CreatedAtEpoch: pod.CreationTimestamp.UnixMilli(),
+	}
+	if pod.DeletionTimestamp != nil {
+		executorPod.DeletedAtEpoch = pod.DeletionTimestamp.UnixMilli()
+	}
+
+	// The unschedulable pods only report the reason of their PodScheduled condition
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodScheduled && condition.Status == corev1.ConditionFalse && executorPod.Reason == "" {
+			executorPod.Reason = condition.Reason
+			executorPod.Message = condition.Message
+		}
+	}
+
+	if status := sparkContainerStatus(pod, sparkExecutorContainer); status != nil {
+		executorPod.State, executorPod.Ready = containerState(status)
+		executorPod.Restarts = status.RestartCount
+
+		terminated := status.State.Terminated
+		if terminated == nil {
+			terminated = status.LastTerminationState.Terminated
+		}
+		if terminated != nil {
+			executorPod.Termination = &model.Termination{
+				Reason:          terminated.Reason,
+				Message:         terminated.Message,
+				ExitCode:        terminated.ExitCode,
+				FinishedAtEpoch: terminated.FinishedAt.UnixMilli(),
+				DeletedAtEpoch:  executorPod.DeletedAtEpoch,
+			}
+		}
+	}
+
+	if container := executorContainer(pod); container != nil {
+		executorPod.Requests = resourceQuantities(container.Resources.Requests)
+		executorPod.Limits = resourceQuantities(container.Resources.Limits)
+	}
+	return executorPod, true
+}
+
+// ResolveExecutors returns the executors of an application, joining the executors
+// reported by its driver UI with the tracked executor pods. When the driver UI
+// is not queried (completed application) or does not answer, the executor pods
+// are returned alone, with the error of the driver UI if any.
+func ResolveExecutors(sparkApp *model.SparkAppInstance, executorPods *store.ExecutorPodStore) ([]model.Executor, error) {
+	executors := make(map[string]*model.Executor)
+	for _, pod := range executorPods.List(sparkApp.AppID) {
+		executors[pod.ExecutorID] = &model.Executor{ID: pod.ExecutorID, Pod: pod}
+	}
+
+	var err error
+	if sparkApp.BaseURL != "" && !sparkApp.IsCompleted() {
+		var sparkExecutors *[]model.SparkExecutor
+		sparkExecutors, err = getSparkExecutors(sparkApp)
+		if err == nil {
+			for i := range *sparkExecutors {
+				sparkExecutor := &(*sparkExecutors)[i]
+				executor, found := executors[sparkExecutor.ID]
+				if !found {
+					executor = &model.Executor{ID: sparkExecutor.ID}
+					executors[sparkExecutor.ID] = executor
+				}
+				executor.Spark = sparkExecutor
+			}
+		}
+	}
+
+	joined := make([]model.Executor, 0, len(executors))
+	for _, executor := range executors {
+		joined = append(joined, *executor)
+	}
+	sort.Slice(joined, func(i, j int) bool { return store.LessExecutorID(joined[i].ID, joined[j].ID) })
+	return joined, err
+}
+
+// getSparkExecutors queries the executors of an application from its driver UI.
+func getSparkExecutors(sparkApp *model.SparkAppInstance) (*[]model.SparkExecutor, error) {
+	sparkClient, err := sparkclient.NewSparkUIRestClient(sparkApp.BaseURL, SparkUITransport(sparkApp), executorsTimeout)
+	if err != nil {
+		return nil, err
+	}
+	return sparkClient.GetExecutors(sparkApp.AppID)
+}
+
+// executorContainer returns the executor container (the first container if
+// there is no spark-kubernetes-executor container), if any.
+func executorContainer(pod *corev1.Pod) *corev1.Container {
+	var container *corev1.Container
+	for i := range pod.Spec.Containers {
+		if i == 0 || pod.Spec.Containers[i].Name == sparkExecutorContainer {
+			container = &pod.Spec.Containers[i]
+		}
+	}
+	return container
+}
+
+// resourceQuantities returns the quantities of a resource list by resource name.
+func resourceQuantities(resources corev1.ResourceList) map[string]string {
+	if len(resources) == 0 {
+		return nil
+	}
+	quantities := make(map[string]string, len(resources))
+	for name, quantity := range resources {
+		quantities[string(name)] = quantity.String()
+	}
+	return quantities
+}
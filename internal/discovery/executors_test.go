@@ -0,0 +1,79 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package discovery
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/okdp/spark-web-proxy/internal/config"
+	log "github.com/okdp/spark-web-proxy/internal/logging"
+	"github.com/okdp/spark-web-proxy/internal/model"
+	"github.com/okdp/spark-web-proxy/internal/store"
+)
+
+func Test_ResolveExecutors(t *testing.T) {
+	// Given: a driver reporting an active and a removed executor, the pod of the
+	// removed executor, and a pending executor pod not registered with the driver yet
+	log.SetupGlobalLogger(config.Logging{Level: "info"})
+	sparkUI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/applications/spark-1/allexecutors", r.URL.Path, "executors path")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[
+			{"id":"driver","hostPort":"10.0.0.1:7079","isActive":true,"totalCores":0},
+			{"id":"2","hostPort":"10.0.0.3:7079","isActive":true,"totalCores":2,"activeTasks":1},
+			{"id":"1","hostPort":"10.0.0.2:7079","isActive":false,"removeReason":"The executor with id 1 exited with exit code 137(SIGKILL, possible container OOM)."}
+		]`))
+	}))
+	defer sparkUI.Close()
+
+	executorPods := store.NewExecutorPodStore(10)
+	executorPods.Put(&model.ExecutorPod{AppID: "spark-1", ExecutorID: "1", PodName: "spark-1-exec-1", Phase: "Failed",
+		Termination: &model.Termination{Reason: "OOMKilled", ExitCode: 137}, DeletedAtEpoch: 1000})
+	executorPods.Put(&model.ExecutorPod{AppID: "spark-1", ExecutorID: "3", PodName: "spark-1-exec-3", Phase: "Pending", Reason: "Unschedulable"})
+	sparkApp := &model.SparkAppInstance{AppID: "spark-1", BaseURL: sparkUI.URL, Status: string(model.AppRunning)}
+
+	// When
+	executors, err := ResolveExecutors(sparkApp, executorPods)
+
+	// Then
+	assert.NoError(t, err)
+	if assert.Len(t, executors, 4, "executors") {
+		assert.Equal(t, "driver", executors[0].ID, "driver first")
+		assert.Nil(t, executors[0].Pod, "driver pod")
+		assert.Equal(t, "1", executors[1].ID, "removed executor")
+		assert.False(t, executors[1].Spark.IsActive, "removed executor IsActive")
+		assert.Equal(t, "OOMKilled", executors[1].Pod.Termination.Reason, "removed executor termination")
+		assert.Equal(t, "2", executors[2].ID, "active executor")
+		assert.Nil(t, executors[2].Pod, "untracked executor pod")
+		assert.Equal(t, 2, executors[2].Spark.TotalCores, "active executor TotalCores")
+		assert.Equal(t, "3", executors[3].ID, "pending executor")
+		assert.Nil(t, executors[3].Spark, "pending executor not registered")
+		assert.Equal(t, "Unschedulable", executors[3].Pod.Reason, "pending executor reason")
+	}
+
+	// When: the driver UI is down
+	sparkUI.Close()
+	executors, err = ResolveExecutors(sparkApp, executorPods)
+
+	// Then: the executor pods are returned alone
+	assert.Error(t, err)
+	assert.Len(t, executors, 2, "executor pods")
+}
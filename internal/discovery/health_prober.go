@@ -0,0 +1,253 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package discovery
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/okdp/spark-web-proxy/internal/config"
+	sparkclient "github.com/okdp/spark-web-proxy/internal/discovery/resolvers/rest"
+	log "github.com/okdp/spark-web-proxy/internal/logging"
+	"github.com/okdp/spark-web-proxy/internal/model"
+	"github.com/okdp/spark-web-proxy/internal/store"
+)
+
+const (
+	// ProbeSuccess is the result of a health probe answered by the driver UI.
+	ProbeSuccess = "success"
+	// ProbeFailure is the result of a health probe not answered by the driver UI.
+	ProbeFailure = "failure"
+)
+
+var (
+	// HealthProbes counts the health probes of the driver UIs, by result.
+	HealthProbes = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "spark_web_proxy",
+		Subsystem: "health_probe",
+		Name:      "probes_total",
+		Help:      "Number of the health probes of the Spark driver UIs, by result (success or failure).",
+	}, []string{"result"})
+
+	// HealthProbeDuration is the duration of the health probes of the driver UIs.
+	HealthProbeDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "spark_web_proxy",
+		Subsystem: "health_probe",
+		Name:      "duration_seconds",
+		Help:      "Duration of the health probes of the Spark driver UIs, by result (success or failure).",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"result"})
+
+	// UnreachableApps is the number of the probed applications whose driver UI is unreachable.
+	UnreachableApps = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "spark_web_proxy",
+		Subsystem: "health_probe",
+		Name:      "unreachable_applications",
+		Help:      "Number of the probed Spark applications whose driver UI did not answer the last probe.",
+	})
+)
+
+// HealthProber periodically probes the driver UIs of the running applications
+// through their /api/v1/applications endpoint, records the probe results on the
+// stored applications, and marks as lost the applications whose driver UI does
+// not answer anymore, so that the stale entries are detected before a user
+// clicks them. A lost application is marked as running again when its driver
+// UI answers again.
+type HealthProber struct {
+	store            store.Store
+	interval         time.Duration
+	timeout          time.Duration
+	concurrency      int
+	failureThreshold int
+	now              func() time.Time
+}
+
+// NewHealthProber creates a HealthProber of the applications of the given store
+// according to the health probe configuration.
+func NewHealthProber(conf config.HealthProbe, sparkApps store.Store) *HealthProber {
+	prober := &HealthProber{
+		store:            sparkApps,
+		interval:         conf.Interval,
+		timeout:          conf.Timeout,
+		concurrency:      conf.Concurrency,
+		failureThreshold: conf.FailureThreshold,
+		now:              time.Now,
+	}
+	if prober.interval <= 0 {
+		prober.interval = 30 * time.Second
+	}
+	if prober.timeout <= 0 {
+		prober.timeout = sparkUIProbeTimeout
+	}
+	if prober.concurrency <= 0 {
+		prober.concurrency = 1
+	}
+	if prober.failureThreshold <= 0 {
+		prober.failureThreshold = 1
+	}
+	return prober
+}
+
+// Run probes the driver UIs every probe interval until the context is done.
+func (p *HealthProber) Run(ctx context.Context) {
+	log.Info("Probing the spark driver UIs every %s (timeout: %s, concurrency: %d, failure threshold: %d)",
+		p.interval, p.timeout, p.concurrency, p.failureThreshold)
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.ProbeAll()
+		}
+	}
+}
+
+// ProbeAll probes the driver UIs of all the probed applications, at most
+// concurrency at a time, and waits for the probes to complete.
+func (p *HealthProber) ProbeAll() {
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, p.concurrency)
+
+	for _, sparkApp := range p.store.List(store.Filter{}) {
+		if !isProbed(sparkApp) {
+			continue
+		}
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(sparkApp *model.SparkAppInstance) {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+			p.probe(sparkApp)
+		}(sparkApp)
+	}
+	wg.Wait()
+
+	unreachable := 0
+	for _, sparkApp := range p.store.List(store.Filter{}) {
+		if isProbed(sparkApp) && sparkApp.Health != nil && !sparkApp.Health.Reachable {
+			unreachable++
+		}
+	}
+	UnreachableApps.Set(float64(unreachable))
+}
+
+// isProbed reports whether the driver UI of the application is probed: the Spark
+// UI is up, or was up before the application was lost. The starting applications
+// are probed by the resolver until their Spark UI answers.
+func isProbed(sparkApp *model.SparkAppInstance) bool {
+	if sparkApp.BaseURL == "" || sparkApp.IsTombstone() {
+		return false
+	}
+	switch model.SparkAppStatus(sparkApp.Status) {
+	case model.AppUIReady, model.AppRunning, model.AppTerminating:
+		return true
+	case model.AppLost:
+		return sparkApp.Health != nil
+	default:
+		return false
+	}
+}
+
+// probe probes the driver UI of the application and records the result.
+func (p *HealthProber) probe(sparkApp *model.SparkAppInstance) {
+	start := p.now()
+	running, err := p.probeSparkUI(sparkApp)
+	latency := p.now().Sub(start)
+
+	result := ProbeSuccess
+	if err != nil {
+		result = ProbeFailure
+	}
+	HealthProbes.WithLabelValues(result).Inc()
+	HealthProbeDuration.WithLabelValues(result).Observe(latency.Seconds())
+
+	// The application may have changed during the probe
+	var lost, recovered bool
+	current, updated := p.store.Update(sparkApp.AppID, func(current *model.SparkAppInstance) bool {
+		if current.BaseURL != sparkApp.BaseURL || !isProbed(current) {
+			return false
+		}
+		// A lost application is only written again when its driver UI answers
+		if err != nil && current.Status == string(model.AppLost) && current.Health != nil && !current.Health.Reachable {
+			return false
+		}
+
+		health := model.Health{}
+		if current.Health != nil {
+			health = *current.Health
+		}
+		health.LatencyMillis = latency.Milliseconds()
+		health.LastProbeEpoch = start.UnixMilli()
+
+		if err != nil {
+			health.Reachable = false
+			health.ConsecutiveFailures++
+			health.LastError = err.Error()
+			current.Health = &health
+			lost = health.ConsecutiveFailures >= p.failureThreshold && current.TransitionTo(model.AppLost, p.now())
+			return true
+		}
+
+		wasLost := current.Status == string(model.AppLost)
+		health.Reachable = true
+		health.ConsecutiveFailures = 0
+		health.LastError = ""
+		health.LastSuccessEpoch = start.UnixMilli()
+		current.Health = &health
+		recovered = running && current.TransitionTo(model.AppRunning, p.now()) && wasLost
+		return true
+	})
+	if !updated {
+		return
+	}
+	switch {
+	case lost:
+		log.Warn("The spark ui of the application '%s' is unreachable at %s after %d probe(s): %v",
+			current.AppID, current.BaseURL, current.Health.ConsecutiveFailures, err)
+	case recovered:
+		log.Info("The spark ui of the application '%s' is reachable again at %s", current.AppID, current.BaseURL)
+	}
+}
+
+// probeSparkUI queries the /api/v1/applications endpoint of the driver UI and
+// reports whether the driver UI lists the application as running.
+func (p *HealthProber) probeSparkUI(sparkApp *model.SparkAppInstance) (bool, error) {
+	sparkClient, err := sparkclient.NewSparkUIRestClient(sparkApp.BaseURL, SparkUITransport(sparkApp), p.timeout)
+	if err != nil {
+		return false, err
+	}
+
+	apps, err := sparkClient.GetApplications()
+	if err != nil {
+		return false, err
+	}
+	for _, app := range *apps {
+		if app.ID == sparkApp.AppID && app.IsRunning() {
+			return true, nil
+		}
+	}
+	return false, nil
+}
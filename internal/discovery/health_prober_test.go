@@ -0,0 +1,106 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package discovery
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/okdp/spark-web-proxy/internal/config"
+	log "github.com/okdp/spark-web-proxy/internal/logging"
+	"github.com/okdp/spark-web-proxy/internal/model"
+	"github.com/okdp/spark-web-proxy/internal/store"
+)
+
+func Test_HealthProber(t *testing.T) {
+	// Given: a running application whose driver UI goes down and up again
+	log.SetupGlobalLogger(config.Logging{Level: "info"})
+	var down atomic.Bool
+	sparkUI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if down.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`<html>Service Unavailable</html>`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"id":"spark-1","name":"spark-pi","attempts":[{"completed":false}]}]`))
+	}))
+	defer sparkUI.Close()
+
+	sparkApps := store.NewMemoryStore()
+	sparkApps.Put(&model.SparkAppInstance{AppID: "spark-1", BaseURL: sparkUI.URL, Status: string(model.AppRunning), Source: model.SourcePod})
+	sparkApps.Put(&model.SparkAppInstance{AppID: "spark-2", BaseURL: sparkUI.URL, Status: string(model.AppStarting), Source: model.SourcePod})
+	prober := NewHealthProber(config.HealthProbe{Timeout: time.Second, Concurrency: 2, FailureThreshold: 2}, sparkApps)
+
+	// When
+	prober.ProbeAll()
+
+	// Then: the probe result is recorded on the running application only
+	app, _ := sparkApps.Get("spark-1")
+	if assert.NotNil(t, app.Health, "Health") {
+		assert.True(t, app.Health.Reachable, "Reachable")
+		assert.NotZero(t, app.Health.LastSuccessEpoch, "LastSuccessEpoch")
+		assert.Equal(t, app.Health.LastProbeEpoch, app.Health.LastSuccessEpoch, "LastSuccessEpoch")
+	}
+	starting, _ := sparkApps.Get("spark-2")
+	assert.Nil(t, starting.Health, "Health of the starting application")
+
+	// When: the driver UI does not answer once
+	down.Store(true)
+	prober.ProbeAll()
+
+	// Then: the application is still running until the failure threshold is reached
+	app, _ = sparkApps.Get("spark-1")
+	assert.Equal(t, string(model.AppRunning), app.Status, "Status")
+	assert.False(t, app.Health.Reachable, "Reachable")
+	assert.Equal(t, 1, app.Health.ConsecutiveFailures, "ConsecutiveFailures")
+
+	// When
+	prober.ProbeAll()
+
+	// Then: the application is unreachable
+	app, _ = sparkApps.Get("spark-1")
+	assert.Equal(t, string(model.AppLost), app.Status, "Status")
+	assert.Equal(t, 2, app.Health.ConsecutiveFailures, "ConsecutiveFailures")
+	assert.NotEmpty(t, app.Health.LastError, "LastError")
+	assert.NotZero(t, app.Health.LastSuccessEpoch, "LastSuccessEpoch")
+
+	// When: the driver UI still does not answer
+	lastProbeEpoch := app.Health.LastProbeEpoch
+	prober.ProbeAll()
+
+	// Then: the lost application is not written again
+	app, _ = sparkApps.Get("spark-1")
+	assert.Equal(t, 2, app.Health.ConsecutiveFailures, "ConsecutiveFailures")
+	assert.Equal(t, lastProbeEpoch, app.Health.LastProbeEpoch, "LastProbeEpoch")
+
+	// When: the driver UI answers again
+	down.Store(false)
+	prober.ProbeAll()
+
+	// Then: the application is recovered
+	app, _ = sparkApps.Get("spark-1")
+	assert.Equal(t, string(model.AppRunning), app.Status, "Status")
+	assert.True(t, app.Health.Reachable, "Reachable")
+	assert.Zero(t, app.Health.ConsecutiveFailures, "ConsecutiveFailures")
+	assert.Empty(t, app.Health.LastError, "LastError")
+}
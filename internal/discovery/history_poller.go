@@ -0,0 +1,140 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/okdp/spark-web-proxy/internal/config"
+	sparkclient "github.com/okdp/spark-web-proxy/internal/discovery/resolvers/rest"
+	log "github.com/okdp/spark-web-proxy/internal/logging"
+	"github.com/okdp/spark-web-proxy/internal/model"
+	"github.com/okdp/spark-web-proxy/internal/store"
+)
+
+// sparkHistoryPollTimeout is the timeout of a single Spark History request of the poller.
+const sparkHistoryPollTimeout = 10 * time.Second
+
+// HistoryPoller periodically lists the incomplete applications of the Spark History
+// Server to register the applications which are not discovered from Kubernetes
+// (e.g. client mode drivers and notebooks) as soon as their event logs are written,
+// instead of when their Spark UI is first requested.
+//
+// The Spark UI of a polled application is resolved from the driver host and Spark UI
+// port of its environment. The polled applications are removed from the store once
+// Spark History no longer reports them as running.
+type HistoryPoller struct {
+	store               store.Store
+	sparkHistoryBaseURL string
+	interval            time.Duration
+}
+
+// NewHistoryPoller creates a HistoryPoller registering the applications in the
+// given store according to the application configuration.
+func NewHistoryPoller(config *config.ApplicationConfig, sparkApps store.Store) *HistoryPoller {
+	interval := config.Spark.Discovery.History.PollInterval
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	return &HistoryPoller{
+		store:               sparkApps,
+		sparkHistoryBaseURL: config.GetSparkHistoryBaseURL(),
+		interval:            interval,
+	}
+}
+
+// Run polls Spark History every poll interval until the context is done.
+func (p *HistoryPoller) Run(ctx context.Context) {
+	log.Info("Polling the running applications of spark history at %s every %s", p.sparkHistoryBaseURL, p.interval)
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := p.Poll(); err != nil {
+			log.Warn("Unable to poll the running applications of spark history at %s: %v", p.sparkHistoryBaseURL, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll lists the running applications of Spark History, registers the applications
+// which are not tracked yet and removes the polled applications which are no longer running.
+func (p *HistoryPoller) Poll() error {
+	sparkClient, err := sparkclient.NewSparkUIRestClient(p.sparkHistoryBaseURL, nil, sparkHistoryPollTimeout)
+	if err != nil {
+		return err
+	}
+	sparkClient.Request.URL.RawQuery = url.Values{"status": []string{"running"}}.Encode()
+
+	apps, err := sparkClient.GetApplications()
+	if err != nil {
+		return err
+	}
+
+	running := make(map[string]bool, len(*apps))
+	for _, app := range *apps {
+		if !app.IsRunning() {
+			continue
+		}
+		running[app.ID] = true
+		if _, found := p.store.Get(app.ID); found {
+			continue
+		}
+		if sparkApp, err := p.resolve(app); err != nil {
+			log.Warn("Unable to resolve the spark ui of the application '%s' from spark history: %v", app.ID, err)
+		} else if store.Add(p.store, sparkApp) {
+			log.Info("The application '%s' reported running by spark history is served at %s", sparkApp.AppID, sparkApp.BaseURL)
+		}
+	}
+
+	for _, sparkApp := range p.store.List(store.Filter{Source: model.SourceHistory}) {
+		if running[sparkApp.AppID] || sparkApp.Status == string(model.AppUnknown) {
+			continue
+		}
+		p.store.Delete(sparkApp.AppID)
+		log.Info("The application '%s' is no longer reported running by spark history", sparkApp.AppID)
+	}
+	return nil
+}
+
+// resolve resolves the Spark UI of a running application from its environment.
+func (p *HistoryPoller) resolve(app model.SparkApp) (*model.SparkAppInstance, error) {
+	sparkClient, err := sparkclient.NewSparkUIRestClient(p.sparkHistoryBaseURL, nil, sparkHistoryPollTimeout)
+	if err != nil {
+		return nil, err
+	}
+	sparkAppEnv, err := sparkClient.GetEnvironment(app.ID)
+	if err != nil {
+		return nil, err
+	}
+	if host, found := sparkAppEnv.GetProperty("spark.driver.host"); !found || host == "" {
+		return nil, fmt.Errorf("the spark.driver.host property is not set")
+	}
+
+	sparkApp := sparkAppFromHistory(&app, sparkAppEnv)
+	sparkApp.AppID = app.ID
+	sparkApp.TransitionTo(model.AppRunning, time.Now())
+	return sparkApp, nil
+}
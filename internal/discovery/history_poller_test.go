@@ -0,0 +1,95 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package discovery
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/okdp/spark-web-proxy/internal/config"
+	log "github.com/okdp/spark-web-proxy/internal/logging"
+	"github.com/okdp/spark-web-proxy/internal/model"
+	"github.com/okdp/spark-web-proxy/internal/store"
+)
+
+func Test_HistoryPoller(t *testing.T) {
+	// Given: a spark history reporting a client mode application and a cluster mode application
+	log.SetupGlobalLogger(config.Logging{Level: "info"})
+	var completed atomic.Bool
+	var query string
+	sparkHistory := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/applications":
+			query = r.URL.RawQuery
+			if completed.Load() {
+				_, _ = w.Write([]byte(`[]`))
+				return
+			}
+			_, _ = w.Write([]byte(`[
+				{"id":"spark-1","name":"notebook","attempts":[{"completed":false,"sparkUser":"alice","startTimeEpoch":1000}]},
+				{"id":"spark-2","name":"spark-pi","attempts":[{"completed":false}]}
+			]`))
+		case "/api/v1/applications/spark-1/environment":
+			_, _ = w.Write([]byte(`{"sparkProperties":[
+				["spark.app.id","spark-1"],["spark.app.name","notebook"],
+				["spark.driver.host","10.0.0.1"],["spark.ui.port","4041"],
+				["spark.kubernetes.namespace","jupyter"]
+			]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer sparkHistory.Close()
+
+	sparkApps := store.NewMemoryStore()
+	sparkApps.Put(&model.SparkAppInstance{AppID: "spark-2", Status: string(model.AppRunning), Source: model.SourcePod})
+	poller := &HistoryPoller{store: sparkApps, sparkHistoryBaseURL: sparkHistory.URL}
+
+	// When
+	err := poller.Poll()
+
+	// Then: the client mode application is registered
+	assert.NoError(t, err)
+	assert.Equal(t, "status=running", query, "query")
+	sparkApp, found := sparkApps.Get("spark-1")
+	if assert.True(t, found, "spark-1 registered") {
+		assert.Equal(t, "http://10.0.0.1:4041", sparkApp.BaseURL, "BaseURL")
+		assert.Equal(t, "jupyter", sparkApp.Namespace, "Namespace")
+		assert.Equal(t, "alice", sparkApp.User, "User")
+		assert.Equal(t, int64(1000), sparkApp.StartTimeEpoch, "StartTimeEpoch")
+		assert.Equal(t, model.SourceHistory, sparkApp.Source, "Source")
+		assert.Equal(t, string(model.AppRunning), sparkApp.Status, "Status")
+	}
+	clusterApp, _ := sparkApps.Get("spark-2")
+	assert.Equal(t, model.SourcePod, clusterApp.Source, "the discovered application is not replaced")
+
+	// When: spark history no longer reports the applications as running
+	completed.Store(true)
+	err = poller.Poll()
+
+	// Then: the client mode application is removed
+	assert.NoError(t, err)
+	_, found = sparkApps.Get("spark-1")
+	assert.False(t, found, "spark-1 removed")
+	_, found = sparkApps.Get("spark-2")
+	assert.True(t, found, "spark-2 kept")
+}
@@ -0,0 +1,166 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package discovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	kubeclient "github.com/okdp/spark-web-proxy/internal/discovery/resolvers/k8s/client"
+	sparkclient "github.com/okdp/spark-web-proxy/internal/discovery/resolvers/rest"
+	log "github.com/okdp/spark-web-proxy/internal/logging"
+	"github.com/okdp/spark-web-proxy/internal/model"
+)
+
+// sparkUIProbes holds the applications whose Spark UI is being probed.
+var sparkUIProbes sync.Map
+
+// podSparkAppStatus returns the lifecycle state of a Spark application observed
+// from its driver pod. A terminated driver container is final even if a sidecar
+// container keeps the pod running, and a running driver container is Starting
+// until its Spark UI is probed.
+func podSparkAppStatus(pod *corev1.Pod) model.SparkAppStatus {
+	switch pod.Status.Phase {
+	case corev1.PodSucceeded:
+		return model.AppSucceeded
+	case corev1.PodFailed:
+		return model.AppFailed
+	}
+
+	if status := driverContainerStatus(pod); status != nil && status.State.Terminated != nil {
+		if status.State.Terminated.ExitCode == 0 {
+			return model.AppSucceeded
+		}
+		return model.AppFailed
+	}
+
+	switch {
+	case pod.DeletionTimestamp != nil:
+		return model.AppTerminating
+	case pod.Status.Phase == corev1.PodRunning:
+		return model.AppStarting
+	case pod.Status.Phase == corev1.PodUnknown:
+		return model.AppLost
+	default:
+		return model.AppPending
+	}
+}
+
+// driverContainerState returns a description of the driver container state
+// (e.g. "Waiting: ContainerCreating") and whether the driver container is ready.
+func driverContainerState(pod *corev1.Pod) (string, bool) {
+	return containerState(driverContainerStatus(pod))
+}
+
+// containerState returns a description of the state of a container status and
+// whether the container is ready.
+func containerState(status *corev1.ContainerStatus) (string, bool) {
+	switch {
+	case status == nil:
+		return "", false
+	case status.State.Waiting != nil:
+		return fmt.Sprintf("Waiting: %s", status.State.Waiting.Reason), status.Ready
+	case status.State.Terminated != nil:
+		return fmt.Sprintf("Terminated: %s (exit code %d)", status.State.Terminated.Reason, status.State.Terminated.ExitCode), status.Ready
+	case status.State.Running != nil:
+		return "Running", status.Ready
+	default:
+		return "", status.Ready
+	}
+}
+
+// probeSparkUIAsync asynchronously probes the Spark UI of a starting application
+// with retries, and moves the application to UIReady once the Spark UI answers,
+// then to Running once the Spark UI reports the running application, whose Spark
+// UI settings are then read if they are not known yet. The probe stops when the
+// application leaves the Starting and UIReady states.
+func (r SparkAppResolver) probeSparkUIAsync(cluster string, appID string, sparkUIBaseURL string) {
+	if _, inProgress := sparkUIProbes.LoadOrStore(appID, true); inProgress {
+		return
+	}
+
+	go func() {
+		defer sparkUIProbes.Delete(appID)
+
+		isStarting := func(sparkApp *model.SparkAppInstance, found bool) bool {
+			return found && (sparkApp.Status == string(model.AppStarting) || sparkApp.Status == string(model.AppUIReady))
+		}
+
+		err := wait.ExponentialBackoffWithContext(context.Background(), sparkAppIDBackoff, func(context.Context) (bool, error) {
+			if !isStarting(r.store.Get(appID)) {
+				return true, nil
+			}
+
+			status, err := probeSparkUI(cluster, appID, sparkUIBaseURL)
+			if err != nil {
+				log.Debug("The spark ui of the application '%s' is not ready yet at %s: %v", appID, sparkUIBaseURL, err)
+			}
+
+			// The application may have changed during the probe
+			starting := false
+			_, transitioned := r.store.Update(appID, func(sparkApp *model.SparkAppInstance) bool {
+				starting = isStarting(sparkApp, true)
+				return starting && sparkApp.TransitionTo(status, time.Now())
+			})
+			if !starting {
+				return true, nil
+			}
+			if transitioned {
+				log.Info("The application '%s' is %s at %s", appID, status, sparkUIBaseURL)
+			}
+			return status == model.AppRunning, nil
+		})
+		if err != nil {
+			log.Warn("The spark ui of the application '%s' is still not ready at %s: %v", appID, sparkUIBaseURL, err)
+			return
+		}
+		resolveSparkUISettings(r.store, appID)
+	}()
+}
+
+// probeSparkUI queries the Spark UI /api/v1/applications endpoint and returns
+// the observed state of the application: Starting if the Spark UI does not
+// answer, UIReady if it does not report the running application yet, Running otherwise.
+func probeSparkUI(cluster string, appID string, sparkUIBaseURL string) (model.SparkAppStatus, error) {
+	sparkClient, err := sparkclient.NewSparkUIRestClient(sparkUIBaseURL, kubeclient.GetTransport(cluster), sparkUIProbeTimeout)
+	if err != nil {
+		return model.AppStarting, err
+	}
+
+	apps, err := sparkClient.GetApplications()
+	var urlErr *url.Error
+	switch {
+	case errors.As(err, &urlErr):
+		return model.AppStarting, err
+	case err != nil:
+		return model.AppUIReady, err
+	}
+
+	for _, app := range *apps {
+		if app.ID == appID && app.IsRunning() {
+			return model.AppRunning, nil
+		}
+	}
+	return model.AppUIReady, fmt.Errorf("the application is not listed by the spark ui")
+}
@@ -0,0 +1,202 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package discovery
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/okdp/spark-web-proxy/internal/config"
+	log "github.com/okdp/spark-web-proxy/internal/logging"
+	"github.com/okdp/spark-web-proxy/internal/model"
+	"github.com/okdp/spark-web-proxy/internal/store"
+)
+
+func Test_PodSparkAppStatus(t *testing.T) {
+	now := metav1.Now()
+	driver := func(state corev1.ContainerState) []corev1.ContainerStatus {
+		return []corev1.ContainerStatus{
+			{Name: "spark-kubernetes-driver", State: state},
+			{Name: "istio-proxy", State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		pod      corev1.Pod
+		expected model.SparkAppStatus
+	}{
+		{"pending pod", corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodPending}}, model.AppPending},
+		{"running driver", corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodRunning,
+			ContainerStatuses: driver(corev1.ContainerState{Running: &corev1.ContainerStateRunning{}})}}, model.AppStarting},
+		{"driver exited, sidecar running", corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodRunning,
+			ContainerStatuses: driver(corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 1}})}}, model.AppFailed},
+		{"deleted pod", corev1.Pod{ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: &now}, Status: corev1.PodStatus{Phase: corev1.PodRunning}}, model.AppTerminating},
+		{"lost node", corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodUnknown}}, model.AppLost},
+		{"succeeded pod", corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodSucceeded}}, model.AppSucceeded},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, podSparkAppStatus(&test.pod))
+		})
+	}
+}
+
+func Test_ProbeSparkUIAsync(t *testing.T) {
+	// Given: a driver UI which is bound before the application is reported
+	log.SetupGlobalLogger(config.Logging{Level: "info"})
+	withFastSparkAppIDBackoff(t)
+
+	var requests atomic.Int32
+	sparkUI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if requests.Add(1) < 3 {
+			_, _ = w.Write([]byte(`<html>Spark UI is initializing</html>`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"id":"spark-1","name":"spark-pi","attempts":[{"completed":false}]}]`))
+	}))
+	defer sparkUI.Close()
+	sparkUIURL, _ := url.Parse(sparkUI.URL)
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "spark-pi-driver",
+			Namespace:   "spark",
+			Annotations: map[string]string{"spark-web-proxy.okdp.io/ui-port": sparkUIURL.Port()},
+		},
+		Spec:   corev1.PodSpec{Containers: []corev1.Container{{Env: []corev1.EnvVar{{Name: "SPARK_APPLICATION_ID", Value: "spark-1"}}}}},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning, PodIP: sparkUIURL.Hostname()},
+	}
+	sparkApps := store.NewMemoryStore()
+	resolver := NewSparkAppResolver(sparkApps)
+
+	// When
+	sparkApp, _ := resolver.ResolveSparkAppFromPod("default", pod)
+
+	// Then: the application is starting until its UI reports it
+	assert.Equal(t, string(model.AppStarting), sparkApp.Status, "Status")
+	assert.Eventually(t, func() bool {
+		app, _ := sparkApps.Get("spark-1")
+		return app.IsRunning() && app.Status == string(model.AppRunning)
+	}, 5*time.Second, 10*time.Millisecond, "running application")
+
+	app, _ := sparkApps.Get("spark-1")
+	statuses := make([]string, 0, len(app.Transitions))
+	for _, transition := range app.Transitions {
+		statuses = append(statuses, transition.Status)
+	}
+	assert.Equal(t, []string{"Starting", "UIReady", "Running"}, statuses, "Transitions")
+
+	// When: the pod is updated again
+	sparkApp, _ = resolver.ResolveSparkAppFromPod("default", pod)
+
+	// Then: the application stays running
+	assert.Equal(t, string(model.AppRunning), sparkApp.Status, "Status")
+}
+
+func Test_ResolveSparkAppFromPod_Status_Override(t *testing.T) {
+	// Given: a pending driver pod whose application was marked as lost by the proxy
+	log.SetupGlobalLogger(config.Logging{Level: "info"})
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "spark-pi-driver", Namespace: "spark", UID: "uid-1", ResourceVersion: "1"},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Env: []corev1.EnvVar{{Name: "SPARK_APPLICATION_ID", Value: "spark-1"}}}}},
+		Status:     corev1.PodStatus{Phase: corev1.PodPending},
+	}
+	sparkApps := store.NewMemoryStore()
+	resolver := NewSparkAppResolver(sparkApps)
+	_, err := resolver.ResolveSparkAppFromPod("default", pod)
+	assert.NoError(t, err)
+	store.MarkLost(sparkApps, "spark-1")
+
+	// When: the pod informer resyncs
+	sparkApp, _ := resolver.ResolveSparkAppFromPod("default", pod)
+
+	// Then: the status set by the proxy is kept
+	assert.Equal(t, string(model.AppLost), sparkApp.Status, "Status")
+	assert.True(t, sparkApp.StatusOverride, "StatusOverride")
+
+	// When: the driver pod is recreated
+	recreated := pod.DeepCopy()
+	recreated.UID = "uid-2"
+	sparkApp, _ = resolver.ResolveSparkAppFromPod("default", recreated)
+
+	// Then: the application follows the new driver pod
+	assert.Equal(t, string(model.AppPending), sparkApp.Status, "Status")
+	assert.False(t, sparkApp.StatusOverride, "StatusOverride")
+}
+
+func Test_ResolveSparkAppFromPod_Shared_Status_Override(t *testing.T) {
+	// Given: two replicas sharing their status overrides and tracking the same pending driver pod
+	log.SetupGlobalLogger(config.Logging{Level: "info"})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	clientset := fake.NewSimpleClientset()
+	conf := config.Store{Shared: config.SharedStore{Enabled: true, Namespace: "spark-web-proxy", Name: "spark-web-proxy-state", SyncInterval: 10 * time.Millisecond}}
+	replicas := make([]*store.SharedStore, 0, 2)
+	for _, replica := range []string{"replica-a", "replica-b"} {
+		t.Setenv("POD_NAME", replica)
+		sharedStore := store.NewSharedStore(store.NewMemoryStore(), clientset, conf)
+		go sharedStore.Run(ctx)
+		replicas = append(replicas, sharedStore)
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "spark-pi-driver", Namespace: "spark", UID: "uid-1", ResourceVersion: "1"},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Env: []corev1.EnvVar{{Name: "SPARK_APPLICATION_ID", Value: "spark-1"}}}}},
+		Status:     corev1.PodStatus{Phase: corev1.PodPending},
+	}
+	for _, replica := range replicas {
+		_, err := NewSparkAppResolver(replica).ResolveSparkAppFromPod("default", pod)
+		assert.NoError(t, err)
+	}
+
+	// When: the replica A marks the application as lost
+	store.MarkLost(replicas[0], "spark-1")
+
+	// Then: the replica B agrees
+	assert.Eventually(t, func() bool {
+		app, _ := replicas[1].Get("spark-1")
+		return app.Status == string(model.AppLost)
+	}, 5*time.Second, 10*time.Millisecond, "lost on replica B")
+
+	// When: the pod informers of the replicas resync
+	for _, replica := range replicas {
+		_, err := NewSparkAppResolver(replica).ResolveSparkAppFromPod("default", pod)
+		assert.NoError(t, err)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	// Then: the status override is still shared
+	configMap, err := clientset.CoreV1().ConfigMaps("spark-web-proxy").Get(ctx, "spark-web-proxy-state", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Contains(t, configMap.Data, "spark-1", "shared entries")
+	for _, replica := range replicas {
+		app, _ := replica.Get("spark-1")
+		assert.Equal(t, string(model.AppLost), app.Status, "Status")
+	}
+}
@@ -0,0 +1,181 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package discovery
+
+import (
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	kubeclient "github.com/okdp/spark-web-proxy/internal/discovery/resolvers/k8s/client"
+	sparkclient "github.com/okdp/spark-web-proxy/internal/discovery/resolvers/rest"
+	log "github.com/okdp/spark-web-proxy/internal/logging"
+	"github.com/okdp/spark-web-proxy/internal/model"
+	"github.com/okdp/spark-web-proxy/internal/store"
+	"github.com/okdp/spark-web-proxy/internal/utils"
+)
+
+// sparkUIProbeTimeout is the timeout of a single Spark UI probe.
+const sparkUIProbeTimeout = 2 * time.Second
+
+// podSparkUIProbes holds the pods whose Spark UIs are being probed.
+var podSparkUIProbes sync.Map
+
+// ResolveSparkAppsFromPod resolves all the Spark application instances hosted by
+// a Kubernetes pod running on the given cluster and registers them in the application store.
+//
+// A pod hosting a single Spark application is resolved from its metadata, as in
+// ResolveSparkAppFromPod. A pod that may host several Spark applications over its
+// lifetime or concurrently (notebook kernels, Spark Connect servers) is probed
+// asynchronously on each of its advertised Spark UI ports through the
+// /api/v1/applications endpoint, so that the informer is not blocked: the
+// applications of the pod known so far are returned. Every live application is
+// then registered, and the applications of the pod that are no longer served are removed.
+func (r SparkAppResolver) ResolveSparkAppsFromPod(cluster string, pod *corev1.Pod) ([]*model.SparkAppInstance, error) {
+	if !utils.HasMultipleSparkUIs(pod) {
+		sparkApp, err := r.ResolveSparkAppFromPod(cluster, pod)
+		return []*model.SparkAppInstance{sparkApp}, err
+	}
+
+	// The Spark UIs can only be probed once the pod is running
+	if pod.Status.Phase != corev1.PodRunning || pod.Status.PodIP == "" {
+		sparkApps := make([]*model.SparkAppInstance, 0)
+		for _, sparkApp := range r.store.List(store.Filter{Cluster: cluster, Namespace: pod.Namespace, PodName: pod.Name}) {
+			sparkApp, found := r.store.Update(sparkApp.AppID, func(app *model.SparkAppInstance) bool {
+				// A status set by the proxy (e.g. lost) is kept until the pod phase changes
+				if !app.StatusOverride || app.PodPhase != string(pod.Status.Phase) {
+					app.TransitionTo(podSparkAppStatus(pod), time.Now())
+				}
+				app.PodPhase = string(pod.Status.Phase)
+				return true
+			})
+			if found {
+				sparkApps = append(sparkApps, sparkApp)
+			}
+		}
+		return sparkApps, nil
+	}
+
+	r.probeSparkUIsAsync(cluster, pod)
+	return r.store.List(store.Filter{Cluster: cluster, Namespace: pod.Namespace, PodName: pod.Name}), nil
+}
+
+// probeSparkUIsAsync asynchronously probes the Spark UIs of a pod hosting several
+// Spark applications and registers its live applications. A pod is probed once at a time.
+func (r SparkAppResolver) probeSparkUIsAsync(cluster string, pod *corev1.Pod) {
+	key := model.SparkPodAppKey(cluster, pod.Namespace, pod.Name)
+	if _, inProgress := podSparkUIProbes.LoadOrStore(key, true); inProgress {
+		return
+	}
+
+	go func() {
+		defer podSparkUIProbes.Delete(key)
+		r.registerLiveSparkApps(cluster, pod, probeSparkUIs(cluster, pod))
+	}()
+}
+
+// registerLiveSparkApps registers the live applications of a pod, and removes the
+// applications of the pod that are no longer served. The applications whose pod
+// was deleted during the probe are left as tombstones.
+func (r SparkAppResolver) registerLiveSparkApps(cluster string, pod *corev1.Pod, liveApps []*model.SparkAppInstance) {
+	live := make(map[string]bool, len(liveApps))
+	for _, sparkApp := range liveApps {
+		live[sparkApp.AppID] = true
+		sparkApp, registered := store.Upsert(r.store, sparkApp.AppID, func(existing *model.SparkAppInstance) *model.SparkAppInstance {
+			if existing != nil && existing.IsTombstone() {
+				return nil
+			}
+			merged := *sparkApp
+			if existing != nil {
+				merged.InheritState(existing)
+			}
+			merged.TransitionTo(model.AppRunning, time.Now())
+			return &merged
+		})
+		if registered && sparkApp.UI == nil {
+			resolveSparkUISettingsAsync(r.store, sparkApp.AppID)
+		}
+	}
+
+	for _, sparkApp := range r.store.List(store.Filter{Cluster: cluster, Namespace: pod.Namespace, PodName: pod.Name}) {
+		if !live[sparkApp.AppID] && !sparkApp.IsTombstone() {
+			log.Info("The application '%s' (%s:%s/%s) is no longer served at %s", sparkApp.AppID, cluster, pod.Namespace, pod.Name, sparkApp.BaseURL)
+			r.store.Delete(sparkApp.AppID)
+		}
+	}
+}
+
+// probeSparkUIs concurrently queries the Spark UI REST API on each advertised
+// port of the pod and returns the running applications found.
+func probeSparkUIs(cluster string, pod *corev1.Pod) []*model.SparkAppInstance {
+	var (
+		mu        sync.Mutex
+		wg        sync.WaitGroup
+		sparkApps = make([]*model.SparkAppInstance, 0)
+	)
+
+	for _, port := range utils.GetSparkUIPorts(pod) {
+		wg.Add(1)
+		go func(port int32) {
+			defer wg.Done()
+			baseURL := sparkUIBaseURL(cluster, pod, port)
+			sparkClient, err := sparkclient.NewSparkUIRestClient(baseURL, kubeclient.GetTransport(cluster), sparkUIProbeTimeout)
+			if err != nil {
+				log.Warn("Unable to create new spark ui client for %s: %+v", baseURL, err)
+				return
+			}
+			apps, err := sparkClient.GetApplications()
+			if err != nil {
+				log.Debug("No spark ui is listening at %s: %v", baseURL, err)
+				return
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			for _, app := range *apps {
+				if !app.IsRunning() {
+					continue
+				}
+				sparkApps = append(sparkApps, &model.SparkAppInstance{
+					BaseURL:        baseURL,
+					PodName:        pod.Name,
+					AppID:          app.ID,
+					AppName:        app.Name,
+					Namespace:      pod.Namespace,
+					StartTimeEpoch: podStartTimeEpoch(pod),
+					Cluster:        cluster,
+					PodPhase:       string(pod.Status.Phase),
+					User:           sparkUser(app),
+					Source:         model.SourcePod,
+					UI:             sparkUISettingsFromPod(pod),
+				})
+			}
+		}(port)
+	}
+
+	wg.Wait()
+	return sparkApps
+}
+
+// sparkUser returns the user of the first attempt of the application, if any.
+func sparkUser(app model.SparkApp) string {
+	if len(app.Attempts) == 0 {
+		return ""
+	}
+	return app.Attempts[0].SparkUser
+}
@@ -0,0 +1,146 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package discovery
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/okdp/spark-web-proxy/internal/config"
+	"github.com/okdp/spark-web-proxy/internal/constants"
+	log "github.com/okdp/spark-web-proxy/internal/logging"
+	"github.com/okdp/spark-web-proxy/internal/model"
+	"github.com/okdp/spark-web-proxy/internal/store"
+)
+
+// newSparkUI starts a Spark UI listing the running applications returned by apps.
+func newSparkUI(t *testing.T, apps func() string) *url.URL {
+	sparkUI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/applications" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(apps()))
+	}))
+	t.Cleanup(sparkUI.Close)
+	sparkUIURL, _ := url.Parse(sparkUI.URL)
+	return sparkUIURL
+}
+
+func Test_ResolveSparkAppsFromPod(t *testing.T) {
+	// Given: a notebook pod serving two Spark UIs
+	log.SetupGlobalLogger(config.Logging{Level: "info"})
+	var stopped atomic.Bool
+	sparkUI1 := newSparkUI(t, func() string {
+		return `[{"id":"spark-1","name":"notebook-1","attempts":[{"completed":false,"sparkUser":"alice"}]}]`
+	})
+	sparkUI2 := newSparkUI(t, func() string {
+		if stopped.Load() {
+			return `[{"id":"spark-2","name":"notebook-2","attempts":[{"completed":true,"duration":1000,"endTimeEpoch":2000}]}]`
+		}
+		return `[{"id":"spark-2","name":"notebook-2","attempts":[{"completed":false}]}]`
+	})
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "jupyter",
+			Namespace:   "spark",
+			Annotations: map[string]string{constants.AnnotationUIPorts: fmt.Sprintf("%s,%s", sparkUI1.Port(), sparkUI2.Port())},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning, PodIP: sparkUI1.Hostname()},
+	}
+	sparkApps := store.NewMemoryStore()
+	resolver := NewSparkAppResolver(sparkApps)
+	podApps := func() []*model.SparkAppInstance {
+		return sparkApps.List(store.Filter{Cluster: "default", Namespace: "spark", PodName: "jupyter"})
+	}
+
+	// When
+	_, err := resolver.ResolveSparkAppsFromPod("default", pod)
+
+	// Then: both applications are registered in the background
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return len(podApps()) == 2
+	}, 5*time.Second, 10*time.Millisecond, "live applications")
+	sparkApp, _ := sparkApps.Get("spark-1")
+	assert.Equal(t, "http://"+sparkUI1.Host, sparkApp.BaseURL, "BaseURL")
+	assert.Equal(t, "alice", sparkApp.User, "User")
+	assert.Equal(t, string(model.AppRunning), sparkApp.Status, "Status")
+
+	// When: the second application is stopped
+	stopped.Store(true)
+	assert.Eventually(t, func() bool {
+		_, _ = resolver.ResolveSparkAppsFromPod("default", pod)
+		_, found := sparkApps.Get("spark-2")
+		return !found
+	}, 5*time.Second, 10*time.Millisecond, "stopped application removed")
+
+	// Then: the first application is kept
+	_, found := sparkApps.Get("spark-1")
+	assert.True(t, found, "spark-1 kept")
+
+	// When: the pod is stopping
+	pod.Status.Phase = corev1.PodSucceeded
+	stoppingApps, err := resolver.ResolveSparkAppsFromPod("default", pod)
+
+	// Then: all the applications of the pod are completed
+	assert.NoError(t, err)
+	for _, sparkApp := range stoppingApps {
+		assert.Equal(t, string(model.AppSucceeded), sparkApp.Status, sparkApp.AppID)
+	}
+	for _, sparkApp := range podApps() {
+		assert.True(t, sparkApp.IsCompleted(), sparkApp.AppID)
+	}
+}
+
+func Test_TombstoneSparkAppsFromPod_Multiple_Apps(t *testing.T) {
+	// Given: a deleted notebook pod hosting two applications
+	log.SetupGlobalLogger(config.Logging{Level: "info"})
+	sparkApps := store.NewMemoryStore()
+	for _, appID := range []string{"spark-1", "spark-2"} {
+		sparkApps.Put(&model.SparkAppInstance{AppID: appID, Cluster: "default", Namespace: "spark", PodName: "jupyter",
+			Status: string(model.AppRunning), Source: model.SourcePod})
+	}
+	resolver := NewSparkAppResolver(sparkApps)
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "jupyter", Namespace: "spark"}}
+
+	// When
+	tombstones := resolver.TombstoneSparkAppsFromPod("default", pod)
+
+	// Then: all the applications of the pod are cleaned up
+	assert.Len(t, tombstones, 2, "tombstones")
+	for _, sparkApp := range sparkApps.List(store.Filter{Cluster: "default", Namespace: "spark", PodName: "jupyter"}) {
+		assert.True(t, sparkApp.IsTombstone(), sparkApp.AppID)
+	}
+
+	// When: a late probe of the pod reports the applications as not served
+	resolver.registerLiveSparkApps("default", pod, nil)
+
+	// Then: the tombstones are kept
+	assert.Len(t, sparkApps.List(store.Filter{Source: model.SourceTombstone}), 2, "tombstones")
+}
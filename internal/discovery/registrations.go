@@ -0,0 +1,386 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package discovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/okdp/spark-web-proxy/internal/config"
+	kubeclient "github.com/okdp/spark-web-proxy/internal/discovery/resolvers/k8s/client"
+	log "github.com/okdp/spark-web-proxy/internal/logging"
+	"github.com/okdp/spark-web-proxy/internal/model"
+	"github.com/okdp/spark-web-proxy/internal/store"
+)
+
+var (
+	// ErrInvalidRegistration is returned when a registration misses the application
+	// ID or has an invalid Spark UI URL.
+	ErrInvalidRegistration = errors.New("invalid registration")
+	// ErrRegistrationConflict is returned when the registered application is
+	// already tracked from another source.
+	ErrRegistrationConflict = errors.New("the application is already tracked")
+	// ErrRegistrationForbidden is returned when an application is registered again
+	// or unregistered with another token than the one which registered it.
+	ErrRegistrationForbidden = errors.New("the application was registered with another token")
+)
+
+// Registration is an application pushed by its launcher or a Spark listener.
+type Registration struct {
+	AppID string `json:"appId"`
+	// UIURL is the base URL of the Spark UI of the application (e.g. http://10.0.0.1:4040).
+	UIURL     string            `json:"uiUrl"`
+	AppName   string            `json:"appName,omitempty"`
+	Namespace string            `json:"namespace,omitempty"`
+	Owner     string            `json:"owner,omitempty"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	// Registrant is the fingerprint of the token of the registration request.
+	Registrant string `json:"-"`
+}
+
+// Registrations registers in the store the applications pushed through the
+// registration API, which are not discovered from Kubernetes (e.g. drivers
+// running on VMs). The launchers register an application again as a heartbeat,
+// and the applications which are not registered again within the heartbeat
+// timeout expire.
+//
+// A registered application is bound to the token which registered it, and the
+// hosts of the Spark UI URLs are restricted to the allowed hosts, both when
+// registering and when dialing, as the Spark UIs are reachable through the proxy
+// without authentication.
+type Registrations struct {
+	store            store.Store
+	heartbeatTimeout time.Duration
+	allowedHosts     *hostAllowlist
+	now              func() time.Time
+
+	// mu serializes the registrations, so that a heartbeat does not race with the expiry.
+	mu sync.Mutex
+}
+
+// NewRegistrations creates the Registrations of the given store according to
+// the registration configuration.
+func NewRegistrations(conf config.Registration, sparkApps store.Store) *Registrations {
+	heartbeatTimeout := conf.HeartbeatTimeout
+	if heartbeatTimeout <= 0 {
+		heartbeatTimeout = 2 * time.Minute
+	}
+	if len(conf.AllowedHosts) == 0 {
+		log.Warn("No host is allowed in the Spark UI URLs of the registered applications, " +
+			"set spark.discovery.registration.allowedHosts to allow them")
+	}
+	allowedHosts := newHostAllowlist(conf.AllowedHosts)
+	registrationTransport.Store(allowedHosts.transport())
+	return &Registrations{
+		store:            sparkApps,
+		heartbeatTimeout: heartbeatTimeout,
+		allowedHosts:     allowedHosts,
+		now:              time.Now,
+	}
+}
+
+// Register registers an application, or refreshes the heartbeat of an already
+// registered application, and reports whether the application was created.
+// An application tracked from another source can only be registered once it is completed,
+// and a registered application can only be registered again with the same token.
+func (r *Registrations) Register(registration Registration) (*model.SparkAppInstance, bool, error) {
+	if err := r.validateRegistration(registration); err != nil {
+		return nil, false, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	sparkApp := &model.SparkAppInstance{
+		BaseURL:            strings.TrimSuffix(registration.UIURL, "/"),
+		AppID:              registration.AppID,
+		AppName:            registration.AppName,
+		Namespace:          registration.Namespace,
+		User:               registration.Owner,
+		Labels:             registration.Labels,
+		StartTimeEpoch:     now.UnixMilli(),
+		Source:             model.SourceRegistration,
+		LastHeartbeatEpoch: now.UnixMilli(),
+		Registrant:         registration.Registrant,
+	}
+
+	var err error
+	created := false
+	sparkApp, _ = store.Upsert(r.store, registration.AppID, func(existing *model.SparkAppInstance) *model.SparkAppInstance {
+		merged := *sparkApp
+		switch {
+		case existing != nil && existing.Source == model.SourceRegistration && !isRegistrant(existing, registration.Registrant):
+			err = ErrRegistrationForbidden
+			return nil
+		case existing != nil && existing.Source == model.SourceRegistration:
+			merged.InheritState(existing)
+			merged.StartTimeEpoch = existing.StartTimeEpoch
+			return &merged
+		case existing != nil && !existing.IsCompleted():
+			err = fmt.Errorf("%w from the source %s", ErrRegistrationConflict, existing.Source)
+			return nil
+		}
+		created = true
+		merged.TransitionTo(model.AppRunning, now)
+		return &merged
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	if created {
+		log.Info("The application '%s' was registered at %s", sparkApp.AppID, sparkApp.BaseURL)
+	}
+	if sparkApp.UI == nil {
+		resolveSparkUISettingsAsync(r.store, sparkApp.AppID)
+	}
+	return sparkApp, created, nil
+}
+
+// Unregister removes a registered application and reports whether it was registered.
+// A registered application can only be unregistered with the token which registered it.
+func (r *Registrations) Unregister(appID string, registrant string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	sparkApp, found := r.store.Get(appID)
+	if !found || sparkApp.Source != model.SourceRegistration {
+		return false, nil
+	}
+	if !isRegistrant(sparkApp, registrant) {
+		return true, ErrRegistrationForbidden
+	}
+	r.store.Delete(appID)
+	log.Info("The application '%s' was unregistered", appID)
+	return true, nil
+}
+
+// isRegistrant reports whether the token fingerprint is the one which registered the
+// application. The applications registered without fingerprint are not bound to a token.
+func isRegistrant(sparkApp *model.SparkAppInstance, registrant string) bool {
+	return sparkApp.Registrant == "" || sparkApp.Registrant == registrant
+}
+
+// ExpiresAt returns the time at which a registered application expires.
+func (r *Registrations) ExpiresAt(sparkApp *model.SparkAppInstance) time.Time {
+	return time.UnixMilli(sparkApp.LastHeartbeatEpoch).Add(r.heartbeatTimeout)
+}
+
+// Run expires the registered applications until the context is done.
+func (r *Registrations) Run(ctx context.Context) {
+	log.Info("Expiring the registered applications after %s without heartbeat", r.heartbeatTimeout)
+	ticker := time.NewTicker(r.heartbeatTimeout / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.Expire()
+		}
+	}
+}
+
+// Expire removes the registered applications whose heartbeat timed out.
+func (r *Registrations) Expire() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	for _, sparkApp := range r.store.List(store.Filter{Source: model.SourceRegistration}) {
+		if now.After(r.ExpiresAt(sparkApp)) {
+			r.store.Delete(sparkApp.AppID)
+			log.Info("The registration of the application '%s' expired (last heartbeat: %s)",
+				sparkApp.AppID, time.UnixMilli(sparkApp.LastHeartbeatEpoch).Format(time.RFC3339))
+		}
+	}
+}
+
+// validateRegistration checks the application ID and the Spark UI URL of a registration.
+func (r *Registrations) validateRegistration(registration Registration) error {
+	if registration.AppID == "" {
+		return fmt.Errorf("%w: the appId is required", ErrInvalidRegistration)
+	}
+	uiURL, err := url.Parse(registration.UIURL)
+	if err != nil || (uiURL.Scheme != "http" && uiURL.Scheme != "https") || uiURL.Host == "" {
+		return fmt.Errorf("%w: the uiUrl '%s' is not a valid http(s) URL", ErrInvalidRegistration, registration.UIURL)
+	}
+	if _, err := r.allowedHosts.resolve(context.Background(), uiURL.Hostname()); err != nil {
+		return fmt.Errorf("%w: the host of the uiUrl '%s' is not allowed: %v", ErrInvalidRegistration, registration.UIURL, err)
+	}
+	return nil
+}
+
+// registrationTransport is the round tripper reaching the Spark UIs of the
+// registered applications. It only dials the allowed hosts of the registrations,
+// and no host until the registrations are created.
+var registrationTransport atomic.Pointer[http.Transport]
+
+func init() {
+	registrationTransport.Store(newHostAllowlist(nil).transport())
+}
+
+// SparkUITransport returns the round tripper used to reach the Spark UI of an
+// application: the transport of its cluster, or for the registered applications
+// a transport checking the resolved addresses of the allowed hosts when dialing.
+func SparkUITransport(sparkApp *model.SparkAppInstance) http.RoundTripper {
+	if sparkApp.Source == model.SourceRegistration {
+		return registrationTransport.Load()
+	}
+	return kubeclient.GetTransport(sparkApp.Cluster)
+}
+
+// hostAllowlist matches the hosts of the Spark UI URLs of the registrations
+// against host names, wildcard domains, IP addresses and CIDRs.
+type hostAllowlist struct {
+	hosts    []string
+	ips      []net.IP
+	networks []*net.IPNet
+	lookup   func(ctx context.Context, host string) ([]net.IPAddr, error)
+}
+
+// newHostAllowlist creates the hostAllowlist of the given allowed hosts.
+// The invalid CIDRs are ignored, as they are rejected by the configuration validation.
+func newHostAllowlist(allowedHosts []string) *hostAllowlist {
+	allowlist := &hostAllowlist{lookup: net.DefaultResolver.LookupIPAddr}
+	for _, host := range allowedHosts {
+		if ip := net.ParseIP(host); ip != nil {
+			allowlist.ips = append(allowlist.ips, ip)
+			continue
+		}
+		if !strings.Contains(host, "/") {
+			allowlist.hosts = append(allowlist.hosts, strings.ToLower(host))
+			continue
+		}
+		if _, network, err := net.ParseCIDR(host); err == nil {
+			allowlist.networks = append(allowlist.networks, network)
+		}
+	}
+	return allowlist
+}
+
+// resolve resolves an allowed host to its addresses. The host name or all its
+// addresses must be allowed, and none of its addresses may be a loopback,
+// link-local (e.g. the cloud metadata endpoints) or unspecified address.
+// No host is allowed when the allowlist is empty.
+func (a *hostAllowlist) resolve(ctx context.Context, host string) ([]net.IP, error) {
+	host = strings.ToLower(host)
+	if len(a.hosts) == 0 && len(a.ips) == 0 && len(a.networks) == 0 {
+		return nil, fmt.Errorf("no host is allowed")
+	}
+
+	var ips []net.IP
+	if ip := net.ParseIP(host); ip != nil {
+		ips = append(ips, ip)
+	} else {
+		addrs, err := a.lookup(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		for _, addr := range addrs {
+			ips = append(ips, addr.IP)
+		}
+	}
+	if len(ips) == 0 {
+		return nil, fmt.Errorf("the host %s has no address", host)
+	}
+
+	allowedHost := a.allowsHost(host)
+	for _, ip := range ips {
+		if ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified() {
+			return nil, fmt.Errorf("the host %s resolves to the forbidden address %s", host, ip)
+		}
+		if !allowedHost && !a.allowsIP(ip) {
+			return nil, fmt.Errorf("the address %s of the host %s is not allowed", ip, host)
+		}
+	}
+	return ips, nil
+}
+
+// allowsHost reports whether a host name matches an allowed host or wildcard domain.
+func (a *hostAllowlist) allowsHost(host string) bool {
+	for _, allowed := range a.hosts {
+		if domain, wildcard := strings.CutPrefix(allowed, "*."); wildcard {
+			if strings.HasSuffix(host, "."+domain) {
+				return true
+			}
+			continue
+		}
+		if allowed == host {
+			return true
+		}
+	}
+	return false
+}
+
+// allowsIP reports whether an address matches an allowed address or CIDR.
+func (a *hostAllowlist) allowsIP(ip net.IP) bool {
+	for _, allowed := range a.ips {
+		if allowed.Equal(ip) {
+			return true
+		}
+	}
+	for _, network := range a.networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// dialContext dials an allowed host. It connects to the addresses checked when
+// resolving the host, so that a host cannot resolve to another address between
+// the check and the connection (DNS rebinding).
+func (a *hostAllowlist) dialContext(ctx context.Context, network string, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	ips, err := a.resolve(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	for _, ip := range ips {
+		conn, dialErr := dialer.DialContext(ctx, network, net.JoinHostPort(ip.String(), port))
+		if dialErr == nil {
+			return conn, nil
+		}
+		err = dialErr
+	}
+	return nil, err
+}
+
+// transport returns a transport only dialing the allowed hosts. The HTTP proxies
+// of the environment are not used, as the proxy would dial the hosts unchecked.
+func (a *hostAllowlist) transport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = a.dialContext
+	return transport
+}
@@ -0,0 +1,231 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/okdp/spark-web-proxy/internal/config"
+	log "github.com/okdp/spark-web-proxy/internal/logging"
+	"github.com/okdp/spark-web-proxy/internal/model"
+	"github.com/okdp/spark-web-proxy/internal/store"
+)
+
+func Test_Registrations(t *testing.T) {
+	// Given
+	log.SetupGlobalLogger(config.Logging{Level: "info"})
+	now := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	sparkApps := store.NewMemoryStore()
+	sparkApps.Put(&model.SparkAppInstance{AppID: "spark-2", Status: string(model.AppRunning), Source: model.SourcePod})
+	registrations := NewRegistrations(config.Registration{HeartbeatTimeout: time.Minute, AllowedHosts: []string{"10.0.0.0/8"}}, sparkApps)
+	registrations.now = func() time.Time { return now }
+	registration := Registration{
+		AppID:     "spark-1",
+		UIURL:     "http://10.0.0.1:4040/",
+		Namespace: "batch",
+		Owner:     "alice",
+		Labels:    map[string]string{"team": "data"},
+	}
+
+	// When
+	sparkApp, created, err := registrations.Register(registration)
+
+	// Then: the application is registered
+	assert.NoError(t, err)
+	assert.True(t, created, "created")
+	assert.Equal(t, "http://10.0.0.1:4040", sparkApp.BaseURL, "BaseURL")
+	assert.Equal(t, "alice", sparkApp.User, "User")
+	assert.Equal(t, map[string]string{"team": "data"}, sparkApp.Labels, "Labels")
+	assert.Equal(t, string(model.AppRunning), sparkApp.Status, "Status")
+	assert.Equal(t, now.Add(time.Minute).UnixMilli(), registrations.ExpiresAt(sparkApp).UnixMilli(), "ExpiresAt")
+
+	// When: the application is registered again before it expires
+	now = now.Add(50 * time.Second)
+	_, created, err = registrations.Register(registration)
+	now = now.Add(50 * time.Second)
+	registrations.Expire()
+
+	// Then: the heartbeat keeps the application
+	assert.NoError(t, err)
+	assert.False(t, created, "created")
+	stored, found := sparkApps.Get("spark-1")
+	assert.True(t, found, "spark-1 kept")
+	assert.Len(t, stored.Transitions, 1, "Transitions")
+
+	// When: the heartbeat times out
+	now = now.Add(time.Minute)
+	registrations.Expire()
+
+	// Then
+	_, found = sparkApps.Get("spark-1")
+	assert.False(t, found, "spark-1 expired")
+
+	// When: a tracked application or an invalid application is registered
+	_, _, conflict := registrations.Register(Registration{AppID: "spark-2", UIURL: "http://10.0.0.2:4040"})
+	_, _, invalid := registrations.Register(Registration{AppID: "spark-3", UIURL: "10.0.0.3:4040"})
+
+	// Then
+	assert.ErrorIs(t, conflict, ErrRegistrationConflict)
+	assert.ErrorIs(t, invalid, ErrInvalidRegistration)
+
+	// When: the application is unregistered
+	_, _, _ = registrations.Register(registration)
+
+	// Then
+	unregistered, err := registrations.Unregister("spark-1", "")
+	assert.NoError(t, err)
+	assert.True(t, unregistered, "spark-1 unregistered")
+	unregistered, err = registrations.Unregister("spark-2", "")
+	assert.NoError(t, err)
+	assert.False(t, unregistered, "spark-2 is not registered")
+	_, found = sparkApps.Get("spark-2")
+	assert.True(t, found, "spark-2 kept")
+}
+
+func Test_Registrations_Registrant(t *testing.T) {
+	// Given: an application registered with a token
+	log.SetupGlobalLogger(config.Logging{Level: "info"})
+	sparkApps := store.NewMemoryStore()
+	registrations := NewRegistrations(config.Registration{AllowedHosts: []string{"10.0.0.0/8"}}, sparkApps)
+	registration := Registration{AppID: "spark-1", UIURL: "http://10.0.0.1:4040", Registrant: "launcher-a"}
+	_, _, err := registrations.Register(registration)
+	assert.NoError(t, err)
+
+	// When: another token registers or unregisters the application
+	_, _, overwritten := registrations.Register(Registration{AppID: "spark-1", UIURL: "http://10.0.0.9:4040", Registrant: "launcher-b"})
+	registered, unregistered := registrations.Unregister("spark-1", "launcher-b")
+
+	// Then
+	assert.ErrorIs(t, overwritten, ErrRegistrationForbidden)
+	assert.True(t, registered, "registered")
+	assert.ErrorIs(t, unregistered, ErrRegistrationForbidden)
+	sparkApp, found := sparkApps.Get("spark-1")
+	assert.True(t, found, "spark-1 kept")
+	assert.Equal(t, "http://10.0.0.1:4040", sparkApp.BaseURL, "BaseURL")
+	assert.Equal(t, "launcher-a", sparkApp.Registrant, "Registrant")
+
+	// When: the token which registered the application sends a heartbeat and unregisters it
+	_, created, heartbeat := registrations.Register(registration)
+	registered, unregistered = registrations.Unregister("spark-1", "launcher-a")
+
+	// Then
+	assert.NoError(t, heartbeat)
+	assert.False(t, created, "created")
+	assert.True(t, registered, "registered")
+	assert.NoError(t, unregistered)
+	_, found = sparkApps.Get("spark-1")
+	assert.False(t, found, "spark-1 unregistered")
+}
+
+// lookupHosts resolves the host names of the tests.
+func lookupHosts(hosts map[string]string) func(ctx context.Context, host string) ([]net.IPAddr, error) {
+	return func(_ context.Context, host string) ([]net.IPAddr, error) {
+		address, found := hosts[host]
+		if !found {
+			return nil, fmt.Errorf("no such host %s", host)
+		}
+		return []net.IPAddr{{IP: net.ParseIP(address)}}, nil
+	}
+}
+
+func Test_Registrations_Allowed_Hosts(t *testing.T) {
+	log.SetupGlobalLogger(config.Logging{Level: "info"})
+	hosts := map[string]string{
+		"localhost":              "127.0.0.1",
+		"spark.example.com":      "192.168.0.1",
+		"vm-1.vms.example.com":   "192.168.0.2",
+		"vms.example.com":        "192.168.0.3",
+		"metadata.example.com":   "169.254.169.254",
+		"spark.internal":         "10.0.3.4",
+		"kubernetes.default.svc": "172.20.0.1",
+	}
+	tests := []struct {
+		name         string
+		allowedHosts []string
+		uiURL        string
+		allowed      bool
+	}{
+		{"empty allowlist", nil, "http://10.0.0.1:4040", false},
+		{"metadata endpoint", []string{"0.0.0.0/0"}, "http://169.254.169.254/latest/meta-data", false},
+		{"loopback address", []string{"0.0.0.0/0"}, "http://127.0.0.1:4040", false},
+		{"localhost", []string{"0.0.0.0/0"}, "http://LOCALHOST:4040", false},
+		{"IPv6 loopback address", []string{"::/0"}, "http://[::1]:4040", false},
+		{"unspecified address", []string{"0.0.0.0/0"}, "http://0.0.0.0:4040", false},
+		{"address in an allowed CIDR", []string{"10.0.0.0/16"}, "http://10.0.3.4:4040", true},
+		{"address out of the allowed CIDRs", []string{"10.0.0.0/16"}, "http://10.1.3.4:4040", false},
+		{"host resolved in an allowed CIDR", []string{"10.0.0.0/16"}, "http://spark.internal:4040", true},
+		{"allowed address", []string{"10.0.0.1"}, "http://10.0.0.1:4040", true},
+		{"allowed host", []string{"spark.example.com"}, "https://Spark.example.com", true},
+		{"host of an allowed domain", []string{"*.vms.example.com"}, "http://vm-1.vms.example.com:4040", true},
+		{"allowed domain", []string{"*.vms.example.com"}, "http://vms.example.com:4040", false},
+		{"allowed host resolved to the metadata endpoint", []string{"*.example.com"}, "http://metadata.example.com", false},
+		{"unresolved host", []string{"*.example.com"}, "http://unknown.example.com", false},
+		{"host out of the allowed hosts", []string{"spark.example.com", "10.0.0.0/8"}, "http://kubernetes.default.svc", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Given
+			registrations := NewRegistrations(config.Registration{AllowedHosts: test.allowedHosts}, store.NewMemoryStore())
+			registrations.allowedHosts.lookup = lookupHosts(hosts)
+
+			// When
+			err := registrations.validateRegistration(Registration{AppID: "spark-1", UIURL: test.uiURL})
+
+			// Then
+			assert.Equal(t, test.allowed, err == nil, "allowed: %v", err)
+		})
+	}
+}
+
+func Test_SparkUITransport(t *testing.T) {
+	// Given: a registered application whose host resolves to an allowed address
+	log.SetupGlobalLogger(config.Logging{Level: "info"})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+
+	hosts := map[string]string{"spark.example.com": "192.168.0.1"}
+	registrations := NewRegistrations(config.Registration{AllowedHosts: []string{"spark.example.com"}}, store.NewMemoryStore())
+	registrations.allowedHosts.lookup = lookupHosts(hosts)
+	uiURL := "http://spark.example.com:" + port
+	assert.NoError(t, registrations.validateRegistration(Registration{AppID: "spark-1", UIURL: uiURL}))
+
+	// When: the host resolves to a loopback address when the Spark UI is dialed
+	hosts["spark.example.com"] = "127.0.0.1"
+	client := &http.Client{Transport: SparkUITransport(&model.SparkAppInstance{AppID: "spark-1", Source: model.SourceRegistration})}
+	_, err := client.Get(uiURL)
+
+	// Then
+	assert.ErrorContains(t, err, "forbidden address 127.0.0.1", "dial")
+
+	// When: the Spark UI of a discovered application is reached
+	transport := SparkUITransport(&model.SparkAppInstance{AppID: "spark-2", Source: model.SourcePod, Cluster: "unknown"})
+
+	// Then: the transport of its cluster is used
+	assert.Nil(t, transport, "default transport")
+}
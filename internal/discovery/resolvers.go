@@ -21,36 +21,182 @@ package discovery
 import (
 	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 
+	"github.com/okdp/spark-web-proxy/internal/constants"
+	kubeclient "github.com/okdp/spark-web-proxy/internal/discovery/resolvers/k8s/client"
 	sparkclient "github.com/okdp/spark-web-proxy/internal/discovery/resolvers/rest"
 	log "github.com/okdp/spark-web-proxy/internal/logging"
 	"github.com/okdp/spark-web-proxy/internal/model"
+	"github.com/okdp/spark-web-proxy/internal/store"
 	"github.com/okdp/spark-web-proxy/internal/utils"
 )
 
+// SparkAppResolver resolves the Spark application instances from the Kubernetes
+// driver pods, the Spark Operator custom resources or the Spark History Server,
+// and registers them in the application store.
+type SparkAppResolver struct {
+	store store.Store
+}
+
+// NewSparkAppResolver creates a SparkAppResolver registering the applications in the given store.
+func NewSparkAppResolver(sparkApps store.Store) *SparkAppResolver {
+	return &SparkAppResolver{store: sparkApps}
+}
+
 // ResolveSparkAppFromPod resolves a Spark application instance from a Kubernetes
-// driver pod and registers it in the application model.
-func ResolveSparkAppFromPod(pod *corev1.Pod) (*model.SparkAppInstance, error) {
-	sparkUIURL := fmt.Sprintf("http://%s:%d", pod.Status.PodIP, utils.GetSparkUIPort(pod))
+// driver pod running on the given cluster and registers it in the application store.
+//
+// When the driver pod does not provide the Spark application ID (client mode or
+// custom launchers), the application is stored under a placeholder key until the
+// real ID is resolved asynchronously from the driver UI.
+func (r SparkAppResolver) ResolveSparkAppFromPod(cluster string, pod *corev1.Pod) (*model.SparkAppInstance, error) {
+	appID := utils.GetSparkAppID(pod)
+	if appID == constants.UnknownSparkAppID {
+		appID = r.pendingSparkAppID(cluster, pod)
+	}
+
 	sparkApp := &model.SparkAppInstance{
-		BaseURL:        sparkUIURL,
+		BaseURL:        sparkUIBaseURL(cluster, pod, utils.GetSparkUIPort(pod)),
 		PodName:        pod.Name,
-		AppID:          utils.GetSparkAppID(pod),
+		AppID:          appID,
+		AppName:        utils.GetSparkAppName(pod),
 		Namespace:      pod.Namespace,
-		Status:         string(pod.Status.Phase),
 		StartTimeEpoch: podStartTimeEpoch(pod),
+		Cluster:        cluster,
+		PodPhase:       string(pod.Status.Phase),
+		PodUID:         string(pod.UID),
+		User:           utils.GetSparkUser(pod),
+		Source:         model.SourcePod,
+		UI:             sparkUISettingsFromPod(pod),
 	}
 
-	model.AddOrUpdateSparkApp(sparkApp)
+	sparkApp.DriverState, sparkApp.DriverReady = driverContainerState(pod)
+	if status := driverContainerStatus(pod); status != nil {
+		sparkApp.DriverRestarts = status.RestartCount
+	}
+
+	sparkApp, _ = store.Upsert(r.store, appID, func(existing *model.SparkAppInstance) *model.SparkAppInstance {
+		merged := *sparkApp
+		if existing != nil {
+			merged.InheritState(existing)
+			merged.MergeOperatorState(existing)
+			// A status set by the proxy (e.g. lost) is kept until the driver pod changes
+			if merged.StatusOverride && !merged.PodStateChanged(existing) {
+				return &merged
+			}
+		}
+		merged.TransitionTo(podSparkAppStatus(pod), time.Now())
+		return &merged
+	})
+
+	switch {
+	case appID == model.SparkPodAppKey(cluster, pod.Namespace, pod.Name) && pod.Status.Phase == corev1.PodRunning:
+		r.resolveSparkAppIDAsync(cluster, pod, sparkApp.BaseURL)
+	case sparkApp.Status == string(model.AppStarting) || sparkApp.Status == string(model.AppUIReady):
+		r.probeSparkUIAsync(cluster, sparkApp.AppID, sparkApp.BaseURL)
+	}
 
 	return sparkApp, nil
 }
 
+// ResolveSparkAppFromSparkApplication resolves a Spark application instance from a
+// Spark Operator SparkApplication custom resource and registers it in the application store.
+//
+// The SparkApplication is stored under a temporary key until the operator reports the
+// Spark application ID, so that it is tracked even before the driver pod exists.
+// Fields discovered from the driver pod are kept, while the operator state
+// takes precedence once it is final.
+func (r SparkAppResolver) ResolveSparkAppFromSparkApplication(cluster string, sparkApplication *unstructured.Unstructured) (*model.SparkAppInstance, error) {
+	appID, _, err := unstructured.NestedString(sparkApplication.Object, "status", "sparkApplicationId")
+	if err != nil {
+		return nil, err
+	}
+	state, _, _ := unstructured.NestedString(sparkApplication.Object, "status", "applicationState", "state")
+	driverPodName, _, _ := unstructured.NestedString(sparkApplication.Object, "status", "driverInfo", "podName")
+	uiServiceName, _, _ := unstructured.NestedString(sparkApplication.Object, "status", "driverInfo", "webUIServiceName")
+	uiAddress, _, _ := unstructured.NestedString(sparkApplication.Object, "status", "driverInfo", "webUIAddress")
+	uiPort, _, _ := unstructured.NestedInt64(sparkApplication.Object, "status", "driverInfo", "webUIPort")
+
+	operatorState := model.SparkOperatorAppState(state)
+	pendingKey := model.SparkOperatorAppKey(cluster, sparkApplication.GetNamespace(), sparkApplication.GetName())
+	sparkApp := &model.SparkAppInstance{
+		PodName:          driverPodName,
+		AppID:            appID,
+		Namespace:        sparkApplication.GetNamespace(),
+		StartTimeEpoch:   -1,
+		Cluster:          cluster,
+		CRName:           sparkApplication.GetName(),
+		ScheduledCRName:  scheduledSparkApplicationName(sparkApplication),
+		ApplicationState: state,
+		UIServiceName:    uiServiceName,
+		Source:           model.SourceOperator,
+	}
+	if uiAddress != "" {
+		sparkApp.BaseURL = fmt.Sprintf("http://%s", uiAddress)
+		if kubeCluster, found := kubeclient.GetCluster(cluster); found {
+			sparkApp.BaseURL = kubeCluster.SparkUIServiceBaseURL(sparkApp.Namespace, uiServiceName, uiAddress, int32(uiPort))
+		}
+	}
+
+	if appID == "" {
+		sparkApp.AppID = pendingKey
+		sparkApp, _ = store.Upsert(r.store, pendingKey, func(existing *model.SparkAppInstance) *model.SparkAppInstance {
+			merged := *sparkApp
+			if existing != nil {
+				merged.InheritState(existing)
+			}
+			merged.TransitionTo(operatorState.Status(), time.Now())
+			return &merged
+		})
+		return sparkApp, nil
+	}
+
+	pending, hasPending := r.store.Delete(pendingKey)
+	sparkApp, _ = store.Upsert(r.store, appID, func(existing *model.SparkAppInstance) *model.SparkAppInstance {
+		merged := *sparkApp
+		switch {
+		case existing != nil && existing.PodPhase != "":
+			merged = *existing
+			merged.MergeOperatorState(sparkApp)
+			return &merged
+		case existing != nil:
+			merged.InheritState(existing)
+			// A status set by the proxy (e.g. lost) is kept until the operator state changes
+			if merged.StatusOverride && merged.ApplicationState == existing.ApplicationState {
+				return &merged
+			}
+		case hasPending:
+			merged.InheritState(pending)
+		}
+		merged.TransitionTo(operatorState.Status(), time.Now())
+		return &merged
+	})
+
+	if sparkApp.BaseURL != "" && (sparkApp.Status == string(model.AppStarting) || sparkApp.Status == string(model.AppUIReady)) {
+		r.probeSparkUIAsync(cluster, sparkApp.AppID, sparkApp.BaseURL)
+	}
+
+	return sparkApp, nil
+}
+
+// DeleteSparkAppFromSparkApplication removes the Spark application instance
+// registered for the given Spark Operator SparkApplication custom resource
+// running on the given cluster.
+func (r SparkAppResolver) DeleteSparkAppFromSparkApplication(cluster string, sparkApplication *unstructured.Unstructured) (*model.SparkAppInstance, bool) {
+	r.store.Delete(model.SparkOperatorAppKey(cluster, sparkApplication.GetNamespace(), sparkApplication.GetName()))
+
+	appID, _, _ := unstructured.NestedString(sparkApplication.Object, "status", "sparkApplicationId")
+	return r.store.Delete(appID)
+}
+
 // ResolveSparkAppFromHistory resolves a Spark application instance using the
 // Spark History Server REST API.
-func ResolveSparkAppFromHistory(request *http.Request, sparkHistoryBaseURL string, appID string) (*model.SparkAppInstance, error) {
+func (r SparkAppResolver) ResolveSparkAppFromHistory(request *http.Request, sparkHistoryBaseURL string, appID string) (*model.SparkAppInstance, error) {
 	sparkClient, err := sparkclient.NewSparkRestClient(request, sparkHistoryBaseURL)
 	if err != nil {
 		log.Error("Unable to create new spark history client: %+v", err)
@@ -70,27 +216,73 @@ func ResolveSparkAppFromHistory(request *http.Request, sparkHistoryBaseURL strin
 			Status: string(model.AppUnknown),
 		}, err
 	}
+	sparkApp := sparkAppFromHistory(appInfo, sparkAppEnv)
+
+	if appInfo.IsRunning() {
+		sparkApp.TransitionTo(model.AppRunning, time.Now())
+	} else {
+		sparkApp.TransitionTo(model.AppUnknown, time.Now())
+		store.Add(r.store, sparkApp)
+	}
+	return sparkApp, err
+}
+
+// sparkAppFromHistory builds a Spark application instance from its Spark History
+// application info and environment properties. The Spark UI base URL is resolved
+// from the driver host and Spark UI port properties.
+func sparkAppFromHistory(appInfo *model.SparkApp, sparkAppEnv *model.SparkAppEnvironment) *model.SparkAppInstance {
 	sparkDriverHost, _ := sparkAppEnv.GetProperty("spark.driver.host")
-	sparkDriverPort, _ := sparkAppEnv.GetProperty("spark.ui.port")
-	sparkAppID, _ := sparkAppEnv.GetProperty("spark.app.id")
+	sparkDriverPort, found := sparkAppEnv.GetProperty("spark.ui.port")
+	if !found || sparkDriverPort == "" {
+		sparkDriverPort = strconv.Itoa(constants.DefaultSparkUIPort)
+	}
+	sparkAppID, found := sparkAppEnv.GetProperty("spark.app.id")
+	if !found || sparkAppID == "" {
+		sparkAppID = appInfo.ID
+	}
 	sparkAppName, _ := sparkAppEnv.GetProperty("spark.app.name")
 	sparkAppNamespace, _ := sparkAppEnv.GetProperty("spark.kubernetes.namespace")
+	sparkUser := ""
+	var startTimeEpoch int64
+	if len(appInfo.Attempts) != 0 {
+		sparkUser = appInfo.Attempts[0].SparkUser
+		startTimeEpoch = appInfo.Attempts[0].StartTimeEpoch
+	}
 	sparkUIBaseURL := fmt.Sprintf("http://%s:%s", sparkDriverHost, sparkDriverPort)
 
-	sparkApp := &model.SparkAppInstance{
-		BaseURL:   sparkUIBaseURL,
-		PodName:   sparkAppName,
-		AppID:     sparkAppID,
-		Namespace: sparkAppNamespace,
-		Status:    string(model.AppUnknown),
+	return &model.SparkAppInstance{
+		BaseURL:        sparkUIBaseURL,
+		PodName:        sparkAppName,
+		AppID:          sparkAppID,
+		AppName:        sparkAppName,
+		Namespace:      sparkAppNamespace,
+		StartTimeEpoch: startTimeEpoch,
+		User:           sparkUser,
+		Source:         model.SourceHistory,
+		UI:             sparkUISettingsFromEnvironment(sparkAppEnv),
 	}
+}
 
-	if appInfo.IsRunning() {
-		sparkApp.Status = string(model.AppRunning)
-	} else {
-		model.AddOrUpdateSparkApp(sparkApp)
+// scheduledSparkApplicationName returns the name of the ScheduledSparkApplication
+// owning the given SparkApplication run, or an empty string if there is none.
+func scheduledSparkApplicationName(sparkApplication *unstructured.Unstructured) string {
+	for _, owner := range sparkApplication.GetOwnerReferences() {
+		if owner.Kind == "ScheduledSparkApplication" {
+			return owner.Name
+		}
 	}
-	return sparkApp, err
+	return ""
+}
+
+// sparkUIBaseURL returns the base URL of the Spark UI listening on the given
+// port of a driver pod, according to the upstream mode of the cluster.
+func sparkUIBaseURL(cluster string, pod *corev1.Pod, port int32) string {
+	scheme := utils.GetSparkUIScheme(pod)
+	baseURL := fmt.Sprintf("%s://%s:%d", scheme, pod.Status.PodIP, port)
+	if kubeCluster, found := kubeclient.GetCluster(cluster); found {
+		baseURL = kubeCluster.SparkUIPodBaseURL(pod, scheme, port)
+	}
+	return baseURL + utils.GetSparkUIBasePath(pod)
 }
 
 // podStartTimeEpoch returns the pod start time as a Unix epoch timestamp
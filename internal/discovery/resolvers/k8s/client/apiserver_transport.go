@@ -0,0 +1,192 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package kubeclient
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"k8s.io/client-go/rest"
+
+	log "github.com/okdp/spark-web-proxy/internal/logging"
+)
+
+// apiServerProxyPathRe matches the Kubernetes API server pods/proxy and services/proxy
+// subresource prefix of a request path (e.g. /api/v1/namespaces/spark/pods/driver:4040/proxy).
+var apiServerProxyPathRe = regexp.MustCompile(`^(.*/api/v1/namespaces/[^/]+/(?:pods|services)/[^/]+/proxy)`)
+
+// maxRewrittenBodySize is the maximum size of the HTML bodies whose links are reverted.
+// The larger bodies are passed through as is.
+const maxRewrittenBodySize = 8 << 20
+
+// forwardedHeaders lists the request headers forwarded to the API server. The other
+// headers, like the user credentials, the cookies or the impersonation headers, are
+// meant for the Spark UI and must not reach the API server.
+var forwardedHeaders = []string{
+	"Accept",
+	"Accept-Language",
+	"Cache-Control",
+	"Content-Type",
+	"If-Modified-Since",
+	"If-None-Match",
+	"Range",
+	"User-Agent",
+	"X-Requested-With",
+}
+
+// apiServerProxyTransport tunnels the Spark driver UI traffic through the
+// Kubernetes API server proxy subresources.
+//
+// The API server rewrites the redirects and the HTML links of the proxied pages
+// so that they go through the proxy subresource again. As the Spark UIs are
+// served under the web proxy base path, the transport reverts this rewriting.
+type apiServerProxyTransport struct {
+	transport http.RoundTripper
+}
+
+// NewAPIServerProxyTransport returns a round tripper authenticated against the
+// Kubernetes API server of the given REST configuration.
+func NewAPIServerProxyTransport(restConfig *rest.Config) (http.RoundTripper, error) {
+	transport, err := rest.TransportFor(restConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &apiServerProxyTransport{transport: transport}, nil
+}
+
+// PodProxyBaseURL returns the Spark UI base URL of a driver pod through the
+// Kubernetes API server pods/proxy subresource.
+func PodProxyBaseURL(restConfig *rest.Config, namespace string, podName string, scheme string, port int32) string {
+	name := fmt.Sprintf("%s:%d", podName, port)
+	if scheme == "https" {
+		name = fmt.Sprintf("%s:%s", scheme, name)
+	}
+	return fmt.Sprintf("%s/api/v1/namespaces/%s/pods/%s/proxy", strings.TrimSuffix(restConfig.Host, "/"), namespace, name)
+}
+
+// ServiceProxyBaseURL returns the Spark UI base URL of a driver UI service through
+// the Kubernetes API server services/proxy subresource.
+func ServiceProxyBaseURL(restConfig *rest.Config, namespace string, serviceName string, port int32) string {
+	return fmt.Sprintf("%s/api/v1/namespaces/%s/services/%s:%d/proxy", strings.TrimSuffix(restConfig.Host, "/"), namespace, serviceName, port)
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *apiServerProxyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	header := make(http.Header, len(forwardedHeaders)+1)
+	for _, name := range forwardedHeaders {
+		if values := req.Header.Values(name); len(values) > 0 {
+			header[name] = values
+		}
+	}
+	req = req.Clone(req.Context())
+	req.Header = header
+	// Let the API server rewrite a plain HTML body
+	req.Header.Set("Accept-Encoding", "identity")
+
+	resp, err := t.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := apiServerProxyPathRe.FindStringSubmatch(req.URL.Path)
+	if len(matches) < 2 {
+		return resp, nil
+	}
+	proxyPath := []byte(matches[1])
+
+	if location := resp.Header.Get("Location"); location != "" {
+		resp.Header.Set("Location", string(stripProxyPath([]byte(location), proxyPath)))
+	}
+
+	ct := strings.ToLower(resp.Header.Get("Content-Type"))
+	if !strings.Contains(ct, "text/html") || resp.Header.Get("Content-Encoding") != "" {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxRewrittenBodySize+1))
+	if err != nil {
+		_ = resp.Body.Close()
+		return nil, err
+	}
+	if len(body) > maxRewrittenBodySize {
+		log.Warn("The HTML body of %s exceeds %d bytes, its links are not rewritten", req.URL.Path, maxRewrittenBodySize)
+		resp.Body = &readCloser{Reader: io.MultiReader(bytes.NewReader(body), resp.Body), Closer: resp.Body}
+		return resp, nil
+	}
+	_ = resp.Body.Close()
+
+	body = stripProxyPath(body, proxyPath)
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	resp.ContentLength = int64(len(body))
+	resp.Header.Set("Content-Length", strconv.Itoa(len(body)))
+	return resp, nil
+}
+
+// readCloser reads the body of a response partially consumed and closes the original body.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// stripProxyPath removes every occurrence of the API server proxy path prefix from
+// the given content, together with the http(s)://host origin preceding it, if any.
+func stripProxyPath(content []byte, proxyPath []byte) []byte {
+	if !bytes.Contains(content, proxyPath) {
+		return content
+	}
+	stripped := make([]byte, 0, len(content))
+	for {
+		i := bytes.Index(content, proxyPath)
+		if i < 0 {
+			return append(stripped, content...)
+		}
+		stripped = append(stripped, content[:originStart(content[:i])]...)
+		content = content[i+len(proxyPath):]
+	}
+}
+
+// originStart returns the index of the http(s)://host origin ending the given
+// content, or the content length when it does not end with an origin.
+func originStart(content []byte) int {
+	host := len(content)
+	for host > 0 && !isURLDelimiter(content[host-1]) {
+		host--
+	}
+	if host == len(content) {
+		return len(content)
+	}
+	for _, scheme := range []string{"http://", "https://"} {
+		if bytes.HasSuffix(content[:host], []byte(scheme)) {
+			return host - len(scheme)
+		}
+	}
+	return len(content)
+}
+
+// isURLDelimiter reports whether the given byte cannot be part of a URL host.
+func isURLDelimiter(c byte) bool {
+	switch c {
+	case '/', '"', '\'', ' ', '\t', '\n', '\r', '\f', '\v':
+		return true
+	}
+	return false
+}
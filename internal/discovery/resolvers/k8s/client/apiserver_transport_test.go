@@ -0,0 +1,136 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package kubeclient
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/rest"
+
+	"github.com/okdp/spark-web-proxy/internal/config"
+	log "github.com/okdp/spark-web-proxy/internal/logging"
+)
+
+func Test_APIServerProxyTransport(t *testing.T) {
+	// Given: an API server rewriting the proxied links
+	log.SetupGlobalLogger(config.Logging{Level: "info"})
+	prefix := "/api/v1/namespaces/spark/pods/spark-pi-driver:4040/proxy"
+	var forwarded http.Header
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		forwarded = r.Header.Clone()
+		switch r.URL.Path {
+		case prefix + "/sparkui/spark-123":
+			w.Header().Set("Location", "http://"+r.Host+prefix+"/sparkui/spark-123/jobs/")
+			w.WriteHeader(http.StatusFound)
+		case prefix + "/sparkui/spark-123/jobs/":
+			w.Header().Set("Content-Type", "text/html;charset=utf-8")
+			_, _ = io.WriteString(w, `<a href="`+prefix+`/sparkui/spark-123/stages/">Stages</a>`+
+				`<script src="http://`+r.Host+prefix+`/sparkui/spark-123/static/utils.js"></script>`)
+		case prefix + "/sparkui/spark-123/large/":
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = io.WriteString(w, prefix+strings.Repeat("a", maxRewrittenBodySize))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer apiServer.Close()
+
+	restConfig := &rest.Config{Host: apiServer.URL, BearerToken: "service-account-token"}
+	transport, err := NewAPIServerProxyTransport(restConfig)
+	assert.NoError(t, err)
+	client := &http.Client{
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	baseURL := PodProxyBaseURL(restConfig, "spark", "spark-pi-driver", "http", 4040)
+
+	// When
+	req, _ := http.NewRequest(http.MethodGet, baseURL+"/sparkui/spark-123", nil)
+	req.Header.Set("Authorization", "Bearer user-token")
+	req.Header.Set("Cookie", "session=user-session")
+	req.Header.Set("Impersonate-User", "admin")
+	req.Header.Set("Accept-Language", "en")
+	resp, err := client.Do(req)
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, "Bearer service-account-token", forwarded.Get("Authorization"), "Authorization")
+	assert.Empty(t, forwarded.Get("Cookie"), "Cookie")
+	assert.Empty(t, forwarded.Get("Impersonate-User"), "Impersonate-User")
+	assert.Equal(t, "en", forwarded.Get("Accept-Language"), "Accept-Language")
+	assert.Equal(t, http.StatusFound, resp.StatusCode, "StatusCode")
+	assert.Equal(t, "/sparkui/spark-123/jobs/", resp.Header.Get("Location"), "Location")
+
+	// When
+	resp, err = client.Get(baseURL + "/sparkui/spark-123/jobs/")
+	// Then
+	assert.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, `<a href="/sparkui/spark-123/stages/">Stages</a>`+
+		`<script src="/sparkui/spark-123/static/utils.js"></script>`, string(body), "Body")
+
+	// When: the HTML body exceeds the rewriting limit
+	resp, err = client.Get(baseURL + "/sparkui/spark-123/large/")
+	// Then: the body is passed through as is
+	assert.NoError(t, err)
+	body, _ = io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	assert.Equal(t, len(prefix)+maxRewrittenBodySize, len(body), "Body size")
+	assert.True(t, strings.HasPrefix(string(body), prefix), "Body not rewritten")
+}
+
+func Test_StripProxyPath(t *testing.T) {
+	prefix := "/api/v1/namespaces/spark/pods/driver:4040/proxy"
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{
+			name:     "relative link",
+			content:  `href="` + prefix + `/jobs/"`,
+			expected: `href="/jobs/"`,
+		},
+		{
+			name:     "absolute links",
+			content:  `https://kube:6443` + prefix + `/jobs/ http://kube` + prefix + `/stages/`,
+			expected: `/jobs/ /stages/`,
+		},
+		{
+			name:     "unrelated origin scheme",
+			content:  `ftp://kube` + prefix + `/jobs/`,
+			expected: `ftp://kube/jobs/`,
+		},
+		{
+			name:     "no proxy path",
+			content:  `<a href="/jobs/">Jobs</a>`,
+			expected: `<a href="/jobs/">Jobs</a>`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, string(stripProxyPath([]byte(test.content), []byte(prefix))))
+		})
+	}
+}
@@ -0,0 +1,133 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package kubeclient
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"github.com/okdp/spark-web-proxy/internal/config"
+	"github.com/okdp/spark-web-proxy/internal/constants"
+	log "github.com/okdp/spark-web-proxy/internal/logging"
+)
+
+// Cluster holds the Kubernetes clients of a cluster where Spark jobs run,
+// and the HTTP transport used to reach the Spark driver UIs running on it.
+type Cluster struct {
+	Name          string
+	JobNamespaces []string
+	RestConfig    *rest.Config
+	Clientset     kubernetes.Interface
+	// Upstream defines how the driver UIs of the cluster are reached.
+	Upstream string
+	// Transport is the round tripper used to reach the driver UIs of the cluster.
+	// A nil transport uses http.DefaultTransport (pod IPs are routable).
+	Transport http.RoundTripper
+}
+
+// clusters holds the registered clusters, keyed by cluster name.
+var clusters sync.Map
+
+// NewCluster creates the Kubernetes clients of the given cluster configuration.
+func NewCluster(clusterConf config.Cluster) (*Cluster, error) {
+	restConfig, err := NewRestConfig(config.Kubernetes{
+		Kubeconfig: clusterConf.Kubeconfig,
+		Context:    clusterConf.Context,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	cluster := &Cluster{
+		Name:          clusterConf.Name,
+		JobNamespaces: clusterConf.JobNamespaces,
+		RestConfig:    restConfig,
+		Clientset:     clientset,
+		Upstream:      constants.UpstreamDirect,
+	}
+
+	switch clusterConf.Upstream {
+	case "", constants.UpstreamDirect:
+	case constants.UpstreamAPIServer:
+		cluster.Upstream = constants.UpstreamAPIServer
+		cluster.Transport, err = NewAPIServerProxyTransport(restConfig)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported spark ui upstream '%s', expected one of: %s, %s",
+			clusterConf.Upstream, constants.UpstreamDirect, constants.UpstreamAPIServer)
+	}
+
+	log.Info("Reaching the spark driver UIs of the cluster '%s' using the upstream: %s", cluster.Name, cluster.Upstream)
+	return cluster, nil
+}
+
+// SparkUIPodBaseURL returns the Spark UI base URL of the given driver pod,
+// according to the upstream mode of the cluster.
+func (c *Cluster) SparkUIPodBaseURL(pod *corev1.Pod, scheme string, port int32) string {
+	if c.Upstream == constants.UpstreamAPIServer {
+		return PodProxyBaseURL(c.RestConfig, pod.Namespace, pod.Name, scheme, port)
+	}
+	return fmt.Sprintf("%s://%s:%d", scheme, pod.Status.PodIP, port)
+}
+
+// SparkUIServiceBaseURL returns the Spark UI base URL of the given driver UI service,
+// according to the upstream mode of the cluster. The address is the service
+// cluster IP and port.
+func (c *Cluster) SparkUIServiceBaseURL(namespace string, serviceName string, address string, port int32) string {
+	if c.Upstream == constants.UpstreamAPIServer && serviceName != "" {
+		return ServiceProxyBaseURL(c.RestConfig, namespace, serviceName, port)
+	}
+	return fmt.Sprintf("http://%s", address)
+}
+
+// RegisterCluster registers the cluster so that the Spark driver UIs running
+// on it can be reached by name.
+func RegisterCluster(cluster *Cluster) {
+	clusters.Store(cluster.Name, cluster)
+}
+
+// GetCluster retrieves a registered cluster by name.
+func GetCluster(name string) (*Cluster, bool) {
+	value, exists := clusters.Load(name)
+	if exists {
+		return value.(*Cluster), exists
+	}
+	return nil, false
+}
+
+// GetTransport returns the round tripper used to reach the Spark driver UIs
+// running on the given cluster. It returns nil (http.DefaultTransport) when
+// the cluster is unknown, e.g. for applications resolved from Spark History.
+func GetTransport(name string) http.RoundTripper {
+	cluster, found := GetCluster(name)
+	if !found {
+		return nil
+	}
+	return cluster.Transport
+}
@@ -0,0 +1,77 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+// Package kubeclient provides helpers to build Kubernetes client configurations
+// for running the proxy in-cluster or outside of the cluster.
+package kubeclient
+
+import (
+	"os"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/okdp/spark-web-proxy/internal/config"
+	log "github.com/okdp/spark-web-proxy/internal/logging"
+)
+
+// NewRestConfig returns the Kubernetes REST configuration using the following
+// fallback order:
+//  1. The configured kubeconfig file (--kubeconfig flag or kubernetes.kubeconfig key)
+//  2. The kubeconfig files listed in the KUBECONFIG environment variable
+//  3. The in-cluster service account configuration
+//  4. The default kubeconfig file ($HOME/.kube/config)
+//
+// The configured context (--context flag or kubernetes.context key) is used when
+// loading from a kubeconfig file; otherwise the current context is used.
+func NewRestConfig(kubernetesConf config.Kubernetes) (*rest.Config, error) {
+	if kubernetesConf.Kubeconfig != "" {
+		log.Info("Loading Kubernetes config from kubeconfig file: %s (context: %s)", kubernetesConf.Kubeconfig, currentContext(kubernetesConf.Context))
+		return loadKubeconfig(&clientcmd.ClientConfigLoadingRules{ExplicitPath: kubernetesConf.Kubeconfig}, kubernetesConf.Context)
+	}
+
+	if os.Getenv(clientcmd.RecommendedConfigPathEnvVar) != "" {
+		log.Info("Loading Kubernetes config from %s environment variable (context: %s)", clientcmd.RecommendedConfigPathEnvVar, currentContext(kubernetesConf.Context))
+		return loadKubeconfig(clientcmd.NewDefaultClientConfigLoadingRules(), kubernetesConf.Context)
+	}
+
+	if kubernetesConf.Context == "" {
+		restConfig, err := rest.InClusterConfig()
+		if err == nil {
+			log.Info("Loading Kubernetes in-cluster config")
+			return restConfig, nil
+		}
+		log.Debug("Kubernetes in-cluster config is not available: %v", err)
+	}
+
+	log.Info("Loading Kubernetes config from default kubeconfig file: %s (context: %s)", clientcmd.RecommendedHomeFile, currentContext(kubernetesConf.Context))
+	return loadKubeconfig(clientcmd.NewDefaultClientConfigLoadingRules(), kubernetesConf.Context)
+}
+
+// loadKubeconfig builds the REST configuration from the kubeconfig files
+// matched by the loading rules, using the given context when set.
+func loadKubeconfig(loadingRules *clientcmd.ClientConfigLoadingRules, context string) (*rest.Config, error) {
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: context}
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, overrides).ClientConfig()
+}
+
+// currentContext returns a printable name for the given kubeconfig context.
+func currentContext(context string) string {
+	if context == "" {
+		return "current"
+	}
+	return context
+}
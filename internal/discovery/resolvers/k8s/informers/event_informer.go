@@ -0,0 +1,106 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package informers
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/okdp/spark-web-proxy/internal/config"
+	"github.com/okdp/spark-web-proxy/internal/discovery"
+	log "github.com/okdp/spark-web-proxy/internal/logging"
+	"github.com/okdp/spark-web-proxy/internal/store"
+)
+
+// podEventFieldSelector selects the events of the pods.
+const podEventFieldSelector = "involvedObject.kind=Pod"
+
+// EventInformer watches the Kubernetes events of the pods of the job namespaces
+// and records the events of the tracked driver and executor pods on their applications.
+type EventInformer struct {
+	cluster    string
+	namespaces []string
+	recorder   *discovery.PodEventRecorder
+}
+
+// NewEventInformer creates an EventInformer for the given cluster using the application
+// configuration. The events are recorded on the applications of the given store, and
+// correlated with the executor pods of the given executor pod store.
+func NewEventInformer(config *config.ApplicationConfig, cluster config.Cluster, sparkApps store.Store, executorPods *store.ExecutorPodStore) *EventInformer {
+	return &EventInformer{
+		cluster:    cluster.Name,
+		namespaces: cluster.JobNamespaces,
+		recorder:   discovery.NewPodEventRecorder(config.Spark.Discovery.Events, sparkApps, executorPods),
+	}
+}
+
+// WatchEvents starts watching the pod events in all configured namespaces,
+// running one informer per namespace.
+func (i EventInformer) WatchEvents(clientset kubernetes.Interface) {
+	watchNamespaces(i.namespaces, func(ctx context.Context, namespace string) {
+		i.WatchNamespace(ctx, clientset, namespace)
+	})
+}
+
+// WatchNamespace starts the pod event informer of a single namespace and blocks
+// until the context is done.
+func (i EventInformer) WatchNamespace(ctx context.Context, clientset kubernetes.Interface, namespace string) {
+	if err := i.run(ctx, clientset, namespace); err != nil {
+		log.Error("Failed to add event handler: %+v", err)
+		return
+	}
+
+	log.Info("Event informer on the cluster '%s' (namespace: %s) successfully stopped.", i.cluster, namespaceName(namespace))
+}
+
+// run starts the pod event informer for the given namespace and blocks until
+// the context is done.
+func (i EventInformer) run(ctx context.Context, clientset kubernetes.Interface, namespace string) error {
+	log.Info("Running event informer on the cluster '%s' and the following namespaces: %s", i.cluster, namespaceName(namespace))
+
+	factory := informers.NewSharedInformerFactoryWithOptions(clientset, 5*time.Minute,
+		informers.WithNamespace(namespace),
+		informers.WithTweakListOptions(func(opts *metav1.ListOptions) {
+			opts.FieldSelector = podEventFieldSelector
+		}),
+	)
+
+	return runInformer(ctx, factory, factory.Core().V1().Events().Informer(), cache.ResourceEventHandlerFuncs{
+		AddFunc: i.eventAddedOrUpdated,
+		UpdateFunc: func(_, newObj interface{}) {
+			i.eventAddedOrUpdated(newObj)
+		},
+	})
+}
+
+func (i EventInformer) eventAddedOrUpdated(obj interface{}) {
+	event, ok := obj.(*corev1.Event)
+	if !ok {
+		return
+	}
+
+	for _, sparkApp := range i.recorder.Record(i.cluster, event) {
+		log.Debug("The application '%s' (%s:%s/%s) received the event %s %s: %s", sparkApp.AppID, i.cluster, event.InvolvedObject.Namespace,
+			event.InvolvedObject.Name, event.Type, event.Reason, event.Message)
+	}
+}
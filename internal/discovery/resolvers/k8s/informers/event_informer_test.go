@@ -0,0 +1,80 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package informers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/okdp/spark-web-proxy/internal/config"
+	log "github.com/okdp/spark-web-proxy/internal/logging"
+	"github.com/okdp/spark-web-proxy/internal/model"
+	"github.com/okdp/spark-web-proxy/internal/store"
+)
+
+func newPodEvent(name string, podName string, reason string, count int32) *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: "spark"},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "spark", Name: podName},
+		Type:           corev1.EventTypeWarning,
+		Reason:         reason,
+		Message:        "0/3 nodes are available: 3 Insufficient memory.",
+		Count:          count,
+		LastTimestamp:  metav1.Now(),
+	}
+}
+
+func Test_EventInformer_Lifecycle(t *testing.T) {
+	// Given: a pending driver pod which cannot be scheduled
+	log.SetupGlobalLogger(config.Logging{Level: "info"})
+	clientset := fake.NewSimpleClientset(newPodEvent("spark-pi-driver.1", "spark-pi-driver", "FailedScheduling", 1))
+
+	sparkApps := store.NewMemoryStore()
+	sparkApp := &model.SparkAppInstance{AppID: "spark-123", Cluster: "default", Namespace: "spark", PodName: "spark-pi-driver"}
+	sparkApp.TransitionTo(model.AppPending, time.Now())
+	sparkApps.Put(sparkApp)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	informer := NewEventInformer(&config.ApplicationConfig{}, config.Cluster{Name: "default"}, sparkApps, store.NewExecutorPodStore(10))
+	go func() { _ = informer.run(ctx, clientset, "spark") }()
+
+	// Then
+	assert.Eventually(t, func() bool {
+		app, _ := sparkApps.Get("spark-123")
+		return len(app.Events) == 1
+	}, 5*time.Second, 10*time.Millisecond, "recorded event")
+
+	// When: the event occurs again
+	_, err := clientset.CoreV1().Events("spark").Update(ctx, newPodEvent("spark-pi-driver.1", "spark-pi-driver", "FailedScheduling", 4), metav1.UpdateOptions{})
+	assert.NoError(t, err)
+
+	// Then
+	assert.Eventually(t, func() bool {
+		app, _ := sparkApps.Get("spark-123")
+		return len(app.Events) == 1 && app.Events[0].Count == 4
+	}, 5*time.Second, 10*time.Millisecond, "updated event")
+	app, _ := sparkApps.Get("spark-123")
+	assert.Equal(t, "FailedScheduling", app.Events[0].Reason, "Reason")
+	assert.Equal(t, model.EventTypeWarning, app.Events[0].Type, "Type")
+}
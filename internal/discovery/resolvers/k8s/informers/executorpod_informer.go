@@ -0,0 +1,144 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package informers
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/okdp/spark-web-proxy/internal/config"
+	"github.com/okdp/spark-web-proxy/internal/constants"
+	"github.com/okdp/spark-web-proxy/internal/discovery"
+	log "github.com/okdp/spark-web-proxy/internal/logging"
+	"github.com/okdp/spark-web-proxy/internal/store"
+)
+
+// ExecutorPodInformer watches Kubernetes namespaces for Spark executor pods and
+// tracks their state (phase, restarts, termination) by application and executor ID.
+type ExecutorPodInformer struct {
+	cluster       string
+	namespaces    []string
+	labelSelector string
+	executorPods  *store.ExecutorPodStore
+}
+
+// NewExecutorPodInformer creates an ExecutorPodInformer for the given cluster using the
+// application configuration. The executor pods are tracked in the given executor pod store.
+func NewExecutorPodInformer(config *config.ApplicationConfig, cluster config.Cluster, executorPods *store.ExecutorPodStore) *ExecutorPodInformer {
+	labelSelector := config.Spark.Discovery.Executors.LabelSelector
+	if labelSelector == "" {
+		labelSelector = constants.DefaultExecutorLabelSelector
+	}
+
+	return &ExecutorPodInformer{
+		cluster:       cluster.Name,
+		namespaces:    cluster.JobNamespaces,
+		labelSelector: labelSelector,
+		executorPods:  executorPods,
+	}
+}
+
+// WatchExecutorPods starts watching Spark executor pods in all configured namespaces,
+// running one informer per namespace.
+func (i ExecutorPodInformer) WatchExecutorPods(clientset kubernetes.Interface) {
+	watchNamespaces(i.namespaces, func(ctx context.Context, namespace string) {
+		i.WatchNamespace(ctx, clientset, namespace)
+	})
+}
+
+// WatchNamespace starts the Spark executor pod informer of a single namespace
+// and blocks until the context is done.
+func (i ExecutorPodInformer) WatchNamespace(ctx context.Context, clientset kubernetes.Interface, namespace string) {
+	if err := i.run(ctx, clientset, namespace); err != nil {
+		log.Error("Failed to add executor pod event handler: %+v", err)
+		return
+	}
+
+	log.Info("Executor pod informer on the cluster '%s' (namespace: %s) successfully stopped.", i.cluster, namespaceName(namespace))
+}
+
+// run starts the Spark executor pod informer for the given namespace and blocks
+// until the context is done.
+func (i ExecutorPodInformer) run(ctx context.Context, clientset kubernetes.Interface, namespace string) error {
+	log.Info("Running executor pod informer on the cluster '%s' with the label selector '%s' and the following namespaces: %s",
+		i.cluster, i.labelSelector, namespaceName(namespace))
+
+	factory := informers.NewSharedInformerFactoryWithOptions(clientset, 5*time.Minute,
+		informers.WithNamespace(namespace),
+		informers.WithTweakListOptions(func(opts *metav1.ListOptions) {
+			opts.LabelSelector = i.labelSelector
+		}),
+	)
+
+	return runInformer(ctx, factory, factory.Core().V1().Pods().Informer(), cache.ResourceEventHandlerFuncs{
+		AddFunc: i.executorPodAddedOrUpdated,
+		UpdateFunc: func(_, newObj interface{}) {
+			i.executorPodAddedOrUpdated(newObj)
+		},
+		DeleteFunc: i.executorPodDeleted,
+	})
+}
+
+func (i ExecutorPodInformer) executorPodAddedOrUpdated(obj interface{}) {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return
+	}
+
+	executorPod, ok := discovery.ExecutorPodFromPod(i.cluster, pod)
+	if !ok {
+		log.Debug("Ignoring the executor pod %s:%s/%s without application or executor ID", i.cluster, pod.Namespace, pod.Name)
+		return
+	}
+	i.executorPods.Put(executorPod)
+	log.Debug("The executor '%s' of the application '%s' (%s:%s/%s) was updated: %s %s",
+		executorPod.ExecutorID, executorPod.AppID, i.cluster, pod.Namespace, pod.Name, executorPod.Phase, executorPod.State)
+}
+
+// executorPodDeleted keeps the last state of a deleted executor pod, as the
+// driver deletes the pods of the failed executors.
+func (i ExecutorPodInformer) executorPodDeleted(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return
+	}
+
+	executorPod, ok := discovery.ExecutorPodFromPod(i.cluster, pod)
+	if !ok {
+		return
+	}
+	if executorPod.DeletedAtEpoch == 0 {
+		executorPod.DeletedAtEpoch = time.Now().UnixMilli()
+	}
+	i.executorPods.Put(executorPod)
+
+	reason := executorPod.Reason
+	if executorPod.Termination != nil {
+		reason = executorPod.Termination.Reason
+	}
+	log.Info("The executor '%s' of the application '%s' (%s:%s/%s) was removed: %s %s",
+		executorPod.ExecutorID, executorPod.AppID, i.cluster, pod.Namespace, pod.Name, executorPod.Phase, reason)
+}
@@ -0,0 +1,124 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package informers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/okdp/spark-web-proxy/internal/config"
+	log "github.com/okdp/spark-web-proxy/internal/logging"
+	"github.com/okdp/spark-web-proxy/internal/store"
+)
+
+func newExecutorPod(name string, appID string, executorID string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "spark",
+			Labels: map[string]string{
+				"spark-role":         "executor",
+				"spark-app-selector": appID,
+				"spark-exec-id":      executorID,
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name: "spark-kubernetes-executor",
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("1"),
+						corev1.ResourceMemory: resource.MustParse("1408Mi"),
+					},
+				},
+			}},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodPending},
+	}
+}
+
+func Test_ExecutorPodInformer_Lifecycle(t *testing.T) {
+	// Given: a pending executor pod and a pod without executor ID
+	log.SetupGlobalLogger(config.Logging{Level: "info"})
+	orphan := newExecutorPod("spark-pi-exec-x", "spark-123", "")
+	clientset := fake.NewSimpleClientset(newExecutorPod("spark-pi-exec-1", "spark-123", "1"), orphan)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	executorPods := store.NewExecutorPodStore(10)
+	informer := NewExecutorPodInformer(&config.ApplicationConfig{}, config.Cluster{Name: "default"}, executorPods)
+	go func() { _ = informer.run(ctx, clientset, "spark") }()
+
+	// Then
+	assert.Eventually(t, func() bool {
+		return len(executorPods.List("spark-123")) == 1
+	}, 5*time.Second, 10*time.Millisecond, "pending executor pod")
+	executorPod := executorPods.List("spark-123")[0]
+	assert.Equal(t, "1", executorPod.ExecutorID, "ExecutorID")
+	assert.Equal(t, "default", executorPod.Cluster, "Cluster")
+	assert.Equal(t, "spark-pi-exec-1", executorPod.PodName, "PodName")
+	assert.Equal(t, "Pending", executorPod.Phase, "Phase")
+	assert.Equal(t, map[string]string{"cpu": "1", "memory": "1408Mi"}, executorPod.Requests, "Requests")
+
+	// When: the executor container is OOMKilled and restarted
+	pod := newExecutorPod("spark-pi-exec-1", "spark-123", "1")
+	pod.Spec.NodeName = "node-1"
+	pod.Status = corev1.PodStatus{
+		Phase: corev1.PodRunning,
+		ContainerStatuses: []corev1.ContainerStatus{{
+			Name:         "spark-kubernetes-executor",
+			RestartCount: 1,
+			State:        corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+			LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+				Reason:   "OOMKilled",
+				ExitCode: 137,
+			}},
+		}},
+	}
+	_, err := clientset.CoreV1().Pods("spark").Update(ctx, pod, metav1.UpdateOptions{})
+	assert.NoError(t, err)
+
+	// Then
+	assert.Eventually(t, func() bool {
+		return executorPods.List("spark-123")[0].Phase == "Running"
+	}, 5*time.Second, 10*time.Millisecond, "running executor pod")
+	executorPod = executorPods.List("spark-123")[0]
+	assert.Equal(t, "node-1", executorPod.NodeName, "NodeName")
+	assert.Equal(t, "Running", executorPod.State, "State")
+	assert.Equal(t, int32(1), executorPod.Restarts, "Restarts")
+	if assert.NotNil(t, executorPod.Termination, "Termination") {
+		assert.Equal(t, "OOMKilled", executorPod.Termination.Reason, "Termination.Reason")
+		assert.Equal(t, int32(137), executorPod.Termination.ExitCode, "Termination.ExitCode")
+	}
+
+	// When: the driver deletes the executor pod
+	err = clientset.CoreV1().Pods("spark").Delete(ctx, "spark-pi-exec-1", metav1.DeleteOptions{})
+	assert.NoError(t, err)
+
+	// Then: the last state of the executor pod is kept
+	assert.Eventually(t, func() bool {
+		return executorPods.List("spark-123")[0].DeletedAtEpoch != 0
+	}, 5*time.Second, 10*time.Millisecond, "deleted executor pod")
+	assert.Equal(t, "OOMKilled", executorPods.List("spark-123")[0].Termination.Reason, "Termination.Reason")
+}
@@ -0,0 +1,83 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package informers
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// informerFactory is implemented by the typed and dynamic shared informer factories.
+type informerFactory interface {
+	Start(stopCh <-chan struct{})
+	Shutdown()
+}
+
+// watchUntilInterrupted runs the given watch function until the process is interrupted.
+func watchUntilInterrupted(watch func(ctx context.Context)) {
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT, os.Interrupt)
+	defer cancel()
+
+	watch(ctx)
+}
+
+// watchNamespaces runs the given watcher on each namespace (all the namespaces
+// if none is given) in its own goroutine, until the process is interrupted.
+func watchNamespaces(namespaces []string, watch NamespaceWatcher) {
+	if len(namespaces) == 0 {
+		namespaces = []string{metav1.NamespaceAll}
+	}
+
+	for _, ns := range namespaces {
+		go func(namespace string) {
+			watchUntilInterrupted(func(ctx context.Context) {
+				watch(ctx, namespace)
+			})
+		}(ns)
+	}
+}
+
+// runInformer registers the event handler on the informer, starts the informer
+// factory and blocks until the context is done. The event handler is then removed
+// and the factory shut down.
+func runInformer(ctx context.Context, factory informerFactory, informer cache.SharedIndexInformer, handler cache.ResourceEventHandler) error {
+	registration, err := informer.AddEventHandler(handler)
+	if err != nil {
+		return err
+	}
+
+	factory.Start(ctx.Done())
+
+	<-ctx.Done()
+
+	_ = informer.RemoveEventHandler(registration)
+	factory.Shutdown()
+	return nil
+}
+
+// namespaceName returns the name of the namespace to log, "all" for all the namespaces.
+func namespaceName(namespace string) string {
+	if namespace == metav1.NamespaceAll {
+		return "all"
+	}
+	return namespace
+}
@@ -0,0 +1,67 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package informers
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+)
+
+func Test_RunInformer(t *testing.T) {
+	// Given: a pod informer
+	clientset := fake.NewSimpleClientset(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "spark-pi-driver", Namespace: "spark"}})
+	factory := informers.NewSharedInformerFactoryWithOptions(clientset, time.Minute, informers.WithNamespace("spark"))
+	podInformer := factory.Core().V1().Pods().Informer()
+
+	// When
+	var added atomic.Int32
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan error)
+	go func() {
+		stopped <- runInformer(ctx, factory, podInformer, cache.ResourceEventHandlerFuncs{
+			AddFunc: func(interface{}) { added.Add(1) },
+		})
+	}()
+
+	// Then
+	assert.Eventually(t, func() bool { return added.Load() == 1 }, 5*time.Second, 10*time.Millisecond, "added pod")
+
+	// When: the context is done
+	cancel()
+
+	// Then
+	select {
+	case err := <-stopped:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the informer was not stopped")
+	}
+}
+
+func Test_NamespaceName(t *testing.T) {
+	assert.Equal(t, "all", namespaceName(metav1.NamespaceAll))
+	assert.Equal(t, "spark", namespaceName("spark"))
+}
@@ -0,0 +1,193 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package informers
+
+import (
+	"context"
+	"path"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/okdp/spark-web-proxy/internal/config"
+	log "github.com/okdp/spark-web-proxy/internal/logging"
+	"github.com/okdp/spark-web-proxy/internal/store"
+)
+
+// NamespaceWatcher watches the Spark applications of a single namespace and
+// blocks until the context is done.
+type NamespaceWatcher func(ctx context.Context, namespace string)
+
+// NamespaceInformer watches the Kubernetes namespaces matching a label selector
+// and include/exclude glob patterns, and starts or stops the Spark application
+// informers of each namespace as namespaces appear, disappear or change labels.
+type NamespaceInformer struct {
+	cluster   string
+	discovery config.NamespaceDiscovery
+	watchers  []NamespaceWatcher
+	store     store.Store
+
+	mu      sync.Mutex
+	watched map[string]context.CancelFunc
+}
+
+// NewNamespaceInformer creates a NamespaceInformer for the given cluster. The
+// watchers are started for every matching namespace, and the applications of
+// the namespaces no longer watched are removed from the given store.
+func NewNamespaceInformer(cluster config.Cluster, sparkApps store.Store, watchers ...NamespaceWatcher) *NamespaceInformer {
+	return &NamespaceInformer{
+		cluster:   cluster.Name,
+		discovery: cluster.Namespaces,
+		watchers:  watchers,
+		store:     sparkApps,
+		watched:   make(map[string]context.CancelFunc),
+	}
+}
+
+// WatchNamespaces starts watching the namespaces of the cluster.
+func (i *NamespaceInformer) WatchNamespaces(clientset kubernetes.Interface) {
+	watchUntilInterrupted(func(ctx context.Context) {
+		if err := i.run(ctx, clientset); err != nil {
+			log.Error("Failed to add namespace event handler: %+v", err)
+			return
+		}
+
+		log.Info("Namespace informer on the cluster '%s' successfully stopped.", i.cluster)
+	})
+}
+
+// run starts the namespace informer and blocks until the context is done.
+func (i *NamespaceInformer) run(ctx context.Context, clientset kubernetes.Interface) error {
+	selector, err := labels.Parse(i.discovery.LabelSelector)
+	if err != nil {
+		return err
+	}
+
+	log.Info("Running namespace informer on the cluster '%s' with the label selector '%s' (include: %v, exclude: %v)",
+		i.cluster, i.discovery.LabelSelector, i.discovery.Include, i.discovery.Exclude)
+
+	factory := informers.NewSharedInformerFactoryWithOptions(clientset, 5*time.Minute,
+		informers.WithTweakListOptions(func(opts *metav1.ListOptions) {
+			opts.LabelSelector = i.discovery.LabelSelector
+		}),
+	)
+
+	return runInformer(ctx, factory, factory.Core().V1().Namespaces().Informer(), cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			i.namespaceAddedOrUpdated(ctx, selector, obj)
+		},
+		UpdateFunc: func(_, newObj interface{}) {
+			i.namespaceAddedOrUpdated(ctx, selector, newObj)
+		},
+		DeleteFunc: i.namespaceDeleted,
+	})
+}
+
+func (i *NamespaceInformer) namespaceAddedOrUpdated(ctx context.Context, selector labels.Selector, obj interface{}) {
+	namespace, ok := obj.(*corev1.Namespace)
+	if !ok {
+		return
+	}
+
+	if namespace.Status.Phase != corev1.NamespaceTerminating &&
+		selector.Matches(labels.Set(namespace.Labels)) && i.matches(namespace.Name) {
+		i.start(ctx, namespace.Name)
+		return
+	}
+	i.stop(namespace.Name)
+}
+
+func (i *NamespaceInformer) namespaceDeleted(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	namespace, ok := obj.(*corev1.Namespace)
+	if !ok {
+		return
+	}
+	i.stop(namespace.Name)
+}
+
+// matches returns true if the namespace name matches the include patterns (if any)
+// and none of the exclude patterns.
+func (i *NamespaceInformer) matches(name string) bool {
+	for _, pattern := range i.discovery.Exclude {
+		if globMatch(pattern, name) {
+			return false
+		}
+	}
+	if len(i.discovery.Include) == 0 {
+		return true
+	}
+	for _, pattern := range i.discovery.Include {
+		if globMatch(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// start runs the namespace watchers unless they are already running.
+func (i *NamespaceInformer) start(ctx context.Context, namespace string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if _, found := i.watched[namespace]; found {
+		return
+	}
+
+	watchCtx, cancel := context.WithCancel(ctx)
+	i.watched[namespace] = cancel
+	for _, watch := range i.watchers {
+		go watch(watchCtx, namespace)
+	}
+	log.Info("Started watching the namespace %s:%s", i.cluster, namespace)
+}
+
+// stop stops the namespace watchers and removes the applications of the namespace.
+func (i *NamespaceInformer) stop(namespace string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	cancel, found := i.watched[namespace]
+	if !found {
+		return
+	}
+
+	cancel()
+	delete(i.watched, namespace)
+	for _, sparkApp := range store.DeleteAll(i.store, store.Filter{Cluster: i.cluster, Namespace: namespace}) {
+		log.Info("The application '%s' (%s:%s/%s) was removed", sparkApp.AppID, i.cluster, namespace, sparkApp.PodName)
+	}
+	log.Info("Stopped watching the namespace %s:%s", i.cluster, namespace)
+}
+
+// globMatch reports whether the name matches the shell glob pattern.
+func globMatch(pattern string, name string) bool {
+	matched, err := path.Match(pattern, name)
+	if err != nil {
+		log.Warn("Invalid namespace pattern '%s': %+v", pattern, err)
+		return false
+	}
+	return matched
+}
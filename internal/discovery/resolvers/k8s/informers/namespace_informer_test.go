@@ -0,0 +1,112 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package informers
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/okdp/spark-web-proxy/internal/config"
+	log "github.com/okdp/spark-web-proxy/internal/logging"
+	"github.com/okdp/spark-web-proxy/internal/model"
+	"github.com/okdp/spark-web-proxy/internal/store"
+)
+
+func newNamespace(name string, labels map[string]string) *corev1.Namespace {
+	return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+}
+
+func Test_NamespaceInformer_Lifecycle(t *testing.T) {
+	// Given
+	log.SetupGlobalLogger(config.Logging{Level: "info"})
+	sparkJobs := map[string]string{"okdp.io/spark-jobs": "true"}
+	clientset := fake.NewSimpleClientset(
+		newNamespace("tenant-a", sparkJobs),
+		newNamespace("tenant-sandbox-b", sparkJobs),
+		newNamespace("team-c", sparkJobs),
+		newNamespace("tenant-d", nil),
+	)
+
+	var watched sync.Map
+	watcher := func(ctx context.Context, namespace string) {
+		watched.Store(namespace, true)
+		<-ctx.Done()
+		watched.Delete(namespace)
+	}
+	isWatched := func(namespace string) bool {
+		_, found := watched.Load(namespace)
+		return found
+	}
+
+	sparkApps := store.NewMemoryStore()
+	informer := NewNamespaceInformer(config.Cluster{
+		Name: "tenants",
+		Namespaces: config.NamespaceDiscovery{
+			LabelSelector: "okdp.io/spark-jobs=true",
+			Include:       []string{"tenant-*"},
+			Exclude:       []string{"tenant-sandbox-*"},
+		},
+	}, sparkApps, watcher)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = informer.run(ctx, clientset) }()
+
+	// Then: only the matching namespaces are watched
+	assert.Eventually(t, func() bool {
+		return isWatched("tenant-a")
+	}, 5*time.Second, 10*time.Millisecond, "tenant-a")
+	assert.False(t, isWatched("tenant-sandbox-b"), "excluded namespace")
+	assert.False(t, isWatched("team-c"), "not included namespace")
+	assert.False(t, isWatched("tenant-d"), "not labeled namespace")
+
+	// When: a new tenant namespace is created
+	_, err := clientset.CoreV1().Namespaces().Create(ctx, newNamespace("tenant-e", sparkJobs), metav1.CreateOptions{})
+	assert.NoError(t, err)
+
+	// Then
+	assert.Eventually(t, func() bool {
+		return isWatched("tenant-e")
+	}, 5*time.Second, 10*time.Millisecond, "tenant-e")
+
+	// When: the namespace labels no longer match
+	sparkApps.Put(&model.SparkAppInstance{AppID: "spark-tenant-a", Cluster: "tenants", Namespace: "tenant-a", Status: "Running"})
+	_, err = clientset.CoreV1().Namespaces().Update(ctx, newNamespace("tenant-a", nil), metav1.UpdateOptions{})
+	assert.NoError(t, err)
+
+	// Then: the namespace is no longer watched and its applications are removed
+	assert.Eventually(t, func() bool {
+		_, found := sparkApps.Get("spark-tenant-a")
+		return !isWatched("tenant-a") && !found
+	}, 5*time.Second, 10*time.Millisecond, "tenant-a unlabeled")
+
+	// When: the namespace is deleted
+	err = clientset.CoreV1().Namespaces().Delete(ctx, "tenant-e", metav1.DeleteOptions{})
+	assert.NoError(t, err)
+
+	// Then
+	assert.Eventually(t, func() bool {
+		return !isWatched("tenant-e")
+	}, 5*time.Second, 10*time.Millisecond, "tenant-e deleted")
+}
@@ -20,111 +20,212 @@ package informers
 
 import (
 	"context"
-	"os"
-	"os/signal"
-	"syscall"
+	"sync"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
 
 	"github.com/okdp/spark-web-proxy/internal/config"
+	"github.com/okdp/spark-web-proxy/internal/constants"
 	"github.com/okdp/spark-web-proxy/internal/discovery"
 	log "github.com/okdp/spark-web-proxy/internal/logging"
-	"github.com/okdp/spark-web-proxy/internal/model"
+	"github.com/okdp/spark-web-proxy/internal/store"
+	"github.com/okdp/spark-web-proxy/internal/utils"
 )
 
 // SparkAppInformer watches Kubernetes namespaces for Spark driver pods and
 // maintains an in-memory view of running Spark applications.
+//
+// A pod informer runs per label selector. As a pod may match several selectors,
+// the pod is handled by the informer of the first selector it matches only, so
+// that it is resolved once, and a pod leaving a selector while matching another
+// one is not deleted.
 type SparkAppInformer struct {
-	namespaces []string
-	ui         config.UI
+	cluster        string
+	namespaces     []string
+	labelSelectors []string
+	selectors      []labels.Selector
+	probeInterval  time.Duration
+	ui             config.UI
+	store          store.Store
+	resolver       *discovery.SparkAppResolver
 }
 
-// NewSparkAppInformer creates a SparkAppInformer using the application configuration.
-func NewSparkAppInformer(config *config.ApplicationConfig) *SparkAppInformer {
-	return &SparkAppInformer{
-		namespaces: config.Spark.JobNamespaces,
-		ui:         config.Spark.UI,
+// NewSparkAppInformer creates a SparkAppInformer for the given cluster using the application
+// configuration. The discovered applications are registered in the given store.
+func NewSparkAppInformer(config *config.ApplicationConfig, cluster config.Cluster, sparkApps store.Store) *SparkAppInformer {
+	configuredSelectors := config.Spark.Discovery.LabelSelectors
+	if len(configuredSelectors) == 0 {
+		configuredSelectors = []string{constants.DefaultDriverLabelSelector}
 	}
-}
 
-// WatchSparkApps starts watching Spark driver pods in all configured namespaces.
-func (i SparkAppInformer) WatchSparkApps(clientset *kubernetes.Clientset) {
-	namespaces := i.namespaces
-	if len(namespaces) == 0 {
-		namespaces = []string{metav1.NamespaceAll}
+	labelSelectors := make([]string, 0, len(configuredSelectors))
+	selectors := make([]labels.Selector, 0, len(configuredSelectors))
+	for _, labelSelector := range configuredSelectors {
+		selector, err := labels.Parse(labelSelector)
+		if err != nil {
+			log.Error("Invalid spark driver label selector '%s': %+v", labelSelector, err)
+			continue
+		}
+		labelSelectors = append(labelSelectors, labelSelector)
+		selectors = append(selectors, selector)
 	}
 
-	for _, ns := range namespaces {
-		go i.WatchNamespaceSparkApps(clientset, ns)
+	probeInterval := config.Spark.Discovery.ProbeInterval
+	if probeInterval <= 0 {
+		probeInterval = 30 * time.Second
+	}
+
+	return &SparkAppInformer{
+		cluster:        cluster.Name,
+		namespaces:     cluster.JobNamespaces,
+		labelSelectors: labelSelectors,
+		selectors:      selectors,
+		probeInterval:  probeInterval,
+		ui:             config.Spark.UI,
+		store:          sparkApps,
+		resolver:       discovery.NewSparkAppResolver(sparkApps),
 	}
 }
 
-// WatchNamespaceSparkApps starts a Spark driver pod informer for a single namespace.
-func (i SparkAppInformer) WatchNamespaceSparkApps(clientset *kubernetes.Clientset, namespace string) {
+// WatchSparkApps starts watching Spark driver pods in all configured namespaces,
+// running one informer per namespace and label selector.
+func (i SparkAppInformer) WatchSparkApps(clientset kubernetes.Interface) {
+	watchNamespaces(i.namespaces, func(ctx context.Context, namespace string) {
+		i.WatchNamespace(ctx, clientset, namespace)
+	})
+}
 
-	log.Info("Running spark app informer on the following namespaces: %s", func() string {
-		if namespace == metav1.NamespaceAll {
-			return "all"
-		}
-		return namespace
-	}())
+// WatchNamespace starts the Spark driver pod informers of a single namespace,
+// one per label selector, and blocks until the context is done.
+func (i SparkAppInformer) WatchNamespace(ctx context.Context, clientset kubernetes.Interface, namespace string) {
+	var wg sync.WaitGroup
+	for selector := range i.labelSelectors {
+		wg.Add(1)
+		go func(selector int) {
+			defer wg.Done()
+			if err := i.run(ctx, clientset, namespace, selector); err != nil {
+				log.Error("Failed to add spark app event handler: %+v", err)
+				return
+			}
+			log.Info("Spark app informer on the cluster '%s' with the label selector '%s' (namespace: %s) successfully stopped.",
+				i.cluster, i.labelSelectors[selector], namespaceName(namespace))
+		}(selector)
+	}
+	wg.Wait()
+}
+
+// run starts the Spark driver pod informer for the given namespace and label
+// selector (index of the label selector) and blocks until the context is done.
+func (i SparkAppInformer) run(ctx context.Context, clientset kubernetes.Interface, namespace string, selector int) error {
+	labelSelector := i.labelSelectors[selector]
+	log.Info("Running spark app informer on the cluster '%s' with the label selector '%s' and the following namespaces: %s",
+		i.cluster, labelSelector, namespaceName(namespace))
 
 	factory := informers.NewSharedInformerFactoryWithOptions(clientset, 5*time.Minute,
 		informers.WithNamespace(namespace),
 		informers.WithTweakListOptions(func(opts *metav1.ListOptions) {
-			opts.LabelSelector = "spark-role=driver"
+			opts.LabelSelector = labelSelector
 		}),
 	)
 
 	podInformer := factory.Core().V1().Pods().Informer()
 
-	// Register event handlers
-	registration, err := podInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
-		AddFunc: i.sparkAppAddedOrUpdated,
-		UpdateFunc: func(_, newObj interface{}) {
-			i.sparkAppAddedOrUpdated(newObj)
+	go i.probeMultiAppPods(ctx, selector, podInformer.GetStore())
+
+	return runInformer(ctx, factory, podInformer, cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			i.sparkAppAddedOrUpdated(selector, obj)
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			// The periodic resyncs do not change the object
+			if isResync(oldObj, newObj) {
+				return
+			}
+			i.sparkAppAddedOrUpdated(selector, newObj)
+		},
+		DeleteFunc: func(obj interface{}) {
+			i.sparkAppDeleted(selector, obj)
 		},
-		DeleteFunc: i.sparkAppDeleted,
 	})
+}
 
-	if err != nil {
-		log.Error("Failed to add spark app event handler: %+v", err)
-		return
-	}
-
-	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT, os.Interrupt)
-	defer cancel()
-
-	factory.Start(ctx.Done())
-
-	<-ctx.Done()
+// isResync reports whether an informer update is a periodic resync, i.e. the
+// resource version of the object is set and did not change.
+func isResync(oldObj interface{}, newObj interface{}) bool {
+	oldMeta, oldOk := oldObj.(metav1.Object)
+	newMeta, newOk := newObj.(metav1.Object)
+	return oldOk && newOk && oldMeta.GetResourceVersion() != "" && oldMeta.GetResourceVersion() == newMeta.GetResourceVersion()
+}
 
-	log.Info("Received shutdown signal. Stopping Spark app informer...")
-	_ = podInformer.RemoveEventHandler(registration)
-	log.Info("Spark app informer successfully stopped.")
+// owner returns the index of the first label selector matching the pod, or -1 if none does.
+func (i SparkAppInformer) owner(pod *corev1.Pod) int {
+	for selector, labelSelector := range i.selectors {
+		if labelSelector.Matches(labels.Set(pod.Labels)) {
+			return selector
+		}
+	}
+	return -1
 }
 
-func (i SparkAppInformer) sparkAppAddedOrUpdated(obj interface{}) {
+func (i SparkAppInformer) sparkAppAddedOrUpdated(selector int, obj interface{}) {
 	pod, ok := obj.(*corev1.Pod)
-	if !ok {
+	if !ok || i.owner(pod) != selector {
 		return
 	}
 
-	sparkApp, _ := discovery.ResolveSparkAppFromPod(pod)
-	log.Info("The application '%s' (%s/%s) was updated: %s at %s", sparkApp.AppID, sparkApp.Namespace, pod.Name, sparkApp.Status, sparkApp.BaseURL)
+	sparkApps, _ := i.resolver.ResolveSparkAppsFromPod(i.cluster, pod)
+	for _, sparkApp := range sparkApps {
+		log.Info("The application '%s' (%s:%s/%s) was updated: %s at %s", sparkApp.AppID, i.cluster, sparkApp.Namespace, pod.Name, sparkApp.Status, sparkApp.BaseURL)
+	}
 }
 
-func (i SparkAppInformer) sparkAppDeleted(obj interface{}) {
+// probeMultiAppPods periodically probes the pods hosting several Spark applications,
+// as starting or stopping a Spark application does not update the pod.
+func (i SparkAppInformer) probeMultiAppPods(ctx context.Context, selector int, store cache.Store) {
+	ticker := time.NewTicker(i.probeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, obj := range store.List() {
+				pod, ok := obj.(*corev1.Pod)
+				if !ok || !utils.HasMultipleSparkUIs(pod) || i.owner(pod) != selector {
+					continue
+				}
+				sparkApps, _ := i.resolver.ResolveSparkAppsFromPod(i.cluster, pod)
+				log.Debug("Probing the pod %s:%s/%s hosting %d known application(s)", i.cluster, pod.Namespace, pod.Name, len(sparkApps))
+			}
+		}
+	}
+}
+
+// sparkAppDeleted replaces the applications of a deleted driver pod with
+// tombstones, including the pods deleted while the watch was disconnected.
+// A pod leaving the label selector while matching another one is kept.
+func (i SparkAppInformer) sparkAppDeleted(selector int, obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
 	pod, ok := obj.(*corev1.Pod)
 	if !ok {
 		return
 	}
+	if owner := i.owner(pod); owner >= 0 && owner != selector {
+		log.Debug("The pod %s:%s/%s is still watched with the label selector '%s'", i.cluster, pod.Namespace, pod.Name, i.labelSelectors[owner])
+		return
+	}
 
-	sparkApp, _ := model.DeleteSparkAppByName(pod.Name)
-	log.Info("The application '%s' (%s/%s) was removed", sparkApp.AppID, pod.Namespace, pod.Name)
+	for _, sparkApp := range i.resolver.TombstoneSparkAppsFromPod(i.cluster, pod) {
+		log.Info("The application '%s' (%s:%s/%s) was removed: %s (%s, exit code %d)", sparkApp.AppID, i.cluster, pod.Namespace, pod.Name,
+			sparkApp.Status, sparkApp.Termination.Reason, sparkApp.Termination.ExitCode)
+	}
 }
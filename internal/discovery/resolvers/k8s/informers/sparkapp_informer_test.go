@@ -0,0 +1,100 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package informers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/okdp/spark-web-proxy/internal/config"
+	log "github.com/okdp/spark-web-proxy/internal/logging"
+	"github.com/okdp/spark-web-proxy/internal/model"
+	"github.com/okdp/spark-web-proxy/internal/store"
+)
+
+func Test_SparkAppInformer_Deleted_FinalStateUnknown(t *testing.T) {
+	// Given: a running application whose driver pod was deleted during a watch gap
+	log.SetupGlobalLogger(config.Logging{Level: "info"})
+	sparkApps := store.NewMemoryStore()
+	sparkApps.Put(&model.SparkAppInstance{AppID: "spark-123", Cluster: "default", Namespace: "spark", PodName: "spark-pi-driver",
+		Status: string(model.AppRunning), Source: model.SourcePod})
+	informer := NewSparkAppInformer(&config.ApplicationConfig{}, config.Cluster{Name: "default"}, sparkApps)
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "spark-pi-driver", Namespace: "spark"},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+
+	// When
+	informer.sparkAppDeleted(0, cache.DeletedFinalStateUnknown{Key: "spark/spark-pi-driver", Obj: pod})
+
+	// Then: the application is replaced with a lost tombstone
+	sparkApp, found := sparkApps.Get("spark-123")
+	if assert.True(t, found, "tombstone") {
+		assert.True(t, sparkApp.IsTombstone(), "IsTombstone")
+		assert.Equal(t, string(model.AppLost), sparkApp.Status, "Status")
+	}
+}
+
+func Test_SparkAppInformer_Deleted_Label_Selectors(t *testing.T) {
+	// Given: two label selectors and a driver pod leaving the first one while matching the second one
+	log.SetupGlobalLogger(config.Logging{Level: "info"})
+	sparkApps := store.NewMemoryStore()
+	sparkApps.Put(&model.SparkAppInstance{AppID: "spark-123", Cluster: "default", Namespace: "spark", PodName: "jupyter",
+		Status: string(model.AppRunning), Source: model.SourcePod})
+	conf := &config.ApplicationConfig{}
+	conf.Spark.Discovery.LabelSelectors = []string{"spark-role=driver", "app=notebook"}
+	informer := NewSparkAppInformer(conf, config.Cluster{Name: "default"}, sparkApps)
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "jupyter", Namespace: "spark", Labels: map[string]string{"app": "notebook"}},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+
+	// When
+	informer.sparkAppDeleted(0, pod)
+
+	// Then: the application is still tracked with the second label selector
+	sparkApp, _ := sparkApps.Get("spark-123")
+	assert.False(t, sparkApp.IsTombstone(), "IsTombstone")
+
+	// When: the pod matching both selectors is deleted
+	pod.Labels["spark-role"] = "driver"
+	informer.sparkAppDeleted(1, pod)
+
+	// Then: it is handled by the informer of the first selector only
+	sparkApp, _ = sparkApps.Get("spark-123")
+	assert.False(t, sparkApp.IsTombstone(), "IsTombstone")
+	informer.sparkAppDeleted(0, pod)
+	sparkApp, _ = sparkApps.Get("spark-123")
+	assert.True(t, sparkApp.IsTombstone(), "IsTombstone")
+}
+
+func Test_IsResync(t *testing.T) {
+	// Given
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "spark-pi-driver", ResourceVersion: "1"}}
+	updated := pod.DeepCopy()
+	updated.ResourceVersion = "2"
+
+	// Then
+	assert.True(t, isResync(pod, pod.DeepCopy()), "resync")
+	assert.False(t, isResync(pod, updated), "update")
+	assert.False(t, isResync(pod, "unknown"), "unknown object")
+	assert.False(t, isResync(&corev1.Pod{}, &corev1.Pod{}), "resource version not set")
+}
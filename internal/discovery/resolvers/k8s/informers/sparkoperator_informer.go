@@ -0,0 +1,129 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+// Package informers provides Kubernetes informers used to discover and track
+// Spark driver pods and their associated application metadata.
+package informers
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/okdp/spark-web-proxy/internal/config"
+	"github.com/okdp/spark-web-proxy/internal/discovery"
+	log "github.com/okdp/spark-web-proxy/internal/logging"
+	"github.com/okdp/spark-web-proxy/internal/store"
+)
+
+// SparkApplicationGVR identifies the Spark Operator SparkApplication custom resource.
+// ScheduledSparkApplication runs are SparkApplications owned by the schedule,
+// so they are discovered through the same resource.
+var SparkApplicationGVR = schema.GroupVersionResource{
+	Group:    "sparkoperator.k8s.io",
+	Version:  "v1beta2",
+	Resource: "sparkapplications",
+}
+
+// SparkOperatorInformer watches Kubernetes namespaces for Spark Operator
+// SparkApplication custom resources and keeps the in-memory view of Spark
+// applications in sync with the operator reported state.
+type SparkOperatorInformer struct {
+	cluster    string
+	namespaces []string
+	resolver   *discovery.SparkAppResolver
+}
+
+// NewSparkOperatorInformer creates a SparkOperatorInformer for the given cluster.
+// The discovered applications are registered in the given store.
+func NewSparkOperatorInformer(cluster config.Cluster, sparkApps store.Store) *SparkOperatorInformer {
+	return &SparkOperatorInformer{
+		cluster:    cluster.Name,
+		namespaces: cluster.JobNamespaces,
+		resolver:   discovery.NewSparkAppResolver(sparkApps),
+	}
+}
+
+// WatchSparkApplications starts watching SparkApplication resources in all configured namespaces,
+// running one informer per namespace.
+func (i SparkOperatorInformer) WatchSparkApplications(client dynamic.Interface) {
+	watchNamespaces(i.namespaces, func(ctx context.Context, namespace string) {
+		i.WatchNamespace(ctx, client, namespace)
+	})
+}
+
+// WatchNamespace starts the SparkApplication informer of a single namespace and
+// blocks until the context is done.
+func (i SparkOperatorInformer) WatchNamespace(ctx context.Context, client dynamic.Interface, namespace string) {
+	if err := i.run(ctx, client, namespace); err != nil {
+		log.Error("Failed to add spark application event handler: %+v", err)
+		return
+	}
+
+	log.Info("Spark operator informer on the cluster '%s' (namespace: %s) successfully stopped.", i.cluster, namespaceName(namespace))
+}
+
+// run starts the SparkApplication informer for the given namespace and blocks
+// until the context is done.
+func (i SparkOperatorInformer) run(ctx context.Context, client dynamic.Interface, namespace string) error {
+	log.Info("Running spark operator informer on the cluster '%s' and the following namespaces: %s", i.cluster, namespaceName(namespace))
+
+	factory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(client, 5*time.Minute, namespace, nil)
+
+	return runInformer(ctx, factory, factory.ForResource(SparkApplicationGVR).Informer(), cache.ResourceEventHandlerFuncs{
+		AddFunc: i.sparkApplicationAddedOrUpdated,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			// The periodic resyncs do not change the object
+			if isResync(oldObj, newObj) {
+				return
+			}
+			i.sparkApplicationAddedOrUpdated(newObj)
+		},
+		DeleteFunc: i.sparkApplicationDeleted,
+	})
+}
+
+func (i SparkOperatorInformer) sparkApplicationAddedOrUpdated(obj interface{}) {
+	sparkApplication, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return
+	}
+
+	sparkApp, err := i.resolver.ResolveSparkAppFromSparkApplication(i.cluster, sparkApplication)
+	if err != nil {
+		log.Warn("Unable to resolve the spark application %s:%s/%s: %+v", i.cluster, sparkApplication.GetNamespace(), sparkApplication.GetName(), err)
+		return
+	}
+	log.Info("The spark application '%s' (%s:%s/%s) was updated: %s (%s)", sparkApp.AppID, i.cluster, sparkApp.Namespace, sparkApp.CRName, sparkApp.Status, sparkApp.ApplicationState)
+}
+
+func (i SparkOperatorInformer) sparkApplicationDeleted(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	sparkApplication, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return
+	}
+
+	sparkApp, _ := i.resolver.DeleteSparkAppFromSparkApplication(i.cluster, sparkApplication)
+	log.Info("The spark application '%s' (%s:%s/%s) was removed", sparkApp.AppID, i.cluster, sparkApplication.GetNamespace(), sparkApplication.GetName())
+}
@@ -0,0 +1,124 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package informers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+
+	"github.com/okdp/spark-web-proxy/internal/config"
+	log "github.com/okdp/spark-web-proxy/internal/logging"
+	"github.com/okdp/spark-web-proxy/internal/model"
+	"github.com/okdp/spark-web-proxy/internal/store"
+)
+
+func newSparkApplication(name string, appID string, state string) *unstructured.Unstructured {
+	sparkApplication := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "sparkoperator.k8s.io/v1beta2",
+		"kind":       "SparkApplication",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": "spark",
+		},
+	}}
+	if state != "" {
+		sparkApplication.Object["status"] = map[string]interface{}{
+			"sparkApplicationId": appID,
+			"applicationState": map[string]interface{}{
+				"state": state,
+			},
+			"driverInfo": map[string]interface{}{
+				"podName":          name + "-driver",
+				"webUIAddress":     "10.0.0.1:4040",
+				"webUIServiceName": name + "-ui-svc",
+			},
+		}
+	}
+	return sparkApplication
+}
+
+func Test_SparkOperatorInformer_Lifecycle(t *testing.T) {
+	// Given
+	log.SetupGlobalLogger(config.Logging{Level: "info"})
+	scheme := runtime.NewScheme()
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(scheme,
+		map[schema.GroupVersionResource]string{SparkApplicationGVR: "SparkApplicationList"},
+		newSparkApplication("spark-pi", "", ""))
+	resource := client.Resource(SparkApplicationGVR).Namespace("spark")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sparkApps := store.NewMemoryStore()
+	informer := NewSparkOperatorInformer(config.Cluster{Name: "default"}, sparkApps)
+	go func() { _ = informer.run(ctx, client, "spark") }()
+
+	// Then: the application is tracked before the driver pod exists
+	pendingKey := model.SparkOperatorAppKey("default", "spark", "spark-pi")
+	assert.Eventually(t, func() bool {
+		app, found := sparkApps.Get(pendingKey)
+		return found && app.Status == string(model.AppPending)
+	}, 5*time.Second, 10*time.Millisecond, "pending SparkApplication")
+
+	// When: the operator reports the driver as running
+	_, err := resource.Update(ctx, newSparkApplication("spark-pi", "spark-123", "RUNNING"), metav1.UpdateOptions{})
+	assert.NoError(t, err)
+
+	// Then: the driver is starting until its spark ui answers
+	assert.Eventually(t, func() bool {
+		app, found := sparkApps.Get("spark-123")
+		return found && app.Status == string(model.AppStarting)
+	}, 5*time.Second, 10*time.Millisecond, "starting SparkApplication")
+	_, found := sparkApps.Get(pendingKey)
+	assert.False(t, found, "pending key should be removed")
+
+	app, _ := sparkApps.Get("spark-123")
+	assert.Equal(t, "default", app.Cluster, "Cluster")
+	assert.Equal(t, "spark-pi", app.CRName, "CRName")
+	assert.Equal(t, "spark-pi-driver", app.PodName, "PodName")
+	assert.Equal(t, "spark-pi-ui-svc", app.UIServiceName, "UIServiceName")
+	assert.Equal(t, "http://10.0.0.1:4040", app.BaseURL, "BaseURL")
+	assert.Len(t, app.Transitions, 2, "Transitions")
+	assert.Equal(t, string(model.AppPending), app.Transitions[0].Status, "first transition")
+
+	// When: the operator reports a failure
+	_, err = resource.Update(ctx, newSparkApplication("spark-pi", "spark-123", "FAILED"), metav1.UpdateOptions{})
+	assert.NoError(t, err)
+
+	// Then
+	assert.Eventually(t, func() bool {
+		app, found := sparkApps.Get("spark-123")
+		return found && app.Status == string(model.AppFailed)
+	}, 5*time.Second, 10*time.Millisecond, "failed SparkApplication")
+
+	// When: the SparkApplication is deleted
+	err = resource.Delete(ctx, "spark-pi", metav1.DeleteOptions{})
+	assert.NoError(t, err)
+
+	// Then
+	assert.Eventually(t, func() bool {
+		_, found := sparkApps.Get("spark-123")
+		return !found
+	}, 5*time.Second, 10*time.Millisecond, "deleted SparkApplication")
+}
@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/cookiejar"
+	"strings"
 
 	"github.com/okdp/spark-web-proxy/internal/constants"
 	log "github.com/okdp/spark-web-proxy/internal/logging"
@@ -32,6 +33,9 @@ import (
 type SparkClient struct {
 	Client  *http.Client
 	Request *http.Request
+	// BasePath is the path prefix of the upstream base URL (e.g. when the Spark UI
+	// is reached through the Kubernetes API server proxy).
+	BasePath string
 }
 
 // NewSparkClient creates a new SparkClient for forwarding an incoming HTTP
@@ -64,7 +68,8 @@ func NewSparkClient(request *http.Request, sparkHistoryBaseURL string) (*SparkCl
 	req.Header.Set("Accept-Encoding", "identity")
 
 	return &SparkClient{
-		Client:  &http.Client{Jar: jar},
-		Request: req,
+		Client:   &http.Client{Jar: jar},
+		Request:  req,
+		BasePath: strings.TrimSuffix(req.URL.Path, constants.SparkAppsEndpoint),
 	}, nil
 }
@@ -21,8 +21,10 @@ package sparkclient
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/okdp/spark-web-proxy/internal/constants"
 	restclient "github.com/okdp/spark-web-proxy/internal/discovery/resolvers/rest/client"
@@ -30,6 +32,10 @@ import (
 	"github.com/okdp/spark-web-proxy/internal/model"
 )
 
+// maxDrainedBytes bounds how much of an unread response body is discarded before
+// closing it, so that a large unexpected payload does not stall the caller.
+const maxDrainedBytes = 64 << 10
+
 // SparkRestClient provides high-level methods to query the Spark History Server API.
 type SparkRestClient struct {
 	*restclient.SparkClient
@@ -44,6 +50,29 @@ func NewSparkRestClient(request *http.Request, sparkHistoryBaseURL string) (*Spa
 	}, err
 }
 
+// NewSparkUIRestClient creates a SparkRestClient querying a Spark UI (or Spark History)
+// REST API on behalf of the proxy itself, i.e. without an incoming HTTP request.
+// The requests are sent through the given transport (nil uses http.DefaultTransport)
+// and time out after the given duration.
+func NewSparkUIRestClient(sparkUIBaseURL string, transport http.RoundTripper, timeout time.Duration) (*SparkRestClient, error) {
+	request, err := http.NewRequest(http.MethodGet, sparkUIBaseURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	request.URL.RawQuery = ""
+
+	client, err := restclient.NewSparkClient(request, sparkUIBaseURL)
+	if err != nil {
+		return nil, err
+	}
+	client.Client.Transport = transport
+	client.Client.Timeout = timeout
+
+	return &SparkRestClient{
+		client,
+	}, nil
+}
+
 // GetApplications retrieves the list of applications from the Spark History Server.
 func (c *SparkRestClient) GetApplications() (*[]model.SparkApp, error) {
 
@@ -59,7 +88,7 @@ func (c *SparkRestClient) GetApplications() (*[]model.SparkApp, error) {
 
 // GetApplicationInfo retrieves application details for the given application ID.
 func (c *SparkRestClient) GetApplicationInfo(appID string) (*model.SparkApp, error) {
-	c.Request.URL.Path = fmt.Sprintf("%s/%s", constants.SparkAppsEndpoint, appID)
+	c.Request.URL.Path = fmt.Sprintf("%s%s/%s", c.BasePath, constants.SparkAppsEndpoint, appID)
 
 	log.Debug("Get the application status '%s' from URL: %s", appID, c.Request.URL.String())
 
@@ -73,7 +102,7 @@ func (c *SparkRestClient) GetApplicationInfo(appID string) (*model.SparkApp, err
 
 // GetEnvironment retrieves environment properties for the given application ID.
 func (c *SparkRestClient) GetEnvironment(appID string) (*model.SparkAppEnvironment, error) {
-	c.Request.URL.Path = fmt.Sprintf("%s/%s/%s", constants.SparkAppsEndpoint, appID, "environment")
+	c.Request.URL.Path = fmt.Sprintf("%s%s/%s/%s", c.BasePath, constants.SparkAppsEndpoint, appID, "environment")
 
 	log.Debug("Get the application '%s' environment properties from URL: %s", appID, c.Request.URL.String())
 
@@ -85,8 +114,30 @@ func (c *SparkRestClient) GetEnvironment(appID string) (*model.SparkAppEnvironme
 	return doResponse[model.SparkAppEnvironment](resp, appID)
 }
 
+// GetExecutors retrieves the executors of the given application ID, including the
+// removed executors (allexecutors endpoint).
+func (c *SparkRestClient) GetExecutors(appID string) (*[]model.SparkExecutor, error) {
+	c.Request.URL.Path = fmt.Sprintf("%s%s/%s/%s", c.BasePath, constants.SparkAppsEndpoint, appID, "allexecutors")
+
+	log.Debug("Get the application '%s' executors from URL: %s", appID, c.Request.URL.String())
+
+	resp, err := c.Client.Do(c.Request)
+	if err != nil {
+		return nil, err
+	}
+
+	return doResponse[[]model.SparkExecutor](resp, appID)
+}
+
 // doResponse validates that the upstream response contains JSON and decodes it into T.
+// The response body is always drained and closed so that the underlying connection
+// can be reused by the (periodic) callers.
 func doResponse[T any](response *http.Response, appID string) (*T, error) {
+	defer func() {
+		_, _ = io.Copy(io.Discard, io.LimitReader(response.Body, maxDrainedBytes))
+		_ = response.Body.Close()
+	}()
+
 	var object T
 	ct := strings.ToLower(response.Header.Get("Content-Type"))
 
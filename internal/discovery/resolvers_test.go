@@ -0,0 +1,57 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package discovery
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/okdp/spark-web-proxy/internal/model"
+)
+
+func Test_SparkAppFromHistory_AppID(t *testing.T) {
+	tests := []struct {
+		name       string
+		properties [][]string
+		expected   string
+	}{
+		{
+			name:       "spark.app.id is set",
+			properties: [][]string{{"spark.app.id", "spark-1"}},
+			expected:   "spark-1",
+		},
+		{
+			name:       "spark.app.id is missing",
+			properties: [][]string{{"spark.app.name", "notebook"}},
+			expected:   "spark-history-1",
+		},
+		{
+			name:       "spark.app.id is empty",
+			properties: [][]string{{"spark.app.id", ""}},
+			expected:   "spark-history-1",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			appInfo := &model.SparkApp{ID: "spark-history-1"}
+			sparkApp := sparkAppFromHistory(appInfo, &model.SparkAppEnvironment{SparkProperties: test.properties})
+			assert.Equal(t, test.expected, sparkApp.AppID, "AppID")
+		})
+	}
+}
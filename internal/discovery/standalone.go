@@ -0,0 +1,295 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package discovery
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/okdp/spark-web-proxy/internal/config"
+	log "github.com/okdp/spark-web-proxy/internal/logging"
+	"github.com/okdp/spark-web-proxy/internal/model"
+	"github.com/okdp/spark-web-proxy/internal/store"
+)
+
+// standaloneMasterTimeout is the timeout of a single Spark standalone master request.
+const standaloneMasterTimeout = 10 * time.Second
+
+// applicationDetailUILink matches the link to the driver UI of the application page of a master.
+var applicationDetailUILink = regexp.MustCompile(`<a\s+href="([^"]+)"\s*>\s*Application Detail UI\s*</a>`)
+
+// StandaloneDiscovery discovers the running applications of a Spark standalone
+// cluster by polling the /json endpoint of its alive master, and registers them
+// with their driver UI URLs. The masters are tried in turn until the alive one is
+// found, so that a master failover is followed. The worker UIs reported by the
+// master can be proxied using WorkerUIURL.
+type StandaloneDiscovery struct {
+	store      store.Store
+	cluster    string
+	masterURLs []string
+	interval   time.Duration
+	client     *http.Client
+
+	mu sync.RWMutex
+	// active is the index of the last alive master.
+	active int
+	// workers holds the web UI addresses of the workers by worker ID.
+	workers map[string]string
+	// appUIs holds the driver UI URLs by application ID, as the /json endpoint does not report them.
+	appUIs map[string]string
+}
+
+// NewStandaloneDiscovery creates a StandaloneDiscovery of the given Spark standalone
+// cluster registering the applications in the given store.
+func NewStandaloneDiscovery(conf config.StandaloneCluster, pollInterval time.Duration, sparkApps store.Store) *StandaloneDiscovery {
+	if pollInterval <= 0 {
+		pollInterval = 15 * time.Second
+	}
+	masterURLs := make([]string, 0, len(conf.MasterURLs))
+	for _, masterURL := range conf.MasterURLs {
+		masterURLs = append(masterURLs, strings.TrimSuffix(masterURL, "/"))
+	}
+	return &StandaloneDiscovery{
+		store:      sparkApps,
+		cluster:    conf.Name,
+		masterURLs: masterURLs,
+		interval:   pollInterval,
+		client:     &http.Client{Timeout: standaloneMasterTimeout},
+		workers:    make(map[string]string),
+		appUIs:     make(map[string]string),
+	}
+}
+
+// Cluster returns the name of the Spark standalone cluster.
+func (d *StandaloneDiscovery) Cluster() string {
+	return d.cluster
+}
+
+// Run polls the alive master every poll interval until the context is done.
+func (d *StandaloneDiscovery) Run(ctx context.Context) {
+	log.Info("Polling the spark standalone cluster '%s' every %s (masters: %v)", d.cluster, d.interval, d.masterURLs)
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := d.Poll(); err != nil {
+			log.Warn("Unable to poll the spark standalone cluster '%s': %v", d.cluster, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll lists the running applications and the workers of the alive master,
+// registers the applications which are not tracked yet and removes the
+// applications of the cluster which are no longer running.
+func (d *StandaloneDiscovery) Poll() error {
+	masterURL, state, err := d.aliveMaster()
+	if err != nil {
+		return err
+	}
+
+	workers := make(map[string]string, len(state.Workers))
+	for _, worker := range state.Workers {
+		if worker.WebUIAddress != "" {
+			workers[worker.ID] = strings.TrimSuffix(worker.WebUIAddress, "/")
+		}
+	}
+
+	d.mu.Lock()
+	d.workers = workers
+	d.mu.Unlock()
+
+	running := make(map[string]bool, len(state.ActiveApps))
+	for _, app := range state.ActiveApps {
+		running[app.ID] = true
+		if existing, found := d.store.Get(app.ID); found && existing.Source != model.SourceStandalone {
+			continue
+		}
+
+		sparkUIURL, err := d.appUIURL(masterURL, app.ID)
+		if err != nil {
+			log.Debug("The spark ui of the application '%s' of the standalone cluster '%s' is not available: %v", app.ID, d.cluster, err)
+			continue
+		}
+		d.register(app, sparkUIURL)
+	}
+
+	for _, sparkApp := range d.store.List(store.Filter{Cluster: d.cluster, Source: model.SourceStandalone}) {
+		if running[sparkApp.AppID] {
+			continue
+		}
+		d.store.Delete(sparkApp.AppID)
+		d.mu.Lock()
+		delete(d.appUIs, sparkApp.AppID)
+		d.mu.Unlock()
+		log.Info("The application '%s' of the standalone cluster '%s' is no longer running", sparkApp.AppID, d.cluster)
+	}
+	return nil
+}
+
+// WorkerUIURL returns the web UI address of a worker of the cluster.
+func (d *StandaloneDiscovery) WorkerUIURL(workerID string) (string, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	workerUIURL, found := d.workers[workerID]
+	return workerUIURL, found
+}
+
+// register registers a running application of the cluster, or updates it.
+func (d *StandaloneDiscovery) register(app model.StandaloneApplication, sparkUIURL string) {
+	sparkApp := &model.SparkAppInstance{
+		BaseURL:          sparkUIURL,
+		AppID:            app.ID,
+		AppName:          app.Name,
+		StartTimeEpoch:   app.StartTime,
+		Cluster:          d.cluster,
+		ApplicationState: app.State,
+		User:             app.User,
+		Source:           model.SourceStandalone,
+	}
+
+	found := false
+	sparkApp, _ = store.Upsert(d.store, app.ID, func(existing *model.SparkAppInstance) *model.SparkAppInstance {
+		found = existing != nil
+		if found && (existing.Source != model.SourceStandalone ||
+			existing.BaseURL == sparkApp.BaseURL && existing.ApplicationState == sparkApp.ApplicationState) {
+			return nil
+		}
+		merged := *sparkApp
+		if found {
+			merged.InheritState(existing)
+		}
+		merged.TransitionTo(model.AppRunning, time.Now())
+		return &merged
+	})
+
+	if !found {
+		log.Info("The application '%s' of the standalone cluster '%s' is served at %s", app.ID, d.cluster, sparkUIURL)
+	}
+	if sparkApp.Source == model.SourceStandalone && sparkApp.UI == nil {
+		resolveSparkUISettingsAsync(d.store, app.ID)
+	}
+}
+
+// aliveMaster returns the URL and the state of the alive master, starting with
+// the last alive master.
+func (d *StandaloneDiscovery) aliveMaster() (string, *model.StandaloneMasterState, error) {
+	if len(d.masterURLs) == 0 {
+		return "", nil, fmt.Errorf("no master url is configured")
+	}
+
+	d.mu.RLock()
+	active := d.active
+	d.mu.RUnlock()
+
+	errs := make([]error, 0, len(d.masterURLs))
+	for i := range d.masterURLs {
+		index := (active + i) % len(d.masterURLs)
+		masterURL := d.masterURLs[index]
+
+		state := &model.StandaloneMasterState{}
+		if err := d.getJSON(masterURL+"/json/", state); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", masterURL, err))
+			continue
+		}
+		if state.Status != model.StandaloneMasterAlive {
+			errs = append(errs, fmt.Errorf("%s: the master is %s", masterURL, state.Status))
+			continue
+		}
+
+		if index != active {
+			log.Info("The master %s of the standalone cluster '%s' is alive", masterURL, d.cluster)
+			d.mu.Lock()
+			d.active = index
+			d.mu.Unlock()
+		}
+		return masterURL, state, nil
+	}
+	return "", nil, fmt.Errorf("no alive master: %w", errors.Join(errs...))
+}
+
+// appUIURL returns the driver UI URL of an application, read from the
+// "Application Detail UI" link of its master application page.
+func (d *StandaloneDiscovery) appUIURL(masterURL string, appID string) (string, error) {
+	d.mu.RLock()
+	sparkUIURL, found := d.appUIs[appID]
+	d.mu.RUnlock()
+	if found {
+		return sparkUIURL, nil
+	}
+
+	appPageURL := fmt.Sprintf("%s/app/?appId=%s", masterURL, url.QueryEscape(appID))
+	page, err := d.get(appPageURL)
+	if err != nil {
+		return "", err
+	}
+	link := applicationDetailUILink.FindSubmatch(page)
+	if link == nil {
+		return "", fmt.Errorf("no application detail ui link found at %s", appPageURL)
+	}
+
+	// The link is relative to the master when the master is a reverse proxy (spark.ui.reverseProxy)
+	base, _ := url.Parse(masterURL + "/")
+	uiURL, err := base.Parse(strings.ReplaceAll(string(link[1]), "&amp;", "&"))
+	if err != nil {
+		return "", err
+	}
+	sparkUIURL = strings.TrimSuffix(uiURL.String(), "/")
+
+	d.mu.Lock()
+	d.appUIs[appID] = sparkUIURL
+	d.mu.Unlock()
+	return sparkUIURL, nil
+}
+
+// getJSON decodes the JSON response of a master endpoint.
+func (d *StandaloneDiscovery) getJSON(endpoint string, object any) error {
+	body, err := d.get(endpoint)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, object)
+}
+
+// get returns the body of a successful response of a master endpoint.
+func (d *StandaloneDiscovery) get(endpoint string) ([]byte, error) {
+	response, err := d.client.Get(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = response.Body.Close() }()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", response.StatusCode)
+	}
+	return io.ReadAll(response.Body)
+}
@@ -0,0 +1,113 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package discovery
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/okdp/spark-web-proxy/internal/config"
+	log "github.com/okdp/spark-web-proxy/internal/logging"
+	"github.com/okdp/spark-web-proxy/internal/model"
+	"github.com/okdp/spark-web-proxy/internal/store"
+)
+
+// fakeMaster is a fake Spark standalone master serving the /json endpoint and the application pages.
+type fakeMaster struct {
+	*httptest.Server
+	status atomic.Value
+	apps   atomic.Value
+}
+
+func newFakeMaster() *fakeMaster {
+	master := &fakeMaster{}
+	master.status.Store(model.StandaloneMasterStandby)
+	master.apps.Store(`[]`)
+	master.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/json/":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = fmt.Fprintf(w, `{"url":"spark://master:7077","status":"%s",
+				"workers":[{"id":"worker-1","host":"10.0.0.5","webuiaddress":"http://10.0.0.5:8081","state":"ALIVE"}],
+				"activeapps":%s}`, master.status.Load(), master.apps.Load())
+		case "/app/":
+			_, _ = fmt.Fprintf(w, `<html><ul><li><strong>ID:</strong> %s</li>
+				<li><strong>
+				  <a href="http://10.0.0.6:4040">Application Detail UI</a>
+				</strong></li></ul></html>`, r.URL.Query().Get("appId"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return master
+}
+
+func Test_StandaloneDiscovery(t *testing.T) {
+	// Given: a standby master and an alive master running an application
+	log.SetupGlobalLogger(config.Logging{Level: "info"})
+	master1, master2 := newFakeMaster(), newFakeMaster()
+	defer master1.Close()
+	defer master2.Close()
+	master2.status.Store(model.StandaloneMasterAlive)
+	master2.apps.Store(`[{"id":"app-20260501100000-0000","name":"etl","user":"alice","starttime":1000,"state":"RUNNING"}]`)
+
+	sparkApps := store.NewMemoryStore()
+	standalone := NewStandaloneDiscovery(config.StandaloneCluster{
+		Name:       "standalone",
+		MasterURLs: []string{master1.URL, master2.URL + "/"},
+	}, 0, sparkApps)
+
+	// When
+	err := standalone.Poll()
+
+	// Then: the application is registered with its driver ui
+	assert.NoError(t, err)
+	sparkApp, found := sparkApps.Get("app-20260501100000-0000")
+	if assert.True(t, found, "application registered") {
+		assert.Equal(t, "http://10.0.0.6:4040", sparkApp.BaseURL, "BaseURL")
+		assert.Equal(t, "standalone", sparkApp.Cluster, "Cluster")
+		assert.Equal(t, "alice", sparkApp.User, "User")
+		assert.Equal(t, int64(1000), sparkApp.StartTimeEpoch, "StartTimeEpoch")
+		assert.Equal(t, model.SourceStandalone, sparkApp.Source, "Source")
+		assert.Equal(t, string(model.AppRunning), sparkApp.Status, "Status")
+	}
+	workerUIURL, found := standalone.WorkerUIURL("worker-1")
+	assert.True(t, found, "worker found")
+	assert.Equal(t, "http://10.0.0.5:8081", workerUIURL, "worker ui")
+
+	// When: the masters fail over and the application completed meanwhile
+	master2.status.Store(model.StandaloneMasterStandby)
+	master1.status.Store(model.StandaloneMasterAlive)
+	err = standalone.Poll()
+
+	// Then: the new alive master is used
+	assert.NoError(t, err)
+	_, found = sparkApps.Get("app-20260501100000-0000")
+	assert.False(t, found, "application removed")
+
+	// When: no master is alive
+	master1.Close()
+	err = standalone.Poll()
+
+	// Then
+	assert.ErrorContains(t, err, "no alive master")
+}
@@ -0,0 +1,234 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package discovery
+
+import (
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	sparkclient "github.com/okdp/spark-web-proxy/internal/discovery/resolvers/rest"
+	log "github.com/okdp/spark-web-proxy/internal/logging"
+	"github.com/okdp/spark-web-proxy/internal/model"
+	"github.com/okdp/spark-web-proxy/internal/store"
+)
+
+// sparkDriverContainer is the name of the driver container set by spark-submit.
+const sparkDriverContainer = "spark-kubernetes-driver"
+
+// historyListingTTL is how long the Spark History listing used to cross-check the
+// tombstones is cached, as it is needed by every incomplete applications request.
+var historyListingTTL = 30 * time.Second
+
+// historyListing is a cached listing of the Spark History application IDs.
+type historyListing struct {
+	appIDs    map[string]bool
+	expiresAt time.Time
+}
+
+// historyListings caches the Spark History listings by URL.
+var historyListings = struct {
+	sync.Mutex
+	byURL map[string]historyListing
+}{byURL: make(map[string]historyListing)}
+
+// TombstoneSparkAppsFromPod replaces the Spark applications of a deleted driver
+// pod with tombstones recording the driver termination, and returns them.
+//
+// A driver may die before its event logs are flushed (OOM, node loss, event log
+// upload failure), in which case Spark History never lists the application. The
+// tombstones are kept until they are found in Spark History or expire.
+// The applications whose ID was never resolved are removed.
+func (r SparkAppResolver) TombstoneSparkAppsFromPod(cluster string, pod *corev1.Pod) []*model.SparkAppInstance {
+	termination := podTermination(pod)
+	tombstones := make([]*model.SparkAppInstance, 0)
+
+	podKey := model.SparkPodAppKey(cluster, pod.Namespace, pod.Name)
+	for _, sparkApp := range r.store.List(store.Filter{Cluster: cluster, Namespace: pod.Namespace, PodName: pod.Name}) {
+		if sparkApp.AppID == podKey {
+			r.store.Delete(podKey)
+			continue
+		}
+		tombstone, updated := r.store.Update(sparkApp.AppID, func(app *model.SparkAppInstance) bool {
+			// The application may have moved to another pod in the meantime
+			if app.Cluster != cluster || app.Namespace != pod.Namespace || app.PodName != pod.Name {
+				return false
+			}
+			app.Source = model.SourceTombstone
+			app.Termination = termination
+			switch {
+			case termination.FinishedAtEpoch > 0 && termination.ExitCode == 0:
+				app.TransitionTo(model.AppSucceeded, time.Now())
+			case termination.FinishedAtEpoch > 0:
+				app.TransitionTo(model.AppFailed, time.Now())
+			default:
+				app.TransitionTo(model.AppLost, time.Now())
+			}
+			return true
+		})
+		if updated {
+			tombstones = append(tombstones, tombstone)
+		}
+	}
+	return tombstones
+}
+
+// ResolveGhostSparkApps cross-checks the tombstones against the Spark History
+// Server applications and returns the ghost applications, i.e. the tombstones
+// not listed by Spark History once the grace period has elapsed since the
+// driver pod deletion. The tombstones listed by Spark History are removed.
+func (r SparkAppResolver) ResolveGhostSparkApps(request *http.Request, sparkHistoryBaseURL string, gracePeriod time.Duration) ([]*model.SparkAppInstance, error) {
+	ghosts := make([]*model.SparkAppInstance, 0)
+	tombstones := r.store.List(store.Filter{Source: model.SourceTombstone})
+	if len(tombstones) == 0 {
+		return ghosts, nil
+	}
+
+	// List all the applications started since the oldest tombstone, or all the
+	// applications when the start time of a tombstone is unknown
+	query := url.Values{}
+	minStartTimeEpoch := int64(-1)
+	for _, tombstone := range tombstones {
+		if tombstone.StartTimeEpoch <= 0 {
+			minStartTimeEpoch = -1
+			break
+		}
+		if minStartTimeEpoch < 0 || tombstone.StartTimeEpoch < minStartTimeEpoch {
+			minStartTimeEpoch = tombstone.StartTimeEpoch
+		}
+	}
+	if minStartTimeEpoch > 0 {
+		query.Set("minDate", time.UnixMilli(minStartTimeEpoch).UTC().AddDate(0, 0, -1).Format(time.DateOnly))
+	}
+
+	logged, err := listHistoryAppIDs(request, sparkHistoryBaseURL, query)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for _, tombstone := range tombstones {
+		if logged[tombstone.AppID] {
+			log.Debug("The application '%s' of the deleted pod %s:%s/%s was found in spark history", tombstone.AppID, tombstone.Cluster, tombstone.Namespace, tombstone.PodName)
+			r.store.Delete(tombstone.AppID)
+			continue
+		}
+		if tombstone.Termination != nil && now.Sub(time.UnixMilli(tombstone.Termination.DeletedAtEpoch)) < gracePeriod {
+			continue
+		}
+		ghosts = append(ghosts, tombstone)
+	}
+	return ghosts, nil
+}
+
+// listHistoryAppIDs returns the IDs of the Spark History applications matching the
+// given query. The listings are cached for historyListingTTL.
+func listHistoryAppIDs(request *http.Request, sparkHistoryBaseURL string, query url.Values) (map[string]bool, error) {
+	listingURL := sparkHistoryBaseURL + "?" + query.Encode()
+	now := time.Now()
+
+	historyListings.Lock()
+	listing, found := historyListings.byURL[listingURL]
+	historyListings.Unlock()
+	if found && now.Before(listing.expiresAt) {
+		return listing.appIDs, nil
+	}
+
+	sparkHistoryClient, err := sparkclient.NewSparkRestClient(request, sparkHistoryBaseURL)
+	if err != nil {
+		return nil, err
+	}
+	sparkHistoryClient.Request.URL.RawQuery = query.Encode()
+
+	historyApps, err := sparkHistoryClient.GetApplications()
+	if err != nil {
+		return nil, err
+	}
+	appIDs := make(map[string]bool, len(*historyApps))
+	for _, app := range *historyApps {
+		appIDs[app.ID] = true
+	}
+
+	historyListings.Lock()
+	defer historyListings.Unlock()
+	for cachedURL, cached := range historyListings.byURL {
+		if !now.Before(cached.expiresAt) {
+			delete(historyListings.byURL, cachedURL)
+		}
+	}
+	historyListings.byURL[listingURL] = historyListing{appIDs: appIDs, expiresAt: now.Add(historyListingTTL)}
+	return appIDs, nil
+}
+
+// podTermination returns the termination of the driver container of the pod,
+// falling back to the pod status reason (e.g. Evicted) when it did not terminate.
+func podTermination(pod *corev1.Pod) *model.Termination {
+	termination := &model.Termination{
+		Reason:          pod.Status.Reason,
+		Message:         pod.Status.Message,
+		ExitCode:        -1,
+		FinishedAtEpoch: -1,
+		DeletedAtEpoch:  time.Now().UnixMilli(),
+	}
+	if pod.DeletionTimestamp != nil {
+		termination.DeletedAtEpoch = pod.DeletionTimestamp.UnixMilli()
+	}
+
+	if terminated := driverTerminatedState(pod); terminated != nil {
+		termination.Reason = terminated.Reason
+		termination.Message = terminated.Message
+		termination.ExitCode = terminated.ExitCode
+		termination.FinishedAtEpoch = terminated.FinishedAt.UnixMilli()
+	}
+	if termination.Reason == "" {
+		termination.Reason = "Deleted"
+	}
+	return termination
+}
+
+// driverTerminatedState returns the last terminated state of the driver container, if any.
+func driverTerminatedState(pod *corev1.Pod) *corev1.ContainerStateTerminated {
+	status := driverContainerStatus(pod)
+	if status == nil {
+		return nil
+	}
+	if status.State.Terminated != nil {
+		return status.State.Terminated
+	}
+	return status.LastTerminationState.Terminated
+}
+
+// driverContainerStatus returns the status of the driver container (the first
+// container if there is no spark-kubernetes-driver container), if any.
+func driverContainerStatus(pod *corev1.Pod) *corev1.ContainerStatus {
+	return sparkContainerStatus(pod, sparkDriverContainer)
+}
+
+// sparkContainerStatus returns the status of the named container (the first
+// container if there is no such container), if any.
+func sparkContainerStatus(pod *corev1.Pod, name string) *corev1.ContainerStatus {
+	var status *corev1.ContainerStatus
+	for i := range pod.Status.ContainerStatuses {
+		if i == 0 || pod.Status.ContainerStatuses[i].Name == name {
+			status = &pod.Status.ContainerStatuses[i]
+		}
+	}
+	return status
+}
@@ -0,0 +1,159 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package discovery
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/okdp/spark-web-proxy/internal/config"
+	log "github.com/okdp/spark-web-proxy/internal/logging"
+	"github.com/okdp/spark-web-proxy/internal/model"
+	"github.com/okdp/spark-web-proxy/internal/store"
+)
+
+func Test_TombstoneSparkAppsFromPod(t *testing.T) {
+	// Given: a driver pod killed by the OOM killer
+	log.SetupGlobalLogger(config.Logging{Level: "info"})
+	finishedAt := time.Date(2026, 5, 1, 10, 30, 0, 0, time.UTC)
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "spark-pi-driver", Namespace: "spark"},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{
+			Name: "spark-kubernetes-driver",
+			Env:  []corev1.EnvVar{{Name: "SPARK_APPLICATION_ID", Value: "spark-1"}},
+		}}},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodFailed,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name: "spark-kubernetes-driver",
+				State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+					Reason:     "OOMKilled",
+					ExitCode:   137,
+					FinishedAt: metav1.NewTime(finishedAt),
+				}},
+			}},
+		},
+	}
+	sparkApps := store.NewMemoryStore()
+	resolver := NewSparkAppResolver(sparkApps)
+	_, _ = resolver.ResolveSparkAppFromPod("default", pod)
+
+	// When
+	tombstones := resolver.TombstoneSparkAppsFromPod("default", pod)
+
+	// Then
+	assert.Len(t, tombstones, 1, "tombstones")
+	tombstone, found := sparkApps.Get("spark-1")
+	assert.True(t, found, "tombstone stored")
+	assert.True(t, tombstone.IsTombstone(), "IsTombstone")
+	assert.Equal(t, string(model.AppFailed), tombstone.Status, "Status")
+	assert.Equal(t, "OOMKilled", tombstone.Termination.Reason, "Reason")
+	assert.Equal(t, int32(137), tombstone.Termination.ExitCode, "ExitCode")
+	assert.Equal(t, finishedAt.UnixMilli(), tombstone.Termination.FinishedAtEpoch, "FinishedAtEpoch")
+}
+
+func Test_ResolveGhostSparkApps(t *testing.T) {
+	// Given: a spark history listing the application spark-2 only
+	log.SetupGlobalLogger(config.Logging{Level: "info"})
+	var query string
+	listings := 0
+	sparkHistory := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		listings++
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"id":"spark-2","name":"logged","attempts":[{"completed":true}]}]`))
+	}))
+	defer sparkHistory.Close()
+
+	startedAt := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC).UnixMilli()
+	deletedAt := time.Now().Add(-time.Hour).UnixMilli()
+	sparkApps := store.NewMemoryStore()
+	for _, tombstone := range []*model.SparkAppInstance{
+		{AppID: "spark-1", StartTimeEpoch: startedAt, Termination: &model.Termination{Reason: "OOMKilled", DeletedAtEpoch: deletedAt}},
+		{AppID: "spark-2", StartTimeEpoch: startedAt, Termination: &model.Termination{Reason: "Completed", DeletedAtEpoch: deletedAt}},
+		{AppID: "spark-3", StartTimeEpoch: startedAt, Termination: &model.Termination{Reason: "Completed", DeletedAtEpoch: time.Now().UnixMilli()}},
+	} {
+		tombstone.Source = model.SourceTombstone
+		sparkApps.Put(tombstone)
+	}
+	sparkApps.Put(&model.SparkAppInstance{AppID: "spark-4", Status: "Running", Source: model.SourcePod})
+	resolver := NewSparkAppResolver(sparkApps)
+	request := httptest.NewRequest(http.MethodGet, "/api/v1/applications?status=running", nil)
+
+	// When
+	ghosts, err := resolver.ResolveGhostSparkApps(request, sparkHistory.URL, time.Minute)
+
+	// Then: all the applications started since the day before the oldest tombstone are listed
+	assert.NoError(t, err)
+	assert.Equal(t, "minDate=2026-04-30", query, "query")
+
+	// Then: spark-2 reached spark history and spark-3 is in its grace period
+	assert.Len(t, ghosts, 1, "ghosts")
+	assert.Equal(t, "spark-1", ghosts[0].AppID, "ghost")
+	_, found := sparkApps.Get("spark-2")
+	assert.False(t, found, "logged tombstone removed")
+	_, found = sparkApps.Get("spark-3")
+	assert.True(t, found, "tombstone in grace period kept")
+
+	// When: the incomplete applications are requested again
+	ghosts, err = resolver.ResolveGhostSparkApps(request, sparkHistory.URL, time.Minute)
+
+	// Then: the spark history listing is cached
+	assert.NoError(t, err)
+	assert.Len(t, ghosts, 1, "ghosts")
+	assert.Equal(t, 1, listings, "listings")
+}
+
+func Test_ResolveGhostSparkApps_Unknown_Start_Time(t *testing.T) {
+	// Given: a tombstone without start time, listed by spark history
+	log.SetupGlobalLogger(config.Logging{Level: "info"})
+	query := "unset"
+	sparkHistory := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"id":"spark-1","name":"logged","attempts":[{"completed":true}]}]`))
+	}))
+	defer sparkHistory.Close()
+
+	startedAt := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC).UnixMilli()
+	deletedAt := time.Now().Add(-time.Hour).UnixMilli()
+	sparkApps := store.NewMemoryStore()
+	for _, tombstone := range []*model.SparkAppInstance{
+		{AppID: "spark-1", Termination: &model.Termination{Reason: "Completed", DeletedAtEpoch: deletedAt}},
+		{AppID: "spark-2", StartTimeEpoch: startedAt, Termination: &model.Termination{Reason: "OOMKilled", DeletedAtEpoch: deletedAt}},
+	} {
+		tombstone.Source = model.SourceTombstone
+		sparkApps.Put(tombstone)
+	}
+	resolver := NewSparkAppResolver(sparkApps)
+	request := httptest.NewRequest(http.MethodGet, "/api/v1/applications?status=running", nil)
+
+	// When
+	ghosts, err := resolver.ResolveGhostSparkApps(request, sparkHistory.URL, time.Minute)
+
+	// Then: all the applications are listed and spark-1 is not a ghost
+	assert.NoError(t, err)
+	assert.Empty(t, query, "query")
+	assert.Len(t, ghosts, 1, "ghosts")
+	assert.Equal(t, "spark-2", ghosts[0].AppID, "ghost")
+}
@@ -0,0 +1,162 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package discovery
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/okdp/spark-web-proxy/internal/constants"
+	sparkclient "github.com/okdp/spark-web-proxy/internal/discovery/resolvers/rest"
+	log "github.com/okdp/spark-web-proxy/internal/logging"
+	"github.com/okdp/spark-web-proxy/internal/model"
+	"github.com/okdp/spark-web-proxy/internal/store"
+	"github.com/okdp/spark-web-proxy/internal/utils"
+)
+
+// Spark properties changing the root of the links generated by the Spark UI.
+const (
+	sparkUIReverseProxy    = "spark.ui.reverseProxy"
+	sparkUIReverseProxyURL = "spark.ui.reverseProxyUrl"
+	sparkUIProxyBase       = "spark.ui.proxyBase"
+)
+
+// sparkUIEnvironmentTimeout is the timeout of the environment request to the Spark UI.
+const sparkUIEnvironmentTimeout = 5 * time.Second
+
+// sparkUISettingsResolutions holds the applications whose Spark UI settings are being resolved.
+var sparkUISettingsResolutions sync.Map
+
+// sparkUISettings returns the Spark UI settings read using the given Spark
+// property lookup, and nil if none of the properties is set.
+func sparkUISettings(property func(key string) (string, bool)) *model.SparkUISettings {
+	reverseProxy, reverseProxyFound := property(sparkUIReverseProxy)
+	reverseProxyURL, reverseProxyURLFound := property(sparkUIReverseProxyURL)
+	proxyBase, proxyBaseFound := property(sparkUIProxyBase)
+	if !reverseProxyFound && !reverseProxyURLFound && !proxyBaseFound {
+		return nil
+	}
+
+	settings := &model.SparkUISettings{}
+	settings.ReverseProxy, _ = strconv.ParseBool(strings.TrimSpace(reverseProxy))
+	if reverseProxyURLFound {
+		settings.ReverseProxyURL = strings.TrimSpace(reverseProxyURL)
+	}
+	if proxyBaseFound {
+		settings.ProxyBase = strings.TrimSpace(proxyBase)
+	}
+	return settings
+}
+
+// sparkUISettingsFromPod returns the Spark UI settings of a driver pod, read from
+// the spark-web-proxy.okdp.io/ui-reverse-proxy and ui-proxy-base annotations, or
+// from the Spark configuration passed to its containers. It returns nil if the
+// settings are not found.
+func sparkUISettingsFromPod(pod *corev1.Pod) *model.SparkUISettings {
+	return sparkUISettings(func(key string) (string, bool) {
+		annotation := ""
+		switch key {
+		case sparkUIReverseProxy:
+			annotation = constants.AnnotationUIReverseProxy
+		case sparkUIProxyBase:
+			annotation = constants.AnnotationUIProxyBase
+		}
+		if value, found := pod.Annotations[annotation]; found && annotation != "" {
+			return value, true
+		}
+		return utils.GetSparkConf(pod, key)
+	})
+}
+
+// sparkUISettingsFromEnvironment returns the Spark UI settings of the Spark
+// properties of an application environment, and nil if none is set.
+func sparkUISettingsFromEnvironment(sparkAppEnv *model.SparkAppEnvironment) *model.SparkUISettings {
+	return sparkUISettings(sparkAppEnv.GetProperty)
+}
+
+// resolveSparkUISettingsAsync asynchronously resolves the Spark UI settings of
+// an application, unless they are already being resolved. It is called again on
+// the heartbeats and polls of the application while its settings are unknown.
+func resolveSparkUISettingsAsync(sparkApps store.Store, appID string) {
+	if _, inProgress := sparkUISettingsResolutions.LoadOrStore(appID, true); inProgress {
+		return
+	}
+
+	go func() {
+		defer sparkUISettingsResolutions.Delete(appID)
+		resolveSparkUISettings(sparkApps, appID)
+	}()
+}
+
+// resolveSparkUISettings reads the Spark UI settings of a running application
+// from the environment endpoint of its Spark UI, when they were not detected
+// from its driver pod or its event log, and records them in the store. The
+// request is retried while the Spark UI is starting.
+func resolveSparkUISettings(sparkApps store.Store, appID string) {
+	var settings *model.SparkUISettings
+	err := wait.ExponentialBackoffWithContext(context.Background(), sparkAppIDBackoff, func(context.Context) (bool, error) {
+		sparkApp, found := sparkApps.Get(appID)
+		if !found || sparkApp.UI != nil || sparkApp.BaseURL == "" || !sparkApp.IsRunning() {
+			return true, nil
+		}
+
+		sparkClient, err := sparkclient.NewSparkUIRestClient(sparkApp.BaseURL, SparkUITransport(sparkApp), sparkUIEnvironmentTimeout)
+		if err != nil {
+			return false, err
+		}
+		sparkAppEnv, err := sparkClient.GetEnvironment(appID)
+		if err != nil {
+			log.Debug("Unable to read the spark ui settings of the application '%s' at %s: %v", appID, sparkApp.BaseURL, err)
+			return false, nil
+		}
+
+		// Empty settings record that the Spark UI follows the X-Forwarded-Context header
+		settings = sparkUISettingsFromEnvironment(sparkAppEnv)
+		if settings == nil {
+			settings = &model.SparkUISettings{}
+		}
+		return true, nil
+	})
+	if err != nil {
+		log.Warn("Unable to read the spark ui settings of the application '%s': %v", appID, err)
+		return
+	}
+	if settings == nil {
+		return
+	}
+
+	// The application may have changed during the request
+	sparkApp, updated := sparkApps.Update(appID, func(app *model.SparkAppInstance) bool {
+		if app.UI != nil {
+			return false
+		}
+		app.UI = settings
+		return true
+	})
+	if !updated {
+		return
+	}
+	if root := sparkApp.UIRoot(); root != "" {
+		log.Info("The spark ui of the application '%s' generates its links under %s", appID, root)
+	}
+}
@@ -0,0 +1,151 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package discovery
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/okdp/spark-web-proxy/internal/config"
+	"github.com/okdp/spark-web-proxy/internal/constants"
+	log "github.com/okdp/spark-web-proxy/internal/logging"
+	"github.com/okdp/spark-web-proxy/internal/model"
+	"github.com/okdp/spark-web-proxy/internal/store"
+)
+
+func Test_SparkUISettingsFromPod(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		args        []string
+		root        string
+	}{
+		{
+			name: "No settings",
+			root: "",
+		},
+		{
+			name: "Reverse proxy argument",
+			args: []string{"--conf", "spark.ui.reverseProxy=true", "--conf", "spark.ui.reverseProxyUrl=https://gateway.example.com/spark/"},
+			root: "/spark/proxy/spark-1",
+		},
+		{
+			name: "Proxy base argument",
+			args: []string{"--conf", "spark.ui.proxyBase=/notebooks/alice/"},
+			root: "/notebooks/alice",
+		},
+		{
+			name:        "Annotations override the arguments",
+			annotations: map[string]string{constants.AnnotationUIReverseProxy: "true"},
+			args:        []string{"--conf", "spark.ui.proxyBase=/notebooks/alice"},
+			root:        "/proxy/spark-1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "spark-1-driver", Annotations: tt.annotations},
+				Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "spark-kubernetes-driver", Args: tt.args}}},
+			}
+
+			// When
+			settings := sparkUISettingsFromPod(pod)
+
+			// Then
+			assert.Equal(t, tt.root, settings.Root("spark-1"), "Root")
+		})
+	}
+}
+
+func Test_ResolveSparkUISettings(t *testing.T) {
+	// Given: a running application whose Spark UI runs with spark.ui.reverseProxy
+	log.SetupGlobalLogger(config.Logging{Level: "info"})
+	sparkUI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/applications/spark-1/environment", r.URL.Path, "environment path")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"sparkProperties":[["spark.app.id","spark-1"],["spark.ui.reverseProxy","true"]]}`))
+	}))
+	defer sparkUI.Close()
+
+	sparkApps := store.NewMemoryStore()
+	sparkApps.Put(&model.SparkAppInstance{AppID: "spark-1", BaseURL: sparkUI.URL, Status: string(model.AppRunning)})
+
+	// When
+	resolveSparkUISettings(sparkApps, "spark-1")
+
+	// Then
+	sparkApp, _ := sparkApps.Get("spark-1")
+	assert.Equal(t, "/proxy/spark-1", sparkApp.UIRoot(), "UIRoot")
+
+	// Given: an application whose Spark UI follows the X-Forwarded-Context header
+	defaultSparkUI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"sparkProperties":[["spark.app.id","spark-2"]]}`))
+	}))
+	defer defaultSparkUI.Close()
+	sparkApps.Put(&model.SparkAppInstance{AppID: "spark-2", BaseURL: defaultSparkUI.URL, Status: string(model.AppRunning)})
+
+	// When
+	resolveSparkUISettings(sparkApps, "spark-2")
+
+	// Then: the settings are recorded as empty so that they are not requested again
+	sparkApp, _ = sparkApps.Get("spark-2")
+	if assert.NotNil(t, sparkApp.UI, "UI") {
+		assert.Equal(t, "", sparkApp.UIRoot(), "UIRoot")
+	}
+}
+
+func Test_ResolveSparkUISettings_Retry(t *testing.T) {
+	// Given: a registered application whose Spark UI is still starting
+	log.SetupGlobalLogger(config.Logging{Level: "info"})
+	withFastSparkAppIDBackoff(t)
+	var requests atomic.Int32
+	sparkUI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if requests.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"sparkProperties":[["spark.app.id","spark-retry"],["spark.ui.proxyBase","/notebooks/alice"]]}`))
+	}))
+	defer sparkUI.Close()
+
+	sparkApps := store.NewMemoryStore()
+	sparkApps.Put(&model.SparkAppInstance{AppID: "spark-retry", BaseURL: sparkUI.URL, Status: string(model.AppRunning)})
+
+	// When
+	resolveSparkUISettingsAsync(sparkApps, "spark-retry")
+	resolveSparkUISettingsAsync(sparkApps, "spark-retry")
+
+	// Then: the settings are read once the Spark UI answers
+	assert.Eventually(t, func() bool {
+		sparkApp, _ := sparkApps.Get("spark-retry")
+		return sparkApp.UI != nil
+	}, 5*time.Second, 10*time.Millisecond, "UI")
+	sparkApp, _ := sparkApps.Get("spark-retry")
+	assert.Equal(t, "/notebooks/alice", sparkApp.UIRoot(), "UIRoot")
+	assert.Equal(t, int32(3), requests.Load(), "requests")
+}
@@ -0,0 +1,79 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package model
+
+// ExecutorPod is the Kubernetes state of the pod of a Spark executor, tracked
+// by the spark-app-selector and spark-exec-id labels set by the driver.
+type ExecutorPod struct {
+	AppID      string `json:"appId"`
+	ExecutorID string `json:"executorId"`
+	Cluster    string `json:"cluster"`
+	Namespace  string `json:"namespace"`
+	PodName    string `json:"podName"`
+	// Phase is the pod phase (e.g. Pending, Running, Failed).
+	Phase    string `json:"phase"`
+	NodeName string `json:"nodeName,omitempty"`
+	PodIP    string `json:"podIp,omitempty"`
+	// State is the state of the executor container (e.g. "Waiting: ImagePullBackOff").
+	State string `json:"state,omitempty"`
+	Ready bool   `json:"ready"`
+	// Restarts is the restart count of the executor container.
+	Restarts int32 `json:"restarts"`
+	// Reason and Message are the pod status reason and message (e.g. Evicted, Unschedulable).
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+	// Termination is the last termination of the executor container (e.g. OOMKilled), if any.
+	Termination *Termination `json:"termination,omitempty"`
+	// Requests and Limits are the resources of the executor container (e.g. cpu, memory).
+	Requests map[string]string `json:"requests,omitempty"`
+	Limits   map[string]string `json:"limits,omitempty"`
+	// CreatedAtEpoch is the pod creation time in milliseconds.
+	CreatedAtEpoch int64 `json:"createdAtEpoch"`
+	// DeletedAtEpoch is the pod deletion time in milliseconds, or 0 while the pod exists.
+	DeletedAtEpoch int64 `json:"deletedAtEpoch,omitempty"`
+}
+
+// SparkExecutor represents an executor as returned by the Spark UI REST API
+// (/applications/[app-id]/allexecutors).
+type SparkExecutor struct {
+	ID             string `json:"id"`
+	HostPort       string `json:"hostPort,omitempty"`
+	IsActive       bool   `json:"isActive"`
+	TotalCores     int    `json:"totalCores"`
+	MaxTasks       int    `json:"maxTasks"`
+	ActiveTasks    int    `json:"activeTasks"`
+	FailedTasks    int    `json:"failedTasks"`
+	CompletedTasks int    `json:"completedTasks"`
+	TotalTasks     int    `json:"totalTasks"`
+	TotalDuration  int64  `json:"totalDuration"`
+	TotalGCTime    int64  `json:"totalGCTime"`
+	MemoryUsed     int64  `json:"memoryUsed"`
+	MaxMemory      int64  `json:"maxMemory"`
+	DiskUsed       int64  `json:"diskUsed"`
+	AddTime        string `json:"addTime,omitempty"`
+	RemoveTime     string `json:"removeTime,omitempty"`
+	RemoveReason   string `json:"removeReason,omitempty"`
+}
+
+// Executor is an executor of a Spark application, joining the executor reported
+// by the driver with the state of its pod. Either side may be missing: the driver
+// has no pod, and a pending executor pod is not registered with the driver yet.
+type Executor struct {
+	ID    string         `json:"id"`
+	Spark *SparkExecutor `json:"spark,omitempty"`
+	Pod   *ExecutorPod   `json:"pod,omitempty"`
+}
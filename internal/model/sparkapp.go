@@ -24,6 +24,9 @@ type SparkApp struct {
 	ID       string            `json:"id,omitempty"`
 	Name     string            `json:"name,omitempty"`
 	Attempts []SparkAppAttempt `json:"attempts,omitempty"`
+	// Termination is the driver pod termination of the applications whose event
+	// logs never reached Spark History (set by the proxy only).
+	Termination *Termination `json:"termination,omitempty"`
 }
 
 // SparkAppAttempt represents a single execution attempt of a Spark application.
@@ -18,118 +18,384 @@
 package model
 
 import (
-	"sync"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
 )
 
 // SparkAppInstance represents a running or completed Spark application
 // discovered either from Kubernetes or the Spark History Server.
 type SparkAppInstance struct {
-	BaseURL        string
-	PodName        string
-	AppID          string
-	Namespace      string
-	Status         string
-	StartTimeEpoch int64
-}
-
-// SparkAppsStore holds a concurrent map of Spark applications, keyed by appId.
-var (
-	SparkAppsStore = struct {
-		Instances sync.Map
-	}{}
+	BaseURL        string `json:"baseUrl,omitempty"`
+	PodName        string `json:"podName,omitempty"`
+	AppID          string `json:"appId"`
+	Namespace      string `json:"namespace,omitempty"`
+	Status         string `json:"status"`
+	StartTimeEpoch int64  `json:"startTimeEpoch,omitempty"`
+	// AppName is the display name of the Spark application, when known.
+	AppName string `json:"appName,omitempty"`
+	// Cluster is the name of the Kubernetes cluster where the driver runs.
+	Cluster string `json:"cluster,omitempty"`
+	// PodPhase is the phase of the driver pod, when the driver pod was discovered.
+	PodPhase string `json:"podPhase,omitempty"`
+	// PodUID is the UID of the driver pod, when the driver pod was discovered.
+	PodUID string `json:"podUid,omitempty"`
+	// CRName is the name of the Spark Operator SparkApplication custom resource.
+	CRName string `json:"crName,omitempty"`
+	// ScheduledCRName is the name of the owning ScheduledSparkApplication, if any.
+	ScheduledCRName string `json:"scheduledCrName,omitempty"`
+	// ApplicationState is the Spark Operator reported application state.
+	ApplicationState string `json:"applicationState,omitempty"`
+	// UIServiceName is the Kubernetes service exposing the driver UI.
+	UIServiceName string `json:"uiServiceName,omitempty"`
+	// User is the user running the Spark application (sparkUser), when known.
+	User string `json:"user,omitempty"`
+	// Source is where the Spark application instance was discovered from.
+	Source string `json:"source,omitempty"`
+	// StatusOverride reports whether the status was set by the proxy (e.g. unreachable
+	// driver UI) rather than by the source of the application.
+	StatusOverride bool `json:"statusOverride,omitempty"`
+	// Termination describes how the driver pod terminated, for the tombstones.
+	Termination *Termination `json:"termination,omitempty"`
+	// Transitions are the lifecycle state transitions, oldest first.
+	Transitions []StateTransition `json:"transitions,omitempty"`
+	// DriverState is the state of the driver container (e.g. "Waiting: ContainerCreating").
+	DriverState string `json:"driverState,omitempty"`
+	// DriverReady reports whether the driver container is ready.
+	DriverReady bool `json:"driverReady,omitempty"`
+	// DriverRestarts is the number of restarts of the driver container.
+	DriverRestarts int32 `json:"driverRestarts,omitempty"`
+	// Health is the result of the driver UI health probes, when probed.
+	Health *Health `json:"health,omitempty"`
+	// Labels are the labels given by the launcher of a registered application.
+	Labels map[string]string `json:"labels,omitempty"`
+	// LastHeartbeatEpoch is the time of the last registration of a registered
+	// application in milliseconds.
+	LastHeartbeatEpoch int64 `json:"lastHeartbeatEpoch,omitempty"`
+	// Registrant is the fingerprint of the token which registered a registered
+	// application, the only one allowed to register it again or to unregister it.
+	// It is not exposed by the API, and is persisted and shared by the stores.
+	Registrant string `json:"-"`
+	// Events are the recent Kubernetes events of the driver and executor pods, oldest first.
+	Events []PodEvent `json:"events,omitempty"`
+	// UI holds the settings of the Spark UI changing the root of its links, when detected.
+	UI *SparkUISettings `json:"ui,omitempty"`
+}
+
+// SparkUISettings are the settings of a Spark UI changing the root of the links
+// it generates (spark.ui.reverseProxy, spark.ui.reverseProxyUrl and spark.ui.proxyBase).
+type SparkUISettings struct {
+	ReverseProxy    bool   `json:"reverseProxy,omitempty"`
+	ReverseProxyURL string `json:"reverseProxyUrl,omitempty"`
+	ProxyBase       string `json:"proxyBase,omitempty"`
+}
+
+// Root returns the path prefix of the links generated by the Spark UI of the
+// given application, or "" when the Spark UI follows the X-Forwarded-Context
+// header. With spark.ui.reverseProxy, the driver sets spark.ui.proxyBase to
+// the path of spark.ui.reverseProxyUrl followed by /proxy/<app-id>.
+func (s *SparkUISettings) Root(appID string) string {
+	switch {
+	case s == nil:
+		return ""
+	case s.ReverseProxy:
+		reverseProxyPath := s.ReverseProxyURL
+		if reverseProxyURL, err := url.Parse(s.ReverseProxyURL); err == nil {
+			reverseProxyPath = reverseProxyURL.Path
+		}
+		return strings.TrimSuffix(reverseProxyPath, "/") + "/proxy/" + appID
+	case strings.Trim(s.ProxyBase, "/") != "":
+		return "/" + strings.Trim(s.ProxyBase, "/")
+	default:
+		return ""
+	}
+}
+
+// PodEvent is a Kubernetes event of the driver pod or of an executor pod of a
+// Spark application (e.g. FailedScheduling, BackOff, Evicted).
+type PodEvent struct {
+	PodName string `json:"podName"`
+	// ExecutorID is the executor ID of the events of an executor pod, empty for the driver pod.
+	ExecutorID string `json:"executorId,omitempty"`
+	// Type is the event type (Normal or Warning).
+	Type    string `json:"type"`
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+	// Count is the number of occurrences of the event.
+	Count int32 `json:"count"`
+	// FirstSeenEpoch and LastSeenEpoch are the times of the first and last
+	// occurrences of the event in milliseconds.
+	FirstSeenEpoch int64 `json:"firstSeenEpoch"`
+	LastSeenEpoch  int64 `json:"lastSeenEpoch"`
+}
+
+// EventTypeWarning is the type of the Kubernetes events reporting a problem.
+const EventTypeWarning = "Warning"
+
+// Health is the result of the health probes of the driver UI of a Spark application.
+type Health struct {
+	// Reachable reports whether the last probe succeeded.
+	Reachable bool `json:"reachable"`
+	// LatencyMillis is the duration of the last probe in milliseconds.
+	LatencyMillis int64 `json:"latencyMillis"`
+	// LastProbeEpoch is the time of the last probe in milliseconds.
+	LastProbeEpoch int64 `json:"lastProbeEpoch"`
+	// LastSuccessEpoch is the time of the last successful probe in milliseconds, or 0.
+	LastSuccessEpoch int64 `json:"lastSuccessEpoch,omitempty"`
+	// ConsecutiveFailures is the number of failed probes since the last success.
+	ConsecutiveFailures int `json:"consecutiveFailures,omitempty"`
+	// LastError is the error of the last failed probe.
+	LastError string `json:"lastError,omitempty"`
+}
+
+// StateTransition records when a Spark application entered a lifecycle state.
+type StateTransition struct {
+	Status    string `json:"status"`
+	TimeEpoch int64  `json:"timeEpoch"`
+}
+
+// maxStateTransitions is the maximum number of transitions recorded for an application.
+const maxStateTransitions = 16
+
+// Termination describes the termination of the driver pod of a Spark application.
+type Termination struct {
+	// Reason is the reason of the driver container termination (e.g. OOMKilled, Error),
+	// or of the pod eviction when the container did not terminate.
+	Reason string `json:"reason,omitempty"`
+	// Message is the termination message, if any.
+	Message string `json:"message,omitempty"`
+	// ExitCode is the exit code of the driver container, or -1 if it did not terminate.
+	ExitCode int32 `json:"exitCode"`
+	// FinishedAtEpoch is the driver container termination time in milliseconds, or -1.
+	FinishedAtEpoch int64 `json:"finishedAtEpoch"`
+	// DeletedAtEpoch is the driver pod deletion time in milliseconds.
+	DeletedAtEpoch int64 `json:"deletedAtEpoch"`
+}
+
+// Sources of the Spark application instances.
+const (
+	// SourcePod is a Spark application discovered from a Kubernetes driver pod.
+	SourcePod = "pod"
+	// SourceOperator is a Spark application discovered from a Spark Operator custom resource.
+	SourceOperator = "operator"
+	// SourceHistory is a Spark application resolved from the Spark History Server.
+	SourceHistory = "history"
+	// SourceEventLog is a Spark application discovered from its in-progress event log.
+	SourceEventLog = "eventlog"
+	// SourceStandalone is a Spark application discovered from a Spark standalone master.
+	SourceStandalone = "standalone"
+	// SourceRegistration is a Spark application registered through the registration API.
+	SourceRegistration = "registration"
+	// SourceProxy is a Spark application only known from a status set by the proxy
+	// (e.g. unreachable driver UI of an application missing from the store).
+	SourceProxy = "proxy"
+	// SourceTombstone is a Spark application whose driver pod was deleted, kept until
+	// it is listed by the Spark History Server.
+	SourceTombstone = "tombstone"
 )
 
-// IsRunning reports whether the Spark application is currently running.
+// SparkHistoryTimeFormat is the date format of the Spark History Server REST API.
+const SparkHistoryTimeFormat = "2006-01-02T15:04:05.000GMT"
+
+// IsRunning reports whether the Spark UI of the application is serving
+// (UIReady or Running).
 func (app SparkAppInstance) IsRunning() bool {
-	return app.Status == string(AppRunning)
+	return app.Status == string(AppUIReady) || app.Status == string(AppRunning)
+}
+
+// IsStarting reports whether the Spark UI of the application is not serving yet
+// (Pending or Starting).
+func (app SparkAppInstance) IsStarting() bool {
+	return app.Status == string(AppPending) || app.Status == string(AppStarting)
 }
 
-// IsCompleted reports whether the Spark application has completed
-// (i.e., it is not in the running state).
+// IsCompleted reports whether the Spark application is no longer running
+// (Succeeded, Failed, Lost or Unknown).
 func (app SparkAppInstance) IsCompleted() bool {
-	return !app.IsRunning()
+	return SparkAppStatus(app.Status).IsCompleted()
 }
 
-// AddOrUpdateSparkApp adds a new SparkApp to the map or updates an existing one
-func AddOrUpdateSparkApp(app *SparkAppInstance) {
-	SparkAppsStore.Instances.Store(app.AppID, app)
+// TransitionTo moves the Spark application to the given lifecycle state if the
+// state machine allows it, and records the transition time. It reports whether
+// the state changed. A status override set by the proxy is cleared.
+func (app *SparkAppInstance) TransitionTo(status SparkAppStatus, at time.Time) bool {
+	if !SparkAppStatus(app.Status).CanTransitionTo(status) {
+		return false
+	}
+	app.Status = string(status)
+	app.StatusOverride = false
+
+	// The transitions may be shared with a copy of the application
+	transitions := make([]StateTransition, 0, len(app.Transitions)+1)
+	transitions = append(transitions, app.Transitions...)
+	transitions = append(transitions, StateTransition{Status: string(status), TimeEpoch: at.UnixMilli()})
+	if len(transitions) > maxStateTransitions {
+		transitions = transitions[len(transitions)-maxStateTransitions:]
+	}
+	app.Transitions = transitions
+	return true
 }
 
-// MakeSparkAppCompleted updates SparkApp to AppUnknown status
-func MakeSparkAppCompleted(appID string) {
-	app, found := GetSparkApp(appID)
-	if found {
-		app.Status = string(AppUnknown)
-	} else {
-		app = &SparkAppInstance{
-			AppID:  appID,
-			Status: string(AppUnknown),
+// StartedAt returns the time the application last entered the Starting state,
+// or the time of its first transition if it never did, and false if no
+// transition was recorded.
+func (app SparkAppInstance) StartedAt() (time.Time, bool) {
+	for i := len(app.Transitions) - 1; i >= 0; i-- {
+		if app.Transitions[i].Status == string(AppStarting) {
+			return time.UnixMilli(app.Transitions[i].TimeEpoch), true
 		}
 	}
+	if len(app.Transitions) != 0 {
+		return time.UnixMilli(app.Transitions[0].TimeEpoch), true
+	}
+	return time.Time{}, false
+}
 
-	SparkAppsStore.Instances.Store(appID, app)
+// InheritState copies the lifecycle state (including a status override),
+// transitions, health and events of a previously observed instance of the
+// application, and its Spark UI settings when they are not known.
+func (app *SparkAppInstance) InheritState(previous *SparkAppInstance) {
+	app.Status = previous.Status
+	app.StatusOverride = previous.StatusOverride
+	app.Transitions = previous.Transitions
+	app.Health = previous.Health
+	app.Events = previous.Events
+	if app.UI == nil {
+		app.UI = previous.UI
+	}
 }
 
-// DeleteSparkApp removes a SparkApp from the map
-func DeleteSparkApp(appID string) {
-	SparkAppsStore.Instances.Delete(appID)
+// UIRoot returns the path prefix of the links generated by the Spark UI of the
+// application, or "" when the Spark UI follows the X-Forwarded-Context header.
+func (app SparkAppInstance) UIRoot() string {
+	return app.UI.Root(app.AppID)
 }
 
-// DeleteSparkAppByName removes a Spark application from the map by its PodName
-// and returns the deleted SparkApp.
-//
-// It iterates over the sync.Map, finds the matching SparkApp by PodName,
-// deletes the entry, and returns the deleted SparkApp.
-//
-// Parameters:
-//   - podName: The name of the pod to be removed.
-//
-// Returns:
-//   - (SparkApp, bool): The deleted SparkApp and a boolean indicating success.
-//
-// Example usage:
-//
-//	deletedApp, found := DeleteSparkAppByPodName("spark-pod-123")
-//	if found {
-//	    fmt.Println("Deleted SparkApp:", deletedApp)
-//	}
-func DeleteSparkAppByName(podName string) (*SparkAppInstance, bool) {
-	var deletedApp *SparkAppInstance
-	var found bool
-
-	SparkAppsStore.Instances.Range(func(key, value interface{}) bool {
-		if app, ok := value.(*SparkAppInstance); ok && app.PodName == podName {
-			deletedApp = app
-			found = true
-			SparkAppsStore.Instances.Delete(key)
+// RecordEvent adds a pod event to the recent events of the application, or
+// updates the occurrences of the same event of the same pod, keeping the
+// maxEvents most recent events. It reports whether the events changed.
+func (app *SparkAppInstance) RecordEvent(event PodEvent, maxEvents int) bool {
+	// The events may be shared with a copy of the application
+	events := make([]PodEvent, 0, len(app.Events)+1)
+	for _, existing := range app.Events {
+		if existing.PodName != event.PodName || existing.Type != event.Type ||
+			existing.Reason != event.Reason || existing.Message != event.Message {
+			events = append(events, existing)
+			continue
+		}
+		if existing.Count == event.Count && existing.LastSeenEpoch == event.LastSeenEpoch {
 			return false
 		}
-		return true
-	})
+		event.FirstSeenEpoch = min(existing.FirstSeenEpoch, event.FirstSeenEpoch)
+	}
+	events = append(events, event)
 
-	return deletedApp, found
+	sort.SliceStable(events, func(i, j int) bool { return events[i].LastSeenEpoch < events[j].LastSeenEpoch })
+	if len(events) > maxEvents {
+		events = events[len(events)-maxEvents:]
+	}
+	app.Events = events
+	return true
 }
 
-// GetSparkApp retrieves a SparkApp from the map by appID
-func GetSparkApp(appID string) (*SparkAppInstance, bool) {
-	value, exists := SparkAppsStore.Instances.Load(appID)
-	if exists {
-		return value.(*SparkAppInstance), exists
+// IsManagedByOperator reports whether the Spark application was submitted
+// through the Spark Operator.
+func (app SparkAppInstance) IsManagedByOperator() bool {
+	return app.CRName != ""
+}
+
+// MergeOperatorState copies the Spark Operator custom resource fields from other.
+// Once the operator reports a final state, it takes precedence over the driver
+// pod phase as the application status.
+func (app *SparkAppInstance) MergeOperatorState(other *SparkAppInstance) {
+	if !other.IsManagedByOperator() {
+		return
+	}
+	app.CRName = other.CRName
+	app.ScheduledCRName = other.ScheduledCRName
+	app.ApplicationState = other.ApplicationState
+	app.UIServiceName = other.UIServiceName
+
+	state := SparkOperatorAppState(other.ApplicationState)
+	if state.IsTerminal() {
+		app.TransitionTo(state.Status(), time.Now())
 	}
-	return &SparkAppInstance{}, false
 }
 
-// GetRunningSparkApps retrieves all SparkApps from the map
-func GetRunningSparkApps() []*SparkAppInstance {
-	apps := make([]*SparkAppInstance, 0)
-	SparkAppsStore.Instances.Range(func(_, value interface{}) bool {
-		app := value.(*SparkAppInstance)
-		if app != nil && app.IsRunning() {
-			apps = append(apps, app)
+// PodStateChanged reports whether the driver pod observed by the application differs
+// from the driver pod observed by its previous instance: another pod, a restarted
+// driver container, or another pod phase or driver container state.
+func (app SparkAppInstance) PodStateChanged(previous *SparkAppInstance) bool {
+	return app.PodUID != previous.PodUID ||
+		app.DriverRestarts != previous.DriverRestarts ||
+		app.PodPhase != previous.PodPhase ||
+		app.DriverState != previous.DriverState ||
+		app.DriverReady != previous.DriverReady
+}
+
+// IsTombstone reports whether the Spark application driver pod was deleted.
+func (app SparkAppInstance) IsTombstone() bool {
+	return app.Source == SourceTombstone
+}
+
+// GhostSparkApp returns the Spark History Server representation of a tombstone
+// whose event logs never reached Spark History. The attempt is reported as not
+// completed so that it is listed in the "incomplete applications" page, and the
+// driver termination is appended to the application name.
+func (app SparkAppInstance) GhostSparkApp() SparkApp {
+	name := app.AppName
+	if name == "" {
+		name = app.AppID
+	}
+
+	attempt := SparkAppAttempt{
+		SparkUser:      app.User,
+		StartTimeEpoch: app.StartTimeEpoch,
+		EndTimeEpoch:   -1,
+	}
+	if app.StartTimeEpoch > 0 {
+		attempt.StartTime = sparkHistoryTime(app.StartTimeEpoch)
+	}
+
+	if app.Termination != nil {
+		name = fmt.Sprintf("%s (driver %s, exit code %d)", name, app.Termination.Reason, app.Termination.ExitCode)
+		attempt.EndTimeEpoch = app.Termination.FinishedAtEpoch
+		if attempt.EndTimeEpoch <= 0 {
+			attempt.EndTimeEpoch = app.Termination.DeletedAtEpoch
+		}
+		attempt.EndTime = sparkHistoryTime(attempt.EndTimeEpoch)
+		attempt.LastUpdated = attempt.EndTime
+		attempt.LastUpdatedEpoch = attempt.EndTimeEpoch
+		if app.StartTimeEpoch > 0 {
+			attempt.Duration = attempt.EndTimeEpoch - app.StartTimeEpoch
 		}
-		return true
-	})
-	return apps
+	}
+
+	return SparkApp{
+		ID:          app.AppID,
+		Name:        name,
+		Attempts:    []SparkAppAttempt{attempt},
+		Termination: app.Termination,
+	}
+}
+
+// sparkHistoryTime formats a Unix epoch timestamp in milliseconds using the Spark History date format.
+func sparkHistoryTime(epoch int64) string {
+	return time.UnixMilli(epoch).UTC().Format(SparkHistoryTimeFormat)
+}
+
+// SparkOperatorAppKey returns the key used to store a SparkApplication custom
+// resource that was not assigned a Spark application ID yet.
+func SparkOperatorAppKey(cluster string, namespace string, name string) string {
+	return fmt.Sprintf("sparkoperator:%s/%s/%s", cluster, namespace, name)
+}
+
+// SparkPodAppKey returns the key used to store a Spark application discovered
+// from a driver pod whose Spark application ID is not known yet.
+func SparkPodAppKey(cluster string, namespace string, podName string) string {
+	return fmt.Sprintf("pod:%s/%s/%s", cluster, namespace, podName)
 }
 
 // GetProperty retrieves the value for the specified property name from the SparkProperties slice.
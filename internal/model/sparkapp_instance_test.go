@@ -18,6 +18,7 @@ package model
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -53,3 +54,113 @@ func TestGetProperty(t *testing.T) {
 		assert.Equal(t, "_", value, "The value should be _")
 	})
 }
+
+func TestGhostSparkApp(t *testing.T) {
+	// Given: a tombstone of a driver killed by the OOM killer
+	app := SparkAppInstance{
+		AppID:          "spark-1",
+		AppName:        "spark-pi",
+		User:           "alice",
+		StartTimeEpoch: 1777629600000,
+		Source:         SourceTombstone,
+		Termination:    &Termination{Reason: "OOMKilled", ExitCode: 137, FinishedAtEpoch: 1777631400000, DeletedAtEpoch: 1777631460000},
+	}
+
+	// When
+	ghost := app.GhostSparkApp()
+
+	// Then
+	assert.Equal(t, "spark-1", ghost.ID, "ID")
+	assert.Equal(t, "spark-pi (driver OOMKilled, exit code 137)", ghost.Name, "Name")
+	assert.Equal(t, int32(137), ghost.Termination.ExitCode, "ExitCode")
+	assert.Len(t, ghost.Attempts, 1, "Attempts")
+	assert.False(t, ghost.Attempts[0].Completed, "Completed")
+	assert.Equal(t, "alice", ghost.Attempts[0].SparkUser, "SparkUser")
+	assert.Equal(t, "2026-05-01T10:00:00.000GMT", ghost.Attempts[0].StartTime, "StartTime")
+	assert.Equal(t, "2026-05-01T10:30:00.000GMT", ghost.Attempts[0].EndTime, "EndTime")
+	assert.Equal(t, int64(1800000), ghost.Attempts[0].Duration, "Duration")
+}
+
+func TestTransitionTo(t *testing.T) {
+	// Given
+	app := &SparkAppInstance{AppID: "spark-1"}
+	at := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	// When / Then: the application moves forward
+	assert.True(t, app.TransitionTo(AppPending, at), "Pending")
+	assert.True(t, app.TransitionTo(AppStarting, at.Add(time.Second)), "Starting")
+	assert.True(t, app.IsStarting(), "IsStarting")
+	assert.True(t, app.TransitionTo(AppRunning, at.Add(2*time.Second)), "Running")
+	assert.True(t, app.IsRunning(), "IsRunning")
+
+	// When / Then: a late pod event does not move the application backward
+	assert.False(t, app.TransitionTo(AppStarting, at.Add(3*time.Second)), "back to Starting")
+	assert.Equal(t, string(AppRunning), app.Status, "Status")
+
+	// When / Then: the application is lost and recovers
+	assert.True(t, app.TransitionTo(AppLost, at.Add(4*time.Second)), "Lost")
+	assert.True(t, app.IsCompleted(), "IsCompleted")
+	app.StatusOverride = true
+	assert.True(t, app.TransitionTo(AppRunning, at.Add(5*time.Second)), "recovered")
+	assert.False(t, app.StatusOverride, "StatusOverride cleared")
+
+	// When / Then: the final states are never left
+	assert.True(t, app.TransitionTo(AppFailed, at.Add(6*time.Second)), "Failed")
+	assert.False(t, app.TransitionTo(AppLost, at.Add(7*time.Second)), "Lost after Failed")
+	assert.False(t, app.TransitionTo(AppRunning, at.Add(7*time.Second)), "Running after Failed")
+
+	// Then: the transitions are recorded
+	assert.Len(t, app.Transitions, 6, "Transitions")
+	assert.Equal(t, StateTransition{Status: "Starting", TimeEpoch: at.Add(time.Second).UnixMilli()}, app.Transitions[1], "Starting transition")
+	assert.Equal(t, "Failed", app.Transitions[5].Status, "last transition")
+}
+
+func TestRecordEvent(t *testing.T) {
+	// Given
+	app := &SparkAppInstance{AppID: "spark-1"}
+	scheduling := PodEvent{PodName: "spark-1-driver", Type: EventTypeWarning, Reason: "FailedScheduling", Message: "0/3 nodes are available", Count: 1, FirstSeenEpoch: 1000, LastSeenEpoch: 1000}
+
+	// When
+	recorded := app.RecordEvent(scheduling, 2)
+
+	// Then
+	assert.True(t, recorded, "recorded")
+	assert.Len(t, app.Events, 1, "Events")
+
+	// When: the same event occurs again
+	repeated := scheduling
+	repeated.Count = 3
+	repeated.FirstSeenEpoch = 3000
+	repeated.LastSeenEpoch = 3000
+	recorded = app.RecordEvent(repeated, 2)
+
+	// Then: the event occurrences are updated
+	assert.True(t, recorded, "recorded")
+	if assert.Len(t, app.Events, 1, "Events") {
+		assert.Equal(t, int32(3), app.Events[0].Count, "Count")
+		assert.Equal(t, int64(1000), app.Events[0].FirstSeenEpoch, "FirstSeenEpoch")
+		assert.Equal(t, int64(3000), app.Events[0].LastSeenEpoch, "LastSeenEpoch")
+	}
+	assert.False(t, app.RecordEvent(repeated, 2), "unchanged event")
+
+	// When: more events than the limit are recorded
+	app.RecordEvent(PodEvent{PodName: "spark-1-driver", Type: "Normal", Reason: "Scheduled", LastSeenEpoch: 4000}, 2)
+	app.RecordEvent(PodEvent{PodName: "spark-1-driver", Type: "Normal", Reason: "Pulling", LastSeenEpoch: 5000}, 2)
+
+	// Then: the most recent events are kept, oldest first
+	if assert.Len(t, app.Events, 2, "Events") {
+		assert.Equal(t, "Scheduled", app.Events[0].Reason, "first event")
+		assert.Equal(t, "Pulling", app.Events[1].Reason, "last event")
+	}
+}
+
+func TestPodStateChanged(t *testing.T) {
+	// Given
+	previous := &SparkAppInstance{PodUID: "uid-1", PodPhase: "Running", DriverState: "Running", DriverReady: true}
+
+	// Then
+	assert.False(t, SparkAppInstance{PodUID: "uid-1", PodPhase: "Running", DriverState: "Running", DriverReady: true}.PodStateChanged(previous), "same pod")
+	assert.True(t, SparkAppInstance{PodUID: "uid-2", PodPhase: "Running", DriverState: "Running", DriverReady: true}.PodStateChanged(previous), "new pod")
+	assert.True(t, SparkAppInstance{PodUID: "uid-1", PodPhase: "Running", DriverState: "Running", DriverReady: true, DriverRestarts: 1}.PodStateChanged(previous), "restarted driver")
+	assert.True(t, SparkAppInstance{PodUID: "uid-1", PodPhase: "Running", DriverState: "Running"}.PodStateChanged(previous), "driver not ready")
+}
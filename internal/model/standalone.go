@@ -0,0 +1,51 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package model
+
+// Spark standalone master statuses (RecoveryState).
+const (
+	// StandaloneMasterAlive is the status of the active master.
+	StandaloneMasterAlive = "ALIVE"
+	// StandaloneMasterStandby is the status of a standby master.
+	StandaloneMasterStandby = "STANDBY"
+)
+
+// StandaloneMasterState represents the state of a Spark standalone master as
+// returned by its /json endpoint.
+type StandaloneMasterState struct {
+	URL        string                  `json:"url"`
+	Status     string                  `json:"status"`
+	Workers    []StandaloneWorker      `json:"workers"`
+	ActiveApps []StandaloneApplication `json:"activeapps"`
+}
+
+// StandaloneWorker represents a worker of a Spark standalone cluster.
+type StandaloneWorker struct {
+	ID           string `json:"id"`
+	Host         string `json:"host"`
+	WebUIAddress string `json:"webuiaddress"`
+	State        string `json:"state"`
+}
+
+// StandaloneApplication represents an application of a Spark standalone cluster.
+type StandaloneApplication struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	User      string `json:"user"`
+	StartTime int64  `json:"starttime"`
+	State     string `json:"state"`
+}
@@ -17,18 +17,124 @@
 // Package model defines domain models used across the application.
 package model
 
-// SparkAppStatus represents the lifecycle status of a Spark application.
+// SparkAppStatus represents the lifecycle state of a Spark application.
+//
+// The states are driven by the driver pod container statuses, the Spark UI
+// probes and Spark History. An application moves forward through
+// Pending, Starting, UIReady, Running and Terminating, and ends in one of the
+// final Succeeded or Failed states. An application may be Lost at any time
+// (e.g. unreachable driver UI or node), and recover from it.
 type SparkAppStatus string
 
 const (
-	// AppPending indicates that the Spark application is pending execution.
+	// AppPending indicates that the driver pod is not running yet (scheduling, image pulling).
 	AppPending SparkAppStatus = "Pending"
-	// AppRunning indicates that the Spark application is currently running.
+	// AppStarting indicates that the driver container is running but the Spark UI is not bound yet.
+	AppStarting SparkAppStatus = "Starting"
+	// AppUIReady indicates that the Spark UI answers but does not report the application yet.
+	AppUIReady SparkAppStatus = "UIReady"
+	// AppRunning indicates that the Spark UI reports the running application.
 	AppRunning SparkAppStatus = "Running"
+	// AppTerminating indicates that the driver pod is being deleted or the driver is cleaning up.
+	AppTerminating SparkAppStatus = "Terminating"
 	// AppSucceeded indicates that the Spark application has completed successfully.
 	AppSucceeded SparkAppStatus = "Succeeded"
 	// AppFailed indicates that the Spark application has failed.
 	AppFailed SparkAppStatus = "Failed"
-	// AppUnknown indicates that the Spark application status is unknown.
+	// AppLost indicates that the driver can no longer be reached (unreachable driver UI or node).
+	AppLost SparkAppStatus = "Lost"
+	// AppUnknown indicates that the Spark application completed with an unknown
+	// outcome (e.g. resolved from Spark History).
 	AppUnknown SparkAppStatus = "Unknown"
 )
+
+// sparkAppStatusOrder is the order of the states an application moves forward through.
+var sparkAppStatusOrder = map[SparkAppStatus]int{
+	AppPending:     1,
+	AppStarting:    2,
+	AppUIReady:     3,
+	AppRunning:     4,
+	AppTerminating: 5,
+	AppSucceeded:   6,
+	AppFailed:      6,
+}
+
+// IsFinal reports whether the state is final (Succeeded or Failed).
+func (s SparkAppStatus) IsFinal() bool {
+	return s == AppSucceeded || s == AppFailed
+}
+
+// IsCompleted reports whether the Spark application is no longer running
+// (Succeeded, Failed, Lost or Unknown).
+func (s SparkAppStatus) IsCompleted() bool {
+	return s.IsFinal() || s == AppLost || s == AppUnknown
+}
+
+// CanTransitionTo reports whether the lifecycle state machine allows the
+// transition to the next state: the final states are never left, the Lost and
+// Unknown states can be entered or left at any time, and the other states only
+// move forward.
+func (s SparkAppStatus) CanTransitionTo(next SparkAppStatus) bool {
+	switch {
+	case s == next || s.IsFinal():
+		return false
+	case s == "" || s == AppLost || s == AppUnknown:
+		return true
+	case next == AppLost || next == AppUnknown:
+		return true
+	default:
+		return sparkAppStatusOrder[next] > sparkAppStatusOrder[s]
+	}
+}
+
+// SparkOperatorAppState represents the `status.applicationState.state` of a
+// Spark Operator (sparkoperator.k8s.io) SparkApplication custom resource.
+type SparkOperatorAppState string
+
+const (
+	// OperatorAppNew indicates that the SparkApplication was just created.
+	OperatorAppNew SparkOperatorAppState = ""
+	// OperatorAppSubmitted indicates that the SparkApplication was submitted.
+	OperatorAppSubmitted SparkOperatorAppState = "SUBMITTED"
+	// OperatorAppRunning indicates that the SparkApplication driver is running.
+	OperatorAppRunning SparkOperatorAppState = "RUNNING"
+	// OperatorAppCompleted indicates that the SparkApplication completed successfully.
+	OperatorAppCompleted SparkOperatorAppState = "COMPLETED"
+	// OperatorAppFailed indicates that the SparkApplication failed.
+	OperatorAppFailed SparkOperatorAppState = "FAILED"
+	// OperatorAppSubmissionFailed indicates that the SparkApplication could not be submitted.
+	OperatorAppSubmissionFailed SparkOperatorAppState = "SUBMISSION_FAILED"
+	// OperatorAppPendingRerun indicates that the SparkApplication is waiting to be resubmitted.
+	OperatorAppPendingRerun SparkOperatorAppState = "PENDING_RERUN"
+	// OperatorAppInvalidating indicates that the SparkApplication is being invalidated after a spec change.
+	OperatorAppInvalidating SparkOperatorAppState = "INVALIDATING"
+	// OperatorAppSucceeding indicates that the driver completed and the operator is cleaning up.
+	OperatorAppSucceeding SparkOperatorAppState = "SUCCEEDING"
+	// OperatorAppFailing indicates that the driver failed and the operator is cleaning up.
+	OperatorAppFailing SparkOperatorAppState = "FAILING"
+	// OperatorAppUnknown indicates that the operator lost track of the driver.
+	OperatorAppUnknown SparkOperatorAppState = "UNKNOWN"
+)
+
+// Status maps the Spark Operator application state to the proxy application status.
+func (s SparkOperatorAppState) Status() SparkAppStatus {
+	switch s {
+	case OperatorAppNew, OperatorAppSubmitted, OperatorAppPendingRerun, OperatorAppInvalidating:
+		return AppPending
+	case OperatorAppRunning:
+		return AppStarting
+	case OperatorAppSucceeding, OperatorAppFailing:
+		return AppTerminating
+	case OperatorAppCompleted:
+		return AppSucceeded
+	case OperatorAppFailed, OperatorAppSubmissionFailed:
+		return AppFailed
+	default:
+		return AppLost
+	}
+}
+
+// IsTerminal reports whether the Spark Operator application state is final.
+func (s SparkOperatorAppState) IsTerminal() bool {
+	return s == OperatorAppCompleted || s == OperatorAppFailed || s == OperatorAppSubmissionFailed
+}
@@ -0,0 +1,87 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package security
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TokenFingerprintKey is the key of the Gin context holding the fingerprint of
+// the bearer token of an authenticated request.
+const TokenFingerprintKey = "tokenFingerprint"
+
+// ReadTokens reads the bearer tokens of a token file, one per line. The empty
+// lines and the lines starting with '#' are ignored.
+func ReadTokens(path string) ([]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := make([]string, 0)
+	for _, line := range strings.Split(string(content), "\n") {
+		token := strings.TrimSpace(line)
+		if token != "" && !strings.HasPrefix(token, "#") {
+			tokens = append(tokens, token)
+		}
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("no token found in %s", path)
+	}
+	return tokens, nil
+}
+
+// BearerTokenAuth creates a Gin middleware handler that rejects the requests
+// without an "Authorization: Bearer <token>" header matching one of the tokens.
+// The fingerprint of the token is set in the context under TokenFingerprintKey.
+func BearerTokenAuth(tokens []string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, found := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		token = strings.TrimSpace(token)
+		if !found || !isValidToken(token, tokens) {
+			c.Header("WWW-Authenticate", `Bearer realm="spark-web-proxy"`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "A valid bearer token is required"})
+			return
+		}
+		c.Set(TokenFingerprintKey, Fingerprint(token))
+		c.Next()
+	}
+}
+
+// isValidToken compares the token with the valid tokens in constant time.
+func isValidToken(token string, tokens []string) bool {
+	valid := 0
+	for _, expected := range tokens {
+		valid |= subtle.ConstantTimeCompare([]byte(token), []byte(expected))
+	}
+	return token != "" && valid == 1
+}
+
+// Fingerprint returns a fingerprint identifying a token without disclosing it
+// (the first 16 hexadecimal digits of its SHA-256 hash).
+func Fingerprint(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:8])
+}
@@ -0,0 +1,79 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package security
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_BearerTokenAuth(t *testing.T) {
+	// Given: a token file with a comment and two tokens
+	path := filepath.Join(t.TempDir(), "tokens")
+	assert.NoError(t, os.WriteFile(path, []byte("# launchers\ntoken-a\n\n  token-b  \n"), 0o600))
+	tokens, err := ReadTokens(path)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"token-a", "token-b"}, tokens, "tokens")
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/registrations", BearerTokenAuth(tokens), func(c *gin.Context) { c.String(http.StatusCreated, c.GetString(TokenFingerprintKey)) })
+
+	tests := []struct {
+		name          string
+		authorization string
+		expected      int
+		fingerprint   string
+	}{
+		{"valid token", "Bearer token-b", http.StatusCreated, Fingerprint("token-b")},
+		{"invalid token", "Bearer token-c", http.StatusUnauthorized, ""},
+		{"empty token", "Bearer ", http.StatusUnauthorized, ""},
+		{"basic auth", "Basic dG9rZW4tYQ==", http.StatusUnauthorized, ""},
+		{"no authorization", "", http.StatusUnauthorized, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// When
+			request := httptest.NewRequest(http.MethodPost, "/registrations", nil)
+			if test.authorization != "" {
+				request.Header.Set("Authorization", test.authorization)
+			}
+			response := httptest.NewRecorder()
+			r.ServeHTTP(response, request)
+
+			// Then
+			assert.Equal(t, test.expected, response.Code, "status code")
+			if test.fingerprint != "" {
+				assert.Equal(t, test.fingerprint, response.Body.String(), "fingerprint")
+			}
+		})
+	}
+}
+
+func Test_Fingerprint(t *testing.T) {
+	assert.Len(t, Fingerprint("token-a"), 16, "length")
+	assert.Equal(t, Fingerprint("token-a"), Fingerprint("token-a"), "stable")
+	assert.NotEqual(t, Fingerprint("token-a"), Fingerprint("token-b"), "distinct")
+	assert.NotContains(t, Fingerprint("token-a"), "token-a", "disclosed")
+}
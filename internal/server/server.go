@@ -18,38 +18,151 @@
 package server
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/rest"
 
 	"github.com/okdp/spark-web-proxy/internal/config"
 	"github.com/okdp/spark-web-proxy/internal/constants"
 	"github.com/okdp/spark-web-proxy/internal/controllers"
+	"github.com/okdp/spark-web-proxy/internal/discovery"
+	kubeclient "github.com/okdp/spark-web-proxy/internal/discovery/resolvers/k8s/client"
 	"github.com/okdp/spark-web-proxy/internal/discovery/resolvers/k8s/informers"
 	log "github.com/okdp/spark-web-proxy/internal/logging"
 	"github.com/okdp/spark-web-proxy/internal/security"
+	"github.com/okdp/spark-web-proxy/internal/store"
 )
 
 // NewSparkUIProxyServer creates and configures the HTTP server for the Spark Web Proxy.
 // It initializes Kubernetes informers, configures the Gin router, and registers routes
 // for Spark UI, Spark History, and health endpoints.
 func NewSparkUIProxyServer(config *config.ApplicationConfig) *http.Server {
-	restConfig, err := rest.InClusterConfig()
-	if err != nil {
-		log.Fatal("Failed to load Kubernetes in-cluster config: %v", err)
+	var localStore store.Store = store.NewMemoryStore()
+	if config.Store.Persistence.Enabled {
+		persistentStore, err := store.NewPersistentStore(localStore, config.Store.Persistence)
+		if err != nil {
+			log.Fatal("Failed to open the application registry: %v", err)
+		}
+		localStore = persistentStore
+	}
+
+	boundedStore := store.NewBoundedStore(localStore, config.Store)
+
+	go boundedStore.RunJanitor(context.Background())
+
+	var sparkAppsStore store.Store = boundedStore
+	if config.Store.Shared.Enabled {
+		restConfig, err := kubeclient.NewRestConfig(config.Kubernetes)
+		if err != nil {
+			log.Fatal("Failed to create Kubernetes client for the shared store: %v", err)
+		}
+		clientset, err := kubernetes.NewForConfig(restConfig)
+		if err != nil {
+			log.Fatal("Failed to create Kubernetes client for the shared store: %v", err)
+		}
+		sharedStore := store.NewSharedStore(boundedStore, clientset, config.Store)
+
+		go sharedStore.Run(context.Background())
+
+		sparkAppsStore = sharedStore
+	}
+
+	executorPods := store.NewExecutorPodStore(config.Spark.Discovery.Executors.MaxDeletedPods)
+
+	go executorPods.RunJanitor(context.Background(), sparkAppsStore, time.Minute)
+
+	for _, clusterConf := range config.GetClusters() {
+		cluster, err := kubeclient.NewCluster(clusterConf)
+		if err != nil {
+			log.Fatal("Failed to create Kubernetes client for the cluster '%s': %v", clusterConf.Name, err)
+		}
+		kubeclient.RegisterCluster(cluster)
+
+		informer := informers.NewSparkAppInformer(config, clusterConf, sparkAppsStore)
+		watchers := []informers.NamespaceWatcher{func(ctx context.Context, namespace string) {
+			informer.WatchNamespace(ctx, cluster.Clientset, namespace)
+		}}
+
+		if !clusterConf.Namespaces.IsEnabled() {
+			go informer.WatchSparkApps(cluster.Clientset)
+		}
+
+		if config.Spark.Discovery.Executors.Enabled {
+			executorInformer := informers.NewExecutorPodInformer(config, clusterConf, executorPods)
+			watchers = append(watchers, func(ctx context.Context, namespace string) {
+				executorInformer.WatchNamespace(ctx, cluster.Clientset, namespace)
+			})
+
+			if !clusterConf.Namespaces.IsEnabled() {
+				go executorInformer.WatchExecutorPods(cluster.Clientset)
+			}
+		}
+
+		if config.Spark.Discovery.Events.Enabled {
+			eventInformer := informers.NewEventInformer(config, clusterConf, sparkAppsStore, executorPods)
+			watchers = append(watchers, func(ctx context.Context, namespace string) {
+				eventInformer.WatchNamespace(ctx, cluster.Clientset, namespace)
+			})
+
+			if !clusterConf.Namespaces.IsEnabled() {
+				go eventInformer.WatchEvents(cluster.Clientset)
+			}
+		}
+
+		if config.Spark.Operator.Enabled {
+			dynamicClient, err := dynamic.NewForConfig(cluster.RestConfig)
+			if err != nil {
+				log.Fatal("Failed to create Kubernetes dynamic client for the cluster '%s': %v", clusterConf.Name, err)
+			}
+
+			operatorInformer := informers.NewSparkOperatorInformer(clusterConf, sparkAppsStore)
+			watchers = append(watchers, func(ctx context.Context, namespace string) {
+				operatorInformer.WatchNamespace(ctx, dynamicClient, namespace)
+			})
+
+			if !clusterConf.Namespaces.IsEnabled() {
+				go operatorInformer.WatchSparkApplications(dynamicClient)
+			}
+		}
+
+		if clusterConf.Namespaces.IsEnabled() {
+			namespaceInformer := informers.NewNamespaceInformer(clusterConf, sparkAppsStore, watchers...)
+
+			go namespaceInformer.WatchNamespaces(cluster.Clientset)
+		}
+	}
+
+	standaloneClusters := make([]*discovery.StandaloneDiscovery, 0, len(config.Spark.Standalone.Clusters))
+	for _, standaloneConf := range config.Spark.Standalone.Clusters {
+		standalone := discovery.NewStandaloneDiscovery(standaloneConf, config.Spark.Standalone.PollInterval, sparkAppsStore)
+		standaloneClusters = append(standaloneClusters, standalone)
+
+		go standalone.Run(context.Background())
+	}
+
+	if config.Spark.Discovery.History.Enabled {
+		poller := discovery.NewHistoryPoller(config, sparkAppsStore)
+
+		go poller.Run(context.Background())
 	}
 
-	clientset, err := kubernetes.NewForConfig(restConfig)
-	if err != nil {
-		log.Fatal("Failed to create Kubernetes client: %v", err)
+	if config.Spark.Discovery.EventLog.Enabled {
+		watcher := discovery.NewEventLogWatcher(config.Spark.Discovery.EventLog, sparkAppsStore)
+
+		go watcher.Run(context.Background())
 	}
 
-	informer := informers.NewSparkAppInformer(config)
+	if config.Spark.UI.HealthProbe.Enabled {
+		prober := discovery.NewHealthProber(config.Spark.UI.HealthProbe, sparkAppsStore)
 
-	go informer.WatchSparkApps(clientset)
+		go prober.Run(context.Background())
+	}
 
 	// Set up Gin router
 	gin.SetMode(config.Proxy.Mode)
@@ -61,40 +174,53 @@ func NewSparkUIProxyServer(config *config.ApplicationConfig) *http.Server {
 	r.Use(security.HTTPSecurity(config.Security)...)
 
 	// Spark UI
-	sparkUI := controllers.NewSparkUIController(config)
-	sparkHistory := controllers.NewSparkHistoryController(config)
-	sparkApps := controllers.NewSparkAppsController(config)
+	sparkUI := controllers.NewSparkUIController(config, sparkAppsStore)
+	sparkHistory := controllers.NewSparkHistoryController(config, sparkAppsStore)
+	sparkApps := controllers.NewSparkAppsController(config, sparkAppsStore)
+	proxyAPI := controllers.NewProxyAPIController(config, sparkAppsStore, executorPods)
+
+	// The routes are served under the external base path, except the health and metrics endpoints
+	base := r.Group(config.GetBasePath())
 
 	// Spark UI Handler
-	r.Any(fmt.Sprintf("%s/:appID/*path", config.Spark.UI.ProxyBase), sparkUI.HandleRunningApp)
+	base.Any(fmt.Sprintf("%s/:appID/*path", config.Spark.UI.ProxyBase), sparkUI.HandleRunningApp)
+	if config.Spark.UI.ProxyBase != constants.SparkReverseProxyBase {
+		base.Any(constants.SparkReverseProxyBase+"/:appID/*path", sparkUI.HandleReverseProxyLink)
+	}
+
+	// Spark standalone worker UI Handler
+	if len(standaloneClusters) != 0 {
+		standalone := controllers.NewStandaloneController(config, standaloneClusters...)
+		base.Any(constants.StandaloneBase+"/:cluster/workers/:workerID/*path", standalone.HandleWorkerUI)
+	}
 
 	// Spark history Handlers
-	r.Any("/history/:appID/*path", sparkHistory.HandleHistoryApp)
-	r.Any("/static/*path", sparkHistory.HandleDefault)
-	r.Any("/api/v1/applications", func(c *gin.Context) {
+	base.Any("/history/:appID/*path", sparkHistory.HandleHistoryApp)
+	base.Any("/static/*path", sparkHistory.HandleDefault)
+	base.Any("/api/v1/applications", func(c *gin.Context) {
 		if c.Query("status") == "running" {
 			sparkApps.HandleIncompleteApplications(c)
 			return
 		}
 		sparkHistory.HandleDefault(c)
 	})
-	r.Any("/api/v1/applications/*path", sparkHistory.HandleDefault)
-	r.Any("/history/", sparkHistory.HandleDefault)
-	r.Any("/home/", func(c *gin.Context) {
+	base.Any("/api/v1/applications/*path", sparkHistory.HandleDefault)
+	base.Any("/history/", sparkHistory.HandleDefault)
+	base.Any("/home/", func(c *gin.Context) {
 		if c.Query("showIncomplete") == constants.True {
 			sparkHistory.HandleIncompleteApps(c)
 			return
 		}
 		sparkHistory.HandleDefault(c)
 	})
-	r.Any("/jobs/", func(c *gin.Context) {
+	base.Any("/jobs/", func(c *gin.Context) {
 		if c.Query("showIncomplete") == constants.True {
 			sparkHistory.HandleIncompleteApps(c)
 			return
 		}
 		sparkHistory.HandleDefault(c)
 	})
-	r.Any("/", func(c *gin.Context) {
+	base.Any("/", func(c *gin.Context) {
 		if c.Query("showIncomplete") == constants.True {
 			sparkHistory.HandleIncompleteApps(c)
 			return
@@ -102,8 +228,30 @@ func NewSparkUIProxyServer(config *config.ApplicationConfig) *http.Server {
 		sparkHistory.HandleDefault(c)
 	})
 
+	// Proxy API
+	base.GET(constants.ProxyAPIBase+"/applications", proxyAPI.ListApplications)
+	base.GET(constants.ProxyAPIBase+"/applications/:appID", proxyAPI.GetApplication)
+	base.GET(constants.ProxyAPIBase+"/applications/:appID/executors", proxyAPI.ListExecutors)
+	base.GET(constants.ProxyAPIBase+"/applications/:appID/events", proxyAPI.ListEvents)
+
+	if config.Spark.Discovery.Registration.Enabled {
+		tokens, err := security.ReadTokens(config.Spark.Discovery.Registration.TokenFile)
+		if err != nil {
+			log.Fatal("Failed to read the registration tokens: %v", err)
+		}
+		registrations := discovery.NewRegistrations(config.Spark.Discovery.Registration, sparkAppsStore)
+		registrationsAPI := controllers.NewRegistrationsController(registrations)
+
+		go registrations.Run(context.Background())
+
+		authenticated := base.Group(constants.ProxyAPIBase+"/registrations", security.BearerTokenAuth(tokens))
+		authenticated.POST("", registrationsAPI.Register)
+		authenticated.DELETE("/:appID", registrationsAPI.Unregister)
+	}
+
 	r.GET(constants.HealthzURI, controllers.Healthz)
 	r.GET(constants.ReadinessURI, controllers.Readiness)
+	r.GET(constants.MetricsURI, gin.WrapH(promhttp.Handler()))
 
 	proxy := &http.Server{
 		Handler: r,
@@ -21,15 +21,23 @@ package spark
 import (
 	"net/http"
 	"net/url"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	log "github.com/okdp/spark-web-proxy/internal/logging"
 	"github.com/okdp/spark-web-proxy/internal/spark/proxy"
+	"github.com/okdp/spark-web-proxy/internal/store"
 )
 
 // DefaultSparkHandler implements proxy.ReverseProxyHandler for Spark UI and
 // Spark History requests.
 type DefaultSparkHandler struct {
+	// uiRoot is the path prefix of the links generated by the Spark UI, rewritten
+	// to proxyRoot in the redirections (empty when the Spark UI follows the
+	// X-Forwarded-Context header).
+	uiRoot    string
+	proxyRoot string
 }
 
 // NewDefaultSparkHandler creates a Spark reverse proxy configured with the
@@ -38,17 +46,36 @@ func NewDefaultSparkHandler(upstreamURL *url.URL, appID string) *proxy.SparkReve
 	return proxy.NewSparkReverseProxy(DefaultSparkHandler{}, upstreamURL, appID)
 }
 
-// ServeSparkHistory proxies Spark History requests to the configured upstream.
-func ServeSparkHistory(c *gin.Context, upstreamURL *url.URL, appID string) {
-	NewDefaultSparkHandler(upstreamURL, appID).
+// ServeSparkHistory proxies Spark History requests to the configured upstream,
+// served under the given external base path. The links of the Spark History
+// pages are rewritten under the base path when it is set.
+func ServeSparkHistory(c *gin.Context, upstreamURL *url.URL, appID string, basePath string) {
+	var handler proxy.ReverseProxyHandler = DefaultSparkHandler{}
+	if basePath != "" {
+		handler = NewLinkRewritingHandler("", basePath)
+	}
+	proxy.NewSparkReverseProxy(handler, upstreamURL, appID).
+		WithBasePath(basePath).
+		ServeHTTP(c.Writer, c.Request)
+}
+
+// ServeSparkWorkerUI proxies Spark standalone worker UI requests to the given
+// worker, served under the given external base path.
+func ServeSparkWorkerUI(c *gin.Context, upstreamURL *url.URL, workerID string, basePath string) {
+	NewDefaultSparkHandler(upstreamURL, workerID).
+		WithBasePath(basePath).
 		ServeHTTP(c.Writer, c.Request)
 }
 
-// ServeSparkUI proxies Spark UI requests to the configured upstream and applies
-// Spark UI–specific error handling (for redirects and fallback behavior).
-func ServeSparkUI(c *gin.Context, upstreamURL *url.URL, appID string) {
-	NewDefaultSparkHandler(upstreamURL, appID).
-		WithSparkUIErrorHandler(c.Request.URL).
+// ServeSparkUI proxies Spark UI requests to the configured upstream through the
+// given transport and handler (see NewSparkUIHandler), served under the given
+// external base path, and applies Spark UI–specific error handling (for redirects,
+// starting drivers and fallback behavior).
+func ServeSparkUI(c *gin.Context, upstreamURL *url.URL, appID string, basePath string, handler proxy.ReverseProxyHandler, transport http.RoundTripper, sparkApps store.Store, startTimeout time.Duration) {
+	proxy.NewSparkReverseProxy(handler, upstreamURL, appID).
+		WithBasePath(basePath).
+		WithTransport(transport).
+		WithSparkUIErrorHandler(c.Request.URL, sparkApps, startTimeout).
 		ServeHTTP(c.Writer, c.Request)
 }
 
@@ -83,6 +110,7 @@ func (c DefaultSparkHandler) ModifyResponse() func(*http.Response) error {
 
 			parsedURL.Scheme = ""
 			parsedURL.Host = ""
+			parsedURL.Path = c.rewriteUIRoot(parsedURL.Path)
 
 			newLocation := parsedURL.String()
 			resp.Header.Set("Location", newLocation)
@@ -94,3 +122,15 @@ func (c DefaultSparkHandler) ModifyResponse() func(*http.Response) error {
 		return nil
 	}
 }
+
+// rewriteUIRoot replaces the uiRoot path prefix of the links generated by the
+// Spark UI with the proxyRoot path prefix.
+func (c DefaultSparkHandler) rewriteUIRoot(path string) string {
+	if c.uiRoot == "" || c.uiRoot == c.proxyRoot {
+		return path
+	}
+	if path == c.uiRoot || strings.HasPrefix(path, c.uiRoot+"/") {
+		return c.proxyRoot + strings.TrimPrefix(path, c.uiRoot)
+	}
+	return path
+}
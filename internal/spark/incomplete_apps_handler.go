@@ -41,18 +41,21 @@ var sparkVersionRe = regexp.MustCompile(`(?is)<span[^>]*class=["'][^"']*\bversio
 // IncompleteAppsHandler implements proxy.ReverseProxyHandler and injects the
 // required scripts into the Spark History "incomplete applications" page.
 type IncompleteAppsHandler struct {
+	// basePath is the external base path of the injected script paths.
+	basePath string
 }
 
 // NewIncompleteAppsHandler creates a reverse proxy configured to handle
-// Spark History incomplete applications pages.
-func NewIncompleteAppsHandler(upstreamURL *url.URL, appID string) *proxy.SparkReverseProxy {
-	return proxy.NewSparkReverseProxy(IncompleteAppsHandler{}, upstreamURL, appID)
+// Spark History incomplete applications pages served under the given external base path.
+func NewIncompleteAppsHandler(upstreamURL *url.URL, appID string, basePath string) *proxy.SparkReverseProxy {
+	return proxy.NewSparkReverseProxy(IncompleteAppsHandler{basePath: basePath}, upstreamURL, appID).
+		WithBasePath(basePath)
 }
 
 // ServeSparkHistoryIncompleteApps proxies Spark History incomplete applications
-// requests to the configured upstream.
-func ServeSparkHistoryIncompleteApps(c *gin.Context, upstreamURL *url.URL, appID string) {
-	NewIncompleteAppsHandler(upstreamURL, appID).
+// requests to the configured upstream, served under the given external base path.
+func ServeSparkHistoryIncompleteApps(c *gin.Context, upstreamURL *url.URL, appID string, basePath string) {
+	NewIncompleteAppsHandler(upstreamURL, appID, basePath).
 		ServeHTTP(c.Writer, c.Request)
 }
 
@@ -76,13 +79,13 @@ func (c IncompleteAppsHandler) ModifyResponse() func(*http.Response) error {
 		resp.TransferEncoding = []string{"identity"}
 		// spark.history.ui.maxApplications = math.MaxInt32
 		// https://spark.apache.org/docs/latest/monitoring.html#spark-history-server-configuration-options
-		return handleIncompleteApplicationsPage(resp, math.MaxInt32)
+		return handleIncompleteApplicationsPage(resp, math.MaxInt32, c.basePath)
 	}
 }
 
-// handleIncompleteApplicationsPage injects the Spark History page scripts when
-// the response is an HTML "no incomplete applications" page.
-func handleIncompleteApplicationsPage(resp *http.Response, limit int) error {
+// handleIncompleteApplicationsPage injects the Spark History page scripts, served
+// under the base path, when the response is an HTML "no incomplete applications" page.
+func handleIncompleteApplicationsPage(resp *http.Response, limit int, basePath string) error {
 
 	log.Debug("Handle incomplete applications pages")
 
@@ -103,7 +106,7 @@ func handleIncompleteApplicationsPage(resp *http.Response, limit int) error {
 		return nil
 	}
 
-	modified := replaceNoIncompleteBlock(plain, limit)
+	modified := replaceNoIncompleteBlock(plain, limit, basePath)
 
 	if err := writeBody(resp, modified, isGzip); err != nil {
 		log.Warn("Failed to write modified HTML response body: %v", err)
@@ -115,17 +118,17 @@ func handleIncompleteApplicationsPage(resp *http.Response, limit int) error {
 	return nil
 }
 
-func replaceNoIncompleteBlock(html []byte, limit int) []byte {
+func replaceNoIncompleteBlock(html []byte, limit int, basePath string) []byte {
 	major, ok := sparkMajorFromHTML(html)
 
 	var repl []byte
 	if ok && major >= 4 {
 		log.Debug("Spark version parsed successfully (major=%d); using Spark 4+ ES module call", major)
 		repl = []byte(
-			`<script src="/static/dataTables.rowsGroup.js"></script>` + "\n" +
-				`<script type="module" src="/static/historypage.js"></script>` + "\n" +
+			`<script src="` + basePath + `/static/dataTables.rowsGroup.js"></script>` + "\n" +
+				`<script type="module" src="` + basePath + `/static/historypage.js"></script>` + "\n" +
 				`<script type="module">` + "\n" +
-				`  import { setAppLimit } from "/static/historypage.js";` + "\n" +
+				`  import { setAppLimit } from "` + basePath + `/static/historypage.js";` + "\n" +
 				`  setAppLimit(` + strconv.Itoa(limit) + `);` + "\n" +
 				`</script>` + "\n" +
 				`<div id="history-summary" class="row-fluid"></div>` + "\n",
@@ -133,9 +136,9 @@ func replaceNoIncompleteBlock(html []byte, limit int) []byte {
 	} else {
 		log.Debug("Spark version parsed successfully (major=%d); using Spark 3+ classic js call", major)
 		repl = []byte(
-			`<script src="/static/dataTables.rowsGroup.js"></script>` + "\n" +
+			`<script src="` + basePath + `/static/dataTables.rowsGroup.js"></script>` + "\n" +
 				`<div id="history-summary" class="row-fluid"></div>` + "\n" +
-				`<script src="/static/historypage.js"></script>` + "\n" +
+				`<script src="` + basePath + `/static/historypage.js"></script>` + "\n" +
 				`<script>setAppLimit(` + strconv.Itoa(limit) + `)</script>` + "\n",
 		)
 	}
@@ -0,0 +1,70 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package spark
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/okdp/spark-web-proxy/internal/config"
+	log "github.com/okdp/spark-web-proxy/internal/logging"
+)
+
+func Test_ReplaceNoIncompleteBlock(t *testing.T) {
+	tests := []struct {
+		name     string
+		version  string
+		expected []string
+	}{
+		{
+			name:    "Spark 3.x classic scripts",
+			version: "3.5.5",
+			expected: []string{
+				`<script src="/spark/static/dataTables.rowsGroup.js"></script>`,
+				`<script src="/spark/static/historypage.js"></script>`,
+				`<script>setAppLimit(100)</script>`,
+			},
+		},
+		{
+			name:    "Spark 4.x ES modules",
+			version: "4.0.0",
+			expected: []string{
+				`<script src="/spark/static/dataTables.rowsGroup.js"></script>`,
+				`<script type="module" src="/spark/static/historypage.js"></script>`,
+				`import { setAppLimit } from "/spark/static/historypage.js";`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given: the "no incomplete applications" page of a Spark History served under /spark
+			log.SetupGlobalLogger(config.Logging{Level: "info"})
+			page := `<span class="version">` + tt.version + `</span><h4>No incomplete applications found!</h4>`
+
+			// When
+			replaced := string(replaceNoIncompleteBlock([]byte(page), 100, "/spark"))
+
+			// Then: the injected scripts are served under the base path
+			assert.NotContains(t, replaced, "No incomplete applications found!", "replaced message")
+			for _, expected := range tt.expected {
+				assert.Contains(t, replaced, expected, "injected script")
+			}
+		})
+	}
+}
@@ -0,0 +1,356 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package spark
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	log "github.com/okdp/spark-web-proxy/internal/logging"
+	"github.com/okdp/spark-web-proxy/internal/spark/proxy"
+)
+
+const (
+	// rewriteChunkSize is the size of the reads from the upstream response body.
+	rewriteChunkSize = 32 * 1024
+	// maxPendingBytes is the size above which the pending bytes of the upstream
+	// response body are rewritten even if they do not end with a delimiter.
+	maxPendingBytes = 256 * 1024
+	// rewriteDelimiters are the bytes after which the response body is cut into
+	// chunks. They do not occur in the beginning of the rewritten links.
+	rewriteDelimiters = ">\n;{},"
+)
+
+var (
+	// linkAttributeRe matches the href, src and action attributes of the HTML
+	// elements and captures their value.
+	linkAttributeRe = regexp.MustCompile(`(?i)\b(href|src|action)([ \t]*=[ \t]*)(["'])([^"'\s<>]*)`)
+	// uiRootScriptRe matches the inline scripts setting the root of the links
+	// built by the Spark UI scripts (e.g. setUIRoot('/proxy/app-1')).
+	uiRootScriptRe = regexp.MustCompile(`\b(setUIRoot|setAppBasePath)\((["'])([^"']*)(["'])\)`)
+	// scriptPathRe matches the JavaScript string literals starting with an
+	// absolute path of the Spark UI static resources or REST API. The literals
+	// appended to a base path (e.g. uiRoot + "/api/v1/applications") are not matched.
+	// The ^ anchor only matches at the beginning of the body, as the chunks of
+	// the streamed bodies are rewritten after the end of the previous chunk.
+	scriptPathRe = regexp.MustCompile("(^|[^+\\s])(\\s*)([\"'`])(/(?:static|api)/)")
+	// refreshURLRe matches the URL of a Refresh header (e.g. 0; url=/jobs/).
+	refreshURLRe = regexp.MustCompile(`(?i)(;\s*url=)(.*)$`)
+)
+
+// LinkRewritingHandler implements proxy.ReverseProxyHandler for the Spark UIs
+// which do not honour the X-Forwarded-Context header (e.g. some Spark versions
+// and custom tabs emitting absolute links such as /jobs/, /static/ or /api/v1/).
+// It rewrites the links of the HTML and JavaScript responses, and the Location
+// and Refresh headers, so that they stay under the proxy root of the application.
+// The response bodies are rewritten while they are streamed.
+type LinkRewritingHandler struct {
+	// uiRoot is the path prefix of the links generated by the Spark UI
+	// (spark.ui.reverseProxy or spark.ui.proxyBase), empty otherwise.
+	uiRoot string
+	// proxyRoot is the path prefix under which the proxy serves the Spark UI (e.g. /sparkui/app-1).
+	proxyRoot string
+	// uiRootLiteralRe matches the JavaScript string literals starting with the uiRoot.
+	uiRootLiteralRe *regexp.Regexp
+}
+
+// NewLinkRewritingHandler creates the LinkRewritingHandler rewriting the links
+// generated by a Spark UI under the uiRoot path prefix, or at the root, to the
+// proxyRoot path prefix.
+func NewLinkRewritingHandler(uiRoot string, proxyRoot string) LinkRewritingHandler {
+	uiRoot = strings.TrimSuffix(uiRoot, "/")
+	proxyRoot = strings.TrimSuffix(proxyRoot, "/")
+	handler := LinkRewritingHandler{uiRoot: uiRoot, proxyRoot: proxyRoot}
+	if uiRoot != "" && uiRoot != proxyRoot {
+		handler.uiRootLiteralRe = regexp.MustCompile("([\"'`])" + regexp.QuoteMeta(uiRoot) + "([\"'`/?#])")
+	}
+	return handler
+}
+
+// NewSparkUIHandler returns the proxy.ReverseProxyHandler of a Spark UI served
+// under proxyRoot: a LinkRewritingHandler when rewriteLinks is enabled, and a
+// DefaultSparkHandler rewriting only the redirections otherwise.
+func NewSparkUIHandler(uiRoot string, proxyRoot string, rewriteLinks bool) proxy.ReverseProxyHandler {
+	if rewriteLinks {
+		return NewLinkRewritingHandler(uiRoot, proxyRoot)
+	}
+	return DefaultSparkHandler{uiRoot: uiRoot, proxyRoot: proxyRoot}
+}
+
+// ModifyRequest returns a function that rewrites the incoming request URL to
+// target the provided upstream URL. The Accept-Encoding header is removed so that
+// the transport decompresses the responses to rewrite.
+func (c LinkRewritingHandler) ModifyRequest(upstreamURL *url.URL) func(*http.Request) {
+	modifyRequest := DefaultSparkHandler{}.ModifyRequest(upstreamURL)
+	return func(req *http.Request) {
+		modifyRequest(req)
+		req.Header.Del("Accept-Encoding")
+	}
+}
+
+// ModifyResponse returns a function that rewrites the Location and Refresh headers
+// of the responses, and the links of the HTML and JavaScript response bodies.
+func (c LinkRewritingHandler) ModifyResponse() func(*http.Response) error {
+	return func(resp *http.Response) error {
+		if location := resp.Header.Get("Location"); location != "" && isRedirect(resp.StatusCode) {
+			resp.Header.Set("Location", c.rewriteLocation(location))
+			log.Debug("Rewritten Location Header: %s", resp.Header.Get("Location"))
+		}
+		if refresh := resp.Header.Get("Refresh"); refresh != "" {
+			resp.Header.Set("Refresh", refreshURLRe.ReplaceAllStringFunc(refresh, func(match string) string {
+				groups := refreshURLRe.FindStringSubmatch(match)
+				return groups[1] + c.rewriteLocation(strings.Trim(groups[2], `"'`))
+			}))
+		}
+
+		rewrite := c.bodyRewriter(resp.Header.Get("Content-Type"))
+		if rewrite == nil || resp.Body == nil || resp.Body == http.NoBody {
+			return nil
+		}
+
+		body := resp.Body
+		switch strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding"))) {
+		case "":
+		case "gzip":
+			gzipReader, err := gzip.NewReader(body)
+			if err != nil {
+				log.Warn("Failed to read the gzip response body of the spark ui served at %s: %v", c.proxyRoot, err)
+				return nil
+			}
+			body = readCloser{Reader: gzipReader, closers: []io.Closer{gzipReader, resp.Body}}
+			resp.Header.Del("Content-Encoding")
+		default:
+			log.Debug("The links of the %s response body of the spark ui served at %s are not rewritten", resp.Header.Get("Content-Encoding"), c.proxyRoot)
+			return nil
+		}
+
+		resp.Body = newRewritingReader(body, rewrite)
+		resp.ContentLength = -1
+		resp.Header.Del("Content-Length")
+		return nil
+	}
+}
+
+// bodyRewriter returns the function rewriting the links of a response body with
+// the given content type, and nil if the response body is not rewritten.
+func (c LinkRewritingHandler) bodyRewriter(contentType string) func([]byte) []byte {
+	contentType = strings.ToLower(contentType)
+	switch {
+	case strings.Contains(contentType, "text/html"):
+		return func(b []byte) []byte {
+			return c.rewriteScript(c.rewriteAttributes(b))
+		}
+	case strings.Contains(contentType, "javascript"):
+		return c.rewriteScript
+	default:
+		return nil
+	}
+}
+
+// rewriteAttributes rewrites the href, src and action attributes of HTML elements.
+func (c LinkRewritingHandler) rewriteAttributes(b []byte) []byte {
+	return linkAttributeRe.ReplaceAllFunc(b, func(match []byte) []byte {
+		groups := linkAttributeRe.FindSubmatch(match)
+		link, rewritten := c.rewriteLink(string(groups[4]))
+		if !rewritten {
+			return match
+		}
+		return []byte(string(groups[1]) + string(groups[2]) + string(groups[3]) + link)
+	})
+}
+
+// rewriteScript rewrites the base paths set by the inline scripts and the
+// absolute paths of the JavaScript string literals.
+func (c LinkRewritingHandler) rewriteScript(b []byte) []byte {
+	b = uiRootScriptRe.ReplaceAllFunc(b, func(match []byte) []byte {
+		groups := uiRootScriptRe.FindSubmatch(match)
+		root := string(groups[3])
+		if rewritten, ok := c.rewriteLink(root); ok {
+			root = rewritten
+		} else if root == "" {
+			root = c.proxyRoot
+		}
+		return []byte(string(groups[1]) + "(" + string(groups[2]) + root + string(groups[4]) + ")")
+	})
+	if c.uiRootLiteralRe != nil {
+		b = c.uiRootLiteralRe.ReplaceAll(b, []byte("${1}"+c.proxyRoot+"${2}"))
+	}
+	return scriptPathRe.ReplaceAll(b, []byte("${1}${2}${3}"+c.proxyRoot+"${4}"))
+}
+
+// rewriteLink rewrites a link generated under the uiRoot path prefix, or an
+// absolute path outside of the proxy root, to the proxy root, and reports
+// whether the link was rewritten. The relative links and the links to other
+// hosts are kept.
+func (c LinkRewritingHandler) rewriteLink(link string) (string, bool) {
+	if strings.HasPrefix(link, "http://") || strings.HasPrefix(link, "https://") {
+		parsedURL, err := url.Parse(link)
+		if err != nil || c.uiRoot == "" || !hasPathPrefix(parsedURL.Path, c.uiRoot) {
+			return link, false
+		}
+		parsedURL.Scheme = ""
+		parsedURL.Host = ""
+		parsedURL.User = nil
+		link = parsedURL.String()
+	}
+
+	switch {
+	case !strings.HasPrefix(link, "/") || strings.HasPrefix(link, "//"):
+		return link, false
+	case c.uiRoot != "" && c.uiRoot != c.proxyRoot && hasPathPrefix(link, c.uiRoot):
+		return c.proxyRoot + strings.TrimPrefix(link, c.uiRoot), true
+	case hasPathPrefix(link, c.proxyRoot):
+		return link, false
+	default:
+		return c.proxyRoot + link, true
+	}
+}
+
+// rewriteLocation rewrites the URL of a Location or Refresh header to a
+// path under the proxy root.
+func (c LinkRewritingHandler) rewriteLocation(location string) string {
+	parsedURL, err := url.Parse(location)
+	if err != nil {
+		log.Error("Error parsing Location URL: %+v", err)
+		return location
+	}
+	if parsedURL.IsAbs() {
+		parsedURL.Scheme = ""
+		parsedURL.Host = ""
+		parsedURL.User = nil
+	}
+	link, _ := c.rewriteLink(parsedURL.String())
+	return link
+}
+
+// hasPathPrefix reports whether the path is the prefix or a path under it.
+func hasPathPrefix(path string, prefix string) bool {
+	if path == prefix || strings.HasPrefix(path, prefix+"/") {
+		return true
+	}
+	return strings.HasPrefix(path, prefix+"?") || strings.HasPrefix(path, prefix+"#")
+}
+
+// isRedirect reports whether the status code is a redirection with a Location header.
+func isRedirect(statusCode int) bool {
+	return statusCode >= http.StatusMultipleChoices && statusCode < http.StatusBadRequest
+}
+
+// rewritingReader rewrites a response body while it is streamed. The body is
+// cut after the last delimiter of the bytes read, so that the links are not
+// split between two rewritten chunks.
+type rewritingReader struct {
+	body    io.ReadCloser
+	rewrite func([]byte) []byte
+	buffer  []byte
+	// pending holds the bytes read which are not rewritten yet.
+	pending []byte
+	// context holds the end of the previous chunk prepended to the next chunk
+	// while it is rewritten: its last non-space byte followed by a line feed.
+	context []byte
+	// rewritten holds the rewritten bytes which are not read yet.
+	rewritten []byte
+	err       error
+}
+
+// newRewritingReader creates a rewritingReader of the given body.
+func newRewritingReader(body io.ReadCloser, rewrite func([]byte) []byte) *rewritingReader {
+	return &rewritingReader{
+		body:    body,
+		rewrite: rewrite,
+		buffer:  make([]byte, rewriteChunkSize),
+	}
+}
+
+// Read implements io.Reader.
+func (r *rewritingReader) Read(p []byte) (int, error) {
+	for len(r.rewritten) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		r.fill()
+	}
+	n := copy(p, r.rewritten)
+	r.rewritten = r.rewritten[n:]
+	return n, nil
+}
+
+// fill reads the body and rewrites the pending bytes up to the last delimiter,
+// or all of them at the end of the body.
+func (r *rewritingReader) fill() {
+	n, err := r.body.Read(r.buffer)
+	r.pending = append(r.pending, r.buffer[:n]...)
+	if err != nil {
+		r.err = err
+		r.rewritten = r.rewriteChunk(r.pending)
+		r.pending = nil
+		return
+	}
+
+	cut := bytes.LastIndexAny(r.pending, rewriteDelimiters) + 1
+	if cut == 0 {
+		if len(r.pending) < maxPendingBytes {
+			return
+		}
+		cut = len(r.pending)
+	}
+	r.rewritten = r.rewriteChunk(r.pending[:cut])
+	r.pending = append([]byte(nil), r.pending[cut:]...)
+}
+
+// rewriteChunk rewrites a chunk of the body after the context of the previous
+// chunk, so that the beginning of the chunk is not taken as the beginning of the
+// body (e.g. the line feed of uiRoot +\n "/api/v1/applications"). The context
+// is then removed from the rewritten chunk, as the expressions do not rewrite it.
+func (r *rewritingReader) rewriteChunk(chunk []byte) []byte {
+	context := r.context
+	if trimmed := bytes.TrimRight(chunk, " \t\r\n\f\v"); len(trimmed) != 0 {
+		r.context = []byte{trimmed[len(trimmed)-1], '\n'}
+	}
+	if len(context) == 0 {
+		return r.rewrite(chunk)
+	}
+	return bytes.TrimPrefix(r.rewrite(append(append([]byte(nil), context...), chunk...)), context)
+}
+
+// Close implements io.Closer.
+func (r *rewritingReader) Close() error {
+	return r.body.Close()
+}
+
+// readCloser is an io.ReadCloser closing several closers (e.g. a gzip reader
+// and the underlying response body).
+type readCloser struct {
+	io.Reader
+	closers []io.Closer
+}
+
+// Close implements io.Closer.
+func (r readCloser) Close() error {
+	var err error
+	for _, closer := range r.closers {
+		if closeErr := closer.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
@@ -0,0 +1,194 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package spark
+
+import (
+	"bytes"
+	"compress/gzip"
+	"flag"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/okdp/spark-web-proxy/internal/config"
+	log "github.com/okdp/spark-web-proxy/internal/logging"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+// rewriteResponse returns the response of the given body and headers rewritten by the handler.
+func rewriteResponse(t *testing.T, handler LinkRewritingHandler, statusCode int, header http.Header, body io.Reader) (*http.Response, string) {
+	t.Helper()
+	resp := &http.Response{StatusCode: statusCode, Header: header, Body: io.NopCloser(body), ContentLength: -1}
+	assert.NoError(t, handler.ModifyResponse()(resp))
+	rewritten, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	assert.NoError(t, resp.Body.Close())
+	return resp, string(rewritten)
+}
+
+func Test_LinkRewritingHandler_Golden(t *testing.T) {
+	tests := []struct {
+		name   string
+		page   string
+		uiRoot string
+	}{
+		{
+			name:   "Spark 3.x page with links at the root",
+			page:   "spark-3.5.5-jobs.html",
+			uiRoot: "",
+		},
+		{
+			name:   "Spark 4.x page with spark.ui.reverseProxy links",
+			page:   "spark-4.0.0-jobs.html",
+			uiRoot: "/proxy/spark-4a1b2c",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			log.SetupGlobalLogger(config.Logging{Level: "info"})
+			page, err := os.ReadFile(filepath.Join("testdata", tt.page))
+			assert.NoError(t, err)
+			handler := NewLinkRewritingHandler(tt.uiRoot, "/sparkui/spark-app")
+			header := http.Header{"Content-Type": {"text/html;charset=utf-8"}}
+
+			// When: the page is streamed in a single read and byte by byte
+			_, rewritten := rewriteResponse(t, handler, http.StatusOK, header.Clone(), bytes.NewReader(page))
+			_, streamed := rewriteResponse(t, handler, http.StatusOK, header.Clone(), iotest.OneByteReader(bytes.NewReader(page)))
+
+			// Then
+			golden := filepath.Join("testdata", tt.page+".golden")
+			if *update {
+				assert.NoError(t, os.WriteFile(golden, []byte(rewritten), 0o600))
+			}
+			expected, err := os.ReadFile(golden)
+			assert.NoError(t, err)
+			assert.Equal(t, string(expected), rewritten, "rewritten page")
+			assert.Equal(t, rewritten, streamed, "streamed page")
+			assert.Equal(t, rewritten, string(handler.rewriteScript(handler.rewriteAttributes([]byte(rewritten)))), "idempotent rewriting")
+		})
+	}
+}
+
+func Test_LinkRewritingHandler_Headers(t *testing.T) {
+	tests := []struct {
+		name       string
+		uiRoot     string
+		statusCode int
+		header     string
+		value      string
+		expected   string
+	}{
+		{
+			name:       "Redirection at the root",
+			statusCode: http.StatusFound,
+			header:     "Location",
+			value:      "http://10.0.0.1:4040/jobs/?id=1",
+			expected:   "/sparkui/spark-app/jobs/?id=1",
+		},
+		{
+			name:       "Redirection under the spark.ui.reverseProxy root",
+			uiRoot:     "/proxy/spark-app",
+			statusCode: http.StatusMovedPermanently,
+			header:     "Location",
+			value:      "/proxy/spark-app/stages/",
+			expected:   "/sparkui/spark-app/stages/",
+		},
+		{
+			name:       "Redirection under the proxy root",
+			statusCode: http.StatusFound,
+			header:     "Location",
+			value:      "/sparkui/spark-app/jobs/",
+			expected:   "/sparkui/spark-app/jobs/",
+		},
+		{
+			name:       "Refresh",
+			statusCode: http.StatusOK,
+			header:     "Refresh",
+			value:      "0; url=/jobs/",
+			expected:   "0; url=/sparkui/spark-app/jobs/",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			handler := NewLinkRewritingHandler(tt.uiRoot, "/sparkui/spark-app")
+			header := http.Header{tt.header: {tt.value}}
+
+			// When
+			resp, _ := rewriteResponse(t, handler, tt.statusCode, header, http.NoBody)
+
+			// Then
+			assert.Equal(t, tt.expected, resp.Header.Get(tt.header), tt.header)
+		})
+	}
+}
+
+func Test_LinkRewritingHandler_Body(t *testing.T) {
+	// Given: a gzip JavaScript response and a JSON response
+	handler := NewLinkRewritingHandler("", "/sparkui/spark-app")
+	var script bytes.Buffer
+	gzipWriter := gzip.NewWriter(&script)
+	_, _ = gzipWriter.Write([]byte(`$.getJSON('/api/v1/applications', function (apps) {});` + "\n" +
+		`return uiRoot + "/api/v1/applications/" + appId + "/allexecutors";`))
+	assert.NoError(t, gzipWriter.Close())
+
+	// When
+	resp, rewritten := rewriteResponse(t, handler, http.StatusOK,
+		http.Header{"Content-Type": {"application/javascript"}, "Content-Encoding": {"gzip"}, "Content-Length": {"100"}}, &script)
+
+	// Then: the response is decompressed and rewritten, except the paths appended to the uiRoot
+	assert.Equal(t, `$.getJSON('/sparkui/spark-app/api/v1/applications', function (apps) {});`+"\n"+
+		`return uiRoot + "/api/v1/applications/" + appId + "/allexecutors";`, rewritten, "rewritten script")
+	assert.Empty(t, resp.Header.Get("Content-Encoding"), "Content-Encoding")
+	assert.Empty(t, resp.Header.Get("Content-Length"), "Content-Length")
+
+	// When
+	_, rewritten = rewriteResponse(t, handler, http.StatusOK,
+		http.Header{"Content-Type": {"application/json"}}, bytes.NewReader([]byte(`{"href":"/jobs/"}`)))
+
+	// Then: the JSON responses are not rewritten
+	assert.Equal(t, `{"href":"/jobs/"}`, rewritten, "JSON response")
+}
+
+func Test_LinkRewritingHandler_Chunk_Boundaries(t *testing.T) {
+	// Given: a script whose paths are appended to the uiRoot on the next line,
+	// streamed one byte at a time so that the body is cut after each line feed
+	handler := NewLinkRewritingHandler("", "/sparkui/spark-app")
+	script := "var url = uiRoot +\n  \"/api/v1/applications/\" + appId;\n" +
+		"var base = uiRoot +\n\n\t'/static/';\n" +
+		"$.getJSON(\n  '/api/v1/applications',\n  function (apps) {});\n" +
+		"'/static/spark-dag-viz.js'"
+
+	// When
+	_, rewritten := rewriteResponse(t, handler, http.StatusOK,
+		http.Header{"Content-Type": {"application/javascript"}}, iotest.OneByteReader(bytes.NewReader([]byte(script))))
+
+	// Then: the paths appended to the uiRoot are kept, and the other paths are rewritten
+	assert.Equal(t, "var url = uiRoot +\n  \"/api/v1/applications/\" + appId;\n"+
+		"var base = uiRoot +\n\n\t'/static/';\n"+
+		"$.getJSON(\n  '/sparkui/spark-app/api/v1/applications',\n  function (apps) {});\n"+
+		"'/sparkui/spark-app/static/spark-dag-viz.js'", rewritten, "rewritten script")
+}
@@ -0,0 +1,136 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package proxy
+
+import (
+	"html/template"
+	"net/http"
+	"time"
+
+	log "github.com/okdp/spark-web-proxy/internal/logging"
+	"github.com/okdp/spark-web-proxy/internal/model"
+)
+
+// eventsTemplate renders the recent pod events of an application on the proxy pages.
+var eventsTemplate = template.Must(template.New("page").Parse(`
+{{- define "eventsStyle" -}}
+    table.events th { text-align: left; padding: 0.3em 1em 0.3em 0; }
+    table.events td:first-child { font-weight: normal; }
+    table.events tr.warning td { color: #b00; }
+{{- end -}}
+{{- define "events" }}
+  {{- if . }}
+  <h4>Recent events</h4>
+  <table class="events">
+    <tr><th>Type</th><th>Reason</th><th>Pod</th><th>Message</th><th>Count</th><th>Last seen</th></tr>
+    {{- range . }}
+    <tr{{ if .Warning }} class="warning"{{ end }}><td>{{ .Type }}</td><td>{{ .Reason }}</td><td>{{ .PodName }}</td><td>{{ .Message }}</td><td>{{ .Count }}</td><td>{{ .LastSeen }} ago</td></tr>
+    {{- end }}
+  </table>
+  {{- end }}
+{{- end -}}
+`))
+
+// errorPage is the page served when the Spark UI of an application with warning
+// events is not available, instead of the redirection to Spark History.
+var errorPage = template.Must(template.Must(eventsTemplate.Clone()).Parse(`<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <title>Spark UI is not available - {{ .AppName }}</title>
+  <style>
+    body { font-family: -apple-system, "Helvetica Neue", Arial, sans-serif; margin: 3em; color: #333; }
+    table { border-collapse: collapse; margin-top: 1em; }
+    td { padding: 0.3em 1em 0.3em 0; }
+    td:first-child { font-weight: bold; }
+    {{ template "eventsStyle" }}
+  </style>
+</head>
+<body>
+  <h3>The Spark UI of the application '{{ .AppName }}' is not available</h3>
+  <p>The application may be completed: <a href="{{ .HistoryURL }}">open it in the Spark History Server</a>.</p>
+  <table>
+    <tr><td>Application</td><td>{{ .App.AppID }}</td></tr>
+    <tr><td>State</td><td>{{ .App.Status }}</td></tr>
+    {{- if .App.PodName }}
+    <tr><td>Driver pod</td><td>{{ if .App.Cluster }}{{ .App.Cluster }}:{{ end }}{{ .App.Namespace }}/{{ .App.PodName }}</td></tr>
+    <tr><td>Pod phase</td><td>{{ .App.PodPhase }}</td></tr>
+    {{- end }}
+    {{- if .App.DriverState }}
+    <tr><td>Driver container</td><td>{{ .App.DriverState }}</td></tr>
+    {{- end }}
+  </table>
+  {{- template "events" .Events }}
+</body>
+</html>
+`))
+
+// eventRow is a pod event displayed on the proxy pages.
+type eventRow struct {
+	model.PodEvent
+	Warning  bool
+	LastSeen string
+}
+
+// errorPageData is the data of the "Spark UI is not available" page.
+type errorPageData struct {
+	App        *model.SparkAppInstance
+	AppName    string
+	HistoryURL string
+	Events     []eventRow
+}
+
+// ServeErrorPage writes a 502 (Bad Gateway) page telling the browser that the
+// Spark UI of the application is not available, with the recent events of its
+// pods and a link to the application in Spark History.
+func ServeErrorPage(rw http.ResponseWriter, app *model.SparkAppInstance, historyURL string) {
+	data := errorPageData{App: app, AppName: app.AppName, HistoryURL: historyURL, Events: recentEvents(app)}
+	if data.AppName == "" {
+		data.AppName = app.AppID
+	}
+
+	rw.Header().Set("Content-Type", "text/html; charset=utf-8")
+	rw.Header().Set("Cache-Control", "no-store")
+	rw.WriteHeader(http.StatusBadGateway)
+	if err := errorPage.Execute(rw, data); err != nil {
+		log.Error("Failed to render the error page of the application '%s': %+v", app.AppID, err)
+	}
+}
+
+// HasWarningEvents reports whether the application recorded warning pod events.
+func HasWarningEvents(app *model.SparkAppInstance) bool {
+	for _, event := range app.Events {
+		if event.Type == model.EventTypeWarning {
+			return true
+		}
+	}
+	return false
+}
+
+// recentEvents returns the pod events of the application to display, most recent first.
+func recentEvents(app *model.SparkAppInstance) []eventRow {
+	rows := make([]eventRow, 0, len(app.Events))
+	for i := len(app.Events) - 1; i >= 0; i-- {
+		event := app.Events[i]
+		rows = append(rows, eventRow{
+			PodEvent: event,
+			Warning:  event.Type == model.EventTypeWarning,
+			LastSeen: time.Since(time.UnixMilli(event.LastSeenEpoch)).Truncate(time.Second).String(),
+		})
+	}
+	return rows
+}
@@ -26,9 +26,10 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
 	log "github.com/okdp/spark-web-proxy/internal/logging"
-	"github.com/okdp/spark-web-proxy/internal/model"
+	"github.com/okdp/spark-web-proxy/internal/store"
 	"github.com/okdp/spark-web-proxy/internal/utils"
 )
 
@@ -68,9 +69,12 @@ func DefaultErrorHandler(appID string) func(http.ResponseWriter, *http.Request,
 
 // SparkUIErrorHandler returns an error handler tailored for Spark UI requests.
 // It handles expected cancellation errors quietly, supports browser redirects
-// for kill actions, and falls back to Spark History when the Spark UI becomes
-// unavailable.
-func SparkUIErrorHandler(fromURL *url.URL, appID string) func(http.ResponseWriter, *http.Request, error) {
+// for kill actions, serves the "Spark UI is starting" page while a starting
+// driver does not accept connections (until the start timeout), and falls back
+// to Spark History when the Spark UI becomes unavailable (the application is
+// then marked as lost in the store). The browsers are served the recent pod
+// events instead of the redirection when the application recorded warning events.
+func SparkUIErrorHandler(fromURL *url.URL, appID string, sparkApps store.Store, startTimeout time.Duration) func(http.ResponseWriter, *http.Request, error) {
 	return func(rw http.ResponseWriter, req *http.Request, err error) {
 		if isCancelErr(err) {
 			log.Debug("Request canceled for app '%s' url=%s: %v", appID, req.URL.String(), err)
@@ -83,8 +87,18 @@ func SparkUIErrorHandler(fromURL *url.URL, appID string) func(http.ResponseWrite
 			rw.WriteHeader(http.StatusFound)
 			return
 		}
+		if app, found := sparkApps.Get(appID); found && IsStartingSparkUI(app, err, startTimeout) {
+			log.Debug("The spark ui of the application '%s' is not listening yet at URL: %s: %v", appID, req.URL.String(), err)
+			ServeStartingPage(rw, req, app)
+			return
+		}
+		store.MarkLost(sparkApps, appID)
+		if app, found := sparkApps.Get(appID); found && HasWarningEvents(app) && utils.IsBrowserRequest(req) {
+			log.Error("An error was occured when accessing spark application '%s' at URL: %s, serving its pod events \ndetails: %+v", appID, req.URL.String(), err)
+			ServeErrorPage(rw, app, fromURL.Path)
+			return
+		}
 		log.Error("An error was occured when accessing spark application '%s' at URL: %s, redirect to spark history \ndetails: %+v", appID, req.URL.String(), err)
-		model.MakeSparkAppCompleted(appID)
 		// redirect to spark history
 		rw.Header().Set("Location", fromURL.Path)
 		rw.WriteHeader(http.StatusFound)
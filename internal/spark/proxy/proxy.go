@@ -17,11 +17,20 @@
 package proxy
 
 import (
+	"fmt"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/okdp/spark-web-proxy/internal/store"
 )
 
+// cookiePathRe matches the Path attribute of a Set-Cookie header and captures its value.
+var cookiePathRe = regexp.MustCompile(`(?i)(;\s*path=)([^;]*)`)
+
 // SparkReverseProxy wraps httputil.ReverseProxy and adds Spark-specific
 // context such as the application ID.
 type SparkReverseProxy struct {
@@ -40,9 +49,61 @@ func NewSparkReverseProxy(c ReverseProxyHandler, upstreamURL *url.URL, appID str
 }
 
 // WithSparkUIErrorHandler configures the proxy to use a Spark UI–specific
-// error handler and returns the updated proxy.
-func (p *SparkReverseProxy) WithSparkUIErrorHandler(fromURL *url.URL) *SparkReverseProxy {
-	p.ErrorHandler = SparkUIErrorHandler(fromURL, p.appID)
+// error handler and returns the updated proxy. The 502 and 503 responses of an
+// upstream proxy to a starting application are handled as connection errors, so
+// that the "Spark UI is starting" page is served.
+func (p *SparkReverseProxy) WithSparkUIErrorHandler(fromURL *url.URL, sparkApps store.Store, startTimeout time.Duration) *SparkReverseProxy {
+	p.ErrorHandler = SparkUIErrorHandler(fromURL, p.appID, sparkApps, startTimeout)
+	modifyResponse := p.ModifyResponse
+	p.ModifyResponse = func(resp *http.Response) error {
+		if isUnavailableSparkUIResponse(resp) {
+			if app, found := sparkApps.Get(p.appID); found && IsStartingSparkUI(app, nil, startTimeout) {
+				return fmt.Errorf("%w: %s", errSparkUIUnavailable, resp.Status)
+			}
+		}
+		if modifyResponse != nil {
+			return modifyResponse(resp)
+		}
+		return nil
+	}
+	return p
+}
+
+// WithTransport configures the round tripper used to reach the upstream and
+// returns the updated proxy. A nil transport uses http.DefaultTransport.
+func (p *SparkReverseProxy) WithTransport(transport http.RoundTripper) *SparkReverseProxy {
+	p.Transport = transport
+	return p
+}
+
+// WithBasePath configures the proxy to serve the upstream under the given external
+// base path and returns the updated proxy: the root-relative redirections and the
+// cookie paths of the upstream responses which are not under the base path are
+// prefixed with it. An empty base path keeps the responses unchanged.
+func (p *SparkReverseProxy) WithBasePath(basePath string) *SparkReverseProxy {
+	if basePath == "" {
+		return p
+	}
+	modifyResponse := p.ModifyResponse
+	p.ModifyResponse = func(resp *http.Response) error {
+		if modifyResponse != nil {
+			if err := modifyResponse(resp); err != nil {
+				return err
+			}
+		}
+		if location := resp.Header.Get("Location"); location != "" && resp.StatusCode >= http.StatusMultipleChoices && resp.StatusCode < http.StatusBadRequest {
+			resp.Header.Set("Location", prefixPath(location, basePath))
+		}
+		cookies := resp.Header.Values("Set-Cookie")
+		resp.Header.Del("Set-Cookie")
+		for _, cookie := range cookies {
+			resp.Header.Add("Set-Cookie", cookiePathRe.ReplaceAllStringFunc(cookie, func(match string) string {
+				groups := cookiePathRe.FindStringSubmatch(match)
+				return groups[1] + prefixPath(strings.TrimSpace(groups[2]), basePath)
+			}))
+		}
+		return nil
+	}
 	return p
 }
 
@@ -51,3 +112,16 @@ func (p *SparkReverseProxy) WithSparkUIErrorHandler(fromURL *url.URL) *SparkReve
 func (p *SparkReverseProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	p.ReverseProxy.ServeHTTP(w, r)
 }
+
+// prefixPath prefixes a root-relative path which is not under the base path
+// with the base path. The relative paths and the URLs are kept.
+func prefixPath(path string, basePath string) string {
+	if !strings.HasPrefix(path, "/") || strings.HasPrefix(path, "//") {
+		return path
+	}
+	if path == basePath || strings.HasPrefix(path, basePath+"/") ||
+		strings.HasPrefix(path, basePath+"?") || strings.HasPrefix(path, basePath+"#") {
+		return path
+	}
+	return basePath + path
+}
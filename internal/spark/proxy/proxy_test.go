@@ -0,0 +1,86 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/okdp/spark-web-proxy/internal/config"
+	log "github.com/okdp/spark-web-proxy/internal/logging"
+)
+
+func Test_SparkReverseProxy_WithBasePath(t *testing.T) {
+	tests := []struct {
+		name             string
+		location         string
+		cookie           string
+		expectedLocation string
+		expectedCookie   string
+	}{
+		{
+			name:             "Root-relative redirection and cookie",
+			location:         "/history/spark-1/jobs/",
+			cookie:           "JSESSIONID=abc; Path=/; HttpOnly",
+			expectedLocation: "/spark/history/spark-1/jobs/",
+			expectedCookie:   "JSESSIONID=abc; Path=/spark/; HttpOnly",
+		},
+		{
+			name:             "Redirection and cookie under the base path",
+			location:         "/spark/sparkui/spark-1/jobs/",
+			cookie:           "session=abc; path=/spark/sparkui",
+			expectedLocation: "/spark/sparkui/spark-1/jobs/",
+			expectedCookie:   "session=abc; path=/spark/sparkui",
+		},
+		{
+			name:             "Redirection to another host and cookie without path",
+			location:         "https://idp.example.com/auth?redirect_uri=/history/",
+			cookie:           "state=xyz; Secure",
+			expectedLocation: "https://idp.example.com/auth?redirect_uri=/history/",
+			expectedCookie:   "state=xyz; Secure",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given: an upstream redirecting and setting a cookie
+			log.SetupGlobalLogger(config.Logging{Level: "info"})
+			upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Set-Cookie", tt.cookie)
+				w.Header().Set("Location", tt.location)
+				w.WriteHeader(http.StatusFound)
+			}))
+			defer upstream.Close()
+			upstreamURL, _ := url.Parse(upstream.URL)
+			recorder := httptest.NewRecorder()
+
+			// When
+			NewSparkReverseProxy(passthroughHandler{}, upstreamURL, "spark-1").
+				WithBasePath("/spark").
+				ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/spark/history/spark-1", nil))
+
+			// Then
+			assert.Equal(t, http.StatusFound, recorder.Code, "status code")
+			assert.Equal(t, tt.expectedLocation, recorder.Header().Get("Location"), "Location")
+			assert.Equal(t, tt.expectedCookie, recorder.Header().Get("Set-Cookie"), "Set-Cookie")
+		})
+	}
+}
@@ -0,0 +1,142 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package proxy
+
+import (
+	"encoding/json"
+	"errors"
+	"html/template"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	log "github.com/okdp/spark-web-proxy/internal/logging"
+	"github.com/okdp/spark-web-proxy/internal/model"
+	"github.com/okdp/spark-web-proxy/internal/utils"
+)
+
+// StartingPageRefreshInterval is the interval at which the "Spark UI is starting" page is refreshed.
+const StartingPageRefreshInterval = 5 * time.Second
+
+// errSparkUIUnavailable is returned when an upstream proxy (e.g. the Kubernetes
+// API server pods/proxy subresource) answers that the driver UI is unavailable.
+var errSparkUIUnavailable = errors.New("the spark ui is unavailable")
+
+// startingPage is the auto-refreshing page served while the driver UI is starting.
+var startingPage = template.Must(template.Must(eventsTemplate.Clone()).Parse(`<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <meta http-equiv="refresh" content="{{ .RefreshSeconds }}">
+  <title>Spark UI is starting - {{ .AppName }}</title>
+  <style>
+    body { font-family: -apple-system, "Helvetica Neue", Arial, sans-serif; margin: 3em; color: #333; }
+    table { border-collapse: collapse; margin-top: 1em; }
+    td { padding: 0.3em 1em 0.3em 0; }
+    td:first-child { font-weight: bold; }
+    {{ template "eventsStyle" }}
+  </style>
+</head>
+<body>
+  <h3>The Spark UI of the application '{{ .AppName }}' is starting</h3>
+  <p>This page is refreshed every {{ .RefreshSeconds }} seconds until the Spark UI is available.</p>
+  <table>
+    <tr><td>Application</td><td>{{ .App.AppID }}</td></tr>
+    <tr><td>State</td><td>{{ .App.Status }}{{ if .StartedFor }} (for {{ .StartedFor }}){{ end }}</td></tr>
+    {{- if .App.PodName }}
+    <tr><td>Driver pod</td><td>{{ if .App.Cluster }}{{ .App.Cluster }}:{{ end }}{{ .App.Namespace }}/{{ .App.PodName }}</td></tr>
+    <tr><td>Pod phase</td><td>{{ .App.PodPhase }}</td></tr>
+    {{- end }}
+    {{- if .App.DriverState }}
+    <tr><td>Driver container</td><td>{{ .App.DriverState }}{{ if .App.DriverReady }}, ready{{ else }}, not ready{{ end }}</td></tr>
+    {{- end }}
+  </table>
+  {{- template "events" .Events }}
+</body>
+</html>
+`))
+
+// startingPageData is the data of the "Spark UI is starting" page.
+type startingPageData struct {
+	App            *model.SparkAppInstance
+	AppName        string
+	RefreshSeconds int
+	StartedFor     string
+	Events         []eventRow
+}
+
+// ServeStartingPage writes a 503 (Service Unavailable) response telling the
+// client that the Spark UI of the application is starting: an auto-refreshing
+// HTML page with the driver pod status for the browsers, or a JSON body for the
+// other clients. The Retry-After header is set in both cases.
+func ServeStartingPage(rw http.ResponseWriter, req *http.Request, app *model.SparkAppInstance) {
+	refreshSeconds := int(StartingPageRefreshInterval.Seconds())
+	rw.Header().Set("Retry-After", strconv.Itoa(refreshSeconds))
+	rw.Header().Set("Cache-Control", "no-store")
+
+	if !utils.IsBrowserRequest(req) {
+		rw.Header().Set("Content-Type", "application/json; charset=utf-8")
+		rw.WriteHeader(http.StatusServiceUnavailable)
+		body := map[string]any{
+			"error":  "The Spark UI of the application '" + app.AppID + "' is starting",
+			"status": app.Status,
+		}
+		if len(app.Events) != 0 {
+			body["events"] = app.Events
+		}
+		_ = json.NewEncoder(rw).Encode(body)
+		return
+	}
+
+	data := startingPageData{App: app, AppName: app.AppName, RefreshSeconds: refreshSeconds, Events: recentEvents(app)}
+	if data.AppName == "" {
+		data.AppName = app.AppID
+	}
+	if startedAt, found := app.StartedAt(); found {
+		data.StartedFor = time.Since(startedAt).Truncate(time.Second).String()
+	}
+
+	rw.Header().Set("Content-Type", "text/html; charset=utf-8")
+	rw.WriteHeader(http.StatusServiceUnavailable)
+	if err := startingPage.Execute(rw, data); err != nil {
+		log.Error("Failed to render the starting page of the application '%s': %+v", app.AppID, err)
+	}
+}
+
+// IsStartingSparkUI reports whether the Spark UI of the application may still be
+// starting: the application is Pending, Starting or UIReady since less than the
+// start timeout, and the error (if any) is a connection error, or an upstream
+// proxy answering that the driver UI is unavailable.
+func IsStartingSparkUI(app *model.SparkAppInstance, err error, startTimeout time.Duration) bool {
+	if !app.IsStarting() && app.Status != string(model.AppUIReady) {
+		return false
+	}
+	var opErr *net.OpError
+	if err != nil && !errors.Is(err, errSparkUIUnavailable) && (!errors.As(err, &opErr) || opErr.Op != "dial") {
+		return false
+	}
+	startedAt, found := app.StartedAt()
+	return found && time.Since(startedAt) < startTimeout
+}
+
+// isUnavailableSparkUIResponse reports whether the response is an upstream proxy
+// error telling that the driver UI does not accept connections: the Kubernetes
+// API server proxy subresources answer 502 or 503 until the driver listens.
+func isUnavailableSparkUIResponse(resp *http.Response) bool {
+	return resp.StatusCode == http.StatusBadGateway || resp.StatusCode == http.StatusServiceUnavailable
+}
@@ -0,0 +1,174 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package proxy
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/okdp/spark-web-proxy/internal/config"
+	log "github.com/okdp/spark-web-proxy/internal/logging"
+	"github.com/okdp/spark-web-proxy/internal/model"
+	"github.com/okdp/spark-web-proxy/internal/store"
+)
+
+// refusedURL returns the URL of a local port which refuses the connections.
+func refusedURL(t *testing.T) *url.URL {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	upstreamURL, _ := url.Parse("http://" + listener.Addr().String())
+	_ = listener.Close()
+	return upstreamURL
+}
+
+func serveSparkUI(sparkApps store.Store, upstreamURL *url.URL) *httptest.ResponseRecorder {
+	fromURL, _ := url.Parse("/sparkui/spark-1/jobs/")
+	request := httptest.NewRequest(http.MethodGet, fromURL.String(), nil)
+	request.Header.Set("User-Agent", "Mozilla/5.0")
+	recorder := httptest.NewRecorder()
+
+	NewSparkReverseProxy(passthroughHandler{}, upstreamURL, "spark-1").
+		WithSparkUIErrorHandler(fromURL, sparkApps, time.Minute).
+		ServeHTTP(recorder, request)
+	return recorder
+}
+
+// passthroughHandler forwards the requests to the upstream URL as is.
+type passthroughHandler struct{}
+
+func (passthroughHandler) ModifyRequest(upstreamURL *url.URL) func(*http.Request) {
+	return func(req *http.Request) {
+		req.URL.Scheme = upstreamURL.Scheme
+		req.URL.Host = upstreamURL.Host
+	}
+}
+
+func (passthroughHandler) ModifyResponse() func(*http.Response) error {
+	return func(*http.Response) error { return nil }
+}
+
+func Test_SparkUIErrorHandler_StartingDriver(t *testing.T) {
+	// Given: a starting driver which does not listen yet
+	log.SetupGlobalLogger(config.Logging{Level: "info"})
+	sparkApps := store.NewMemoryStore()
+	app := &model.SparkAppInstance{AppID: "spark-1", AppName: "spark-pi", PodName: "spark-pi-driver", Namespace: "spark",
+		PodPhase: "Running", DriverState: "Running", DriverReady: true}
+	app.TransitionTo(model.AppStarting, time.Now())
+	sparkApps.Put(app)
+
+	// When
+	recorder := serveSparkUI(sparkApps, refusedURL(t))
+
+	// Then: the holding page is served and the application is still starting
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code, "status code")
+	assert.Equal(t, "5", recorder.Header().Get("Retry-After"), "Retry-After")
+	assert.Contains(t, recorder.Body.String(), `<meta http-equiv="refresh" content="5">`, "auto refresh")
+	assert.Contains(t, recorder.Body.String(), "spark/spark-pi-driver", "driver pod")
+	stored, _ := sparkApps.Get("spark-1")
+	assert.Equal(t, string(model.AppStarting), stored.Status, "Status")
+
+	// Given: the driver started too long ago
+	app.Transitions = []model.StateTransition{{Status: string(model.AppStarting), TimeEpoch: time.Now().Add(-time.Hour).UnixMilli()}}
+	sparkApps.Put(app)
+
+	// When
+	recorder = serveSparkUI(sparkApps, refusedURL(t))
+
+	// Then: the request is redirected to spark history
+	assert.Equal(t, http.StatusFound, recorder.Code, "status code")
+	stored, _ = sparkApps.Get("spark-1")
+	assert.Equal(t, string(model.AppLost), stored.Status, "Status")
+}
+
+func Test_SparkUIErrorHandler_StartingDriver_APIServerProxy(t *testing.T) {
+	// Given: a starting driver which does not listen yet, reached through the API server proxy
+	log.SetupGlobalLogger(config.Logging{Level: "info"})
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(`{"kind":"Status","apiVersion":"v1","status":"Failure","message":"error trying to reach service: dial tcp 10.0.0.1:4040: connect: connection refused","code":503}`))
+	}))
+	defer apiServer.Close()
+	upstreamURL, _ := url.Parse(apiServer.URL)
+
+	sparkApps := store.NewMemoryStore()
+	app := &model.SparkAppInstance{AppID: "spark-1", AppName: "spark-pi", PodName: "spark-pi-driver", Namespace: "spark", PodPhase: "Running"}
+	app.TransitionTo(model.AppStarting, time.Now())
+	sparkApps.Put(app)
+
+	// When
+	recorder := serveSparkUI(sparkApps, upstreamURL)
+
+	// Then: the holding page is served instead of the API server error
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code, "status code")
+	assert.Contains(t, recorder.Body.String(), `<meta http-equiv="refresh" content="5">`, "auto refresh")
+	stored, _ := sparkApps.Get("spark-1")
+	assert.Equal(t, string(model.AppStarting), stored.Status, "Status")
+
+	// Given: a running application
+	app.TransitionTo(model.AppRunning, time.Now())
+	sparkApps.Put(app)
+
+	// When
+	recorder = serveSparkUI(sparkApps, upstreamURL)
+
+	// Then: the error of the upstream is returned as is
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code, "status code")
+	assert.Contains(t, recorder.Body.String(), "connection refused", "upstream error")
+	stored, _ = sparkApps.Get("spark-1")
+	assert.Equal(t, string(model.AppRunning), stored.Status, "Status")
+}
+
+func Test_SparkUIErrorHandler_PodEvents(t *testing.T) {
+	// Given: a pending driver whose image cannot be pulled
+	log.SetupGlobalLogger(config.Logging{Level: "info"})
+	sparkApps := store.NewMemoryStore()
+	app := &model.SparkAppInstance{AppID: "spark-1", AppName: "spark-pi", PodName: "spark-pi-driver", Namespace: "spark",
+		PodPhase: "Pending", DriverState: "Waiting: ImagePullBackOff"}
+	app.TransitionTo(model.AppStarting, time.Now())
+	app.RecordEvent(model.PodEvent{PodName: "spark-pi-driver", Type: model.EventTypeWarning, Reason: "Failed",
+		Message: `Failed to pull image "spark:missing"`, Count: 3, LastSeenEpoch: time.Now().UnixMilli()}, 10)
+	sparkApps.Put(app)
+
+	// When
+	recorder := serveSparkUI(sparkApps, refusedURL(t))
+
+	// Then: the holding page shows the events
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code, "status code")
+	assert.Contains(t, recorder.Body.String(), `<tr class="warning"><td>Warning</td><td>Failed</td><td>spark-pi-driver</td>`, "event")
+	assert.Contains(t, recorder.Body.String(), "Failed to pull image &#34;spark:missing&#34;", "escaped event message")
+
+	// Given: the driver started too long ago
+	app.Transitions = []model.StateTransition{{Status: string(model.AppStarting), TimeEpoch: time.Now().Add(-time.Hour).UnixMilli()}}
+	sparkApps.Put(app)
+
+	// When
+	recorder = serveSparkUI(sparkApps, refusedURL(t))
+
+	// Then: the error page shows the events instead of redirecting to spark history
+	assert.Equal(t, http.StatusBadGateway, recorder.Code, "status code")
+	assert.Contains(t, recorder.Body.String(), `<a href="/sparkui/spark-1/jobs/">`, "spark history link")
+	assert.Contains(t, recorder.Body.String(), "Failed to pull image", "event")
+	stored, _ := sparkApps.Get("spark-1")
+	assert.Equal(t, string(model.AppLost), stored.Status, "Status")
+}
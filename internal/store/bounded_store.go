@@ -0,0 +1,259 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package store
+
+import (
+	"container/list"
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/okdp/spark-web-proxy/internal/config"
+	log "github.com/okdp/spark-web-proxy/internal/logging"
+	"github.com/okdp/spark-web-proxy/internal/model"
+)
+
+const (
+	// EvictionTTL is the reason of the evictions of the expired applications.
+	EvictionTTL = "ttl"
+	// EvictionSize is the reason of the evictions of the least recently used
+	// applications when the store is full.
+	EvictionSize = "size"
+)
+
+var (
+	// Evictions counts the applications evicted from the store, by reason and status.
+	Evictions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "spark_web_proxy",
+		Subsystem: "store",
+		Name:      "evictions_total",
+		Help:      "Number of the Spark applications evicted from the store, by reason (ttl or size) and status.",
+	}, []string{"reason", "status"})
+
+	// Overflows counts the insertions exceeding the maximum size of the store,
+	// as only live applications were left to evict.
+	Overflows = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "spark_web_proxy",
+		Subsystem: "store",
+		Name:      "overflows_total",
+		Help:      "Number of the insertions exceeding the maximum size of the store, as only live Spark applications were left to evict.",
+	})
+
+	// Size is the number of the applications tracked by the bounded stores.
+	Size = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "spark_web_proxy",
+		Subsystem: "store",
+		Name:      "applications",
+		Help:      "Number of the Spark applications in the store.",
+	})
+)
+
+// lruEntry tracks the recency and the last update of a stored application.
+type lruEntry struct {
+	appID     string
+	status    string
+	updatedAt time.Time
+}
+
+// BoundedStore bounds the size of a Store: the applications expire after the
+// time to live of their status, and the least recently used applications are
+// evicted when the maximum size is reached.
+type BoundedStore struct {
+	Store
+	maxSize         int
+	ttl             map[string]time.Duration
+	janitorInterval time.Duration
+	now             func() time.Time
+
+	mu      sync.Mutex
+	lru     *list.List
+	entries map[string]*list.Element
+}
+
+// NewBoundedStore bounds the given store according to the store configuration.
+// The applications already in the store expire after their TTL from now.
+func NewBoundedStore(backend Store, conf config.Store) *BoundedStore {
+	ttl := make(map[string]time.Duration, len(conf.TTL))
+	for status, duration := range conf.TTL {
+		ttl[strings.ToLower(status)] = duration
+	}
+
+	janitorInterval := conf.JanitorInterval
+	if janitorInterval <= 0 {
+		janitorInterval = time.Minute
+	}
+
+	s := &BoundedStore{
+		Store:           backend,
+		maxSize:         conf.MaxSize,
+		ttl:             ttl,
+		janitorInterval: janitorInterval,
+		now:             time.Now,
+		lru:             list.New(),
+		entries:         make(map[string]*list.Element),
+	}
+
+	// Track the applications already in the backend (e.g. reloaded from disk)
+	for _, app := range backend.List(Filter{}) {
+		s.entries[app.AppID] = s.lru.PushFront(&lruEntry{appID: app.AppID, status: app.Status, updatedAt: s.now()})
+	}
+	Size.Set(float64(s.lru.Len()))
+	return s
+}
+
+// Touch implements Store. The application becomes the most recently used.
+func (s *BoundedStore) Touch(appID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if element, tracked := s.entries[appID]; tracked {
+		s.lru.MoveToFront(element)
+	}
+}
+
+// Put implements Store. The least recently used completed applications are
+// evicted when the maximum size is exceeded. The live applications are never
+// evicted: the maximum size is exceeded instead.
+func (s *BoundedStore) Put(app *model.SparkAppInstance) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Store.Put(app)
+	entry := &lruEntry{appID: app.AppID, status: app.Status, updatedAt: s.now()}
+	if element, tracked := s.entries[app.AppID]; tracked {
+		element.Value = entry
+		s.lru.MoveToFront(element)
+	} else {
+		s.entries[app.AppID] = s.lru.PushFront(entry)
+	}
+
+	victims := make([]*lruEntry, 0)
+	for s.maxSize > 0 && s.lru.Len() > s.maxSize {
+		element := s.leastRecentlyUsed()
+		if element == nil {
+			Overflows.Inc()
+			log.Warn("The store exceeds its maximum size %d with %d live applications", s.maxSize, s.lru.Len())
+			break
+		}
+		victims = append(victims, s.untrack(element))
+	}
+	Size.Set(float64(s.lru.Len()))
+	s.evict(victims, EvictionSize)
+}
+
+// Update implements Store. The updates are made in the background (e.g. health
+// probes, pod events): the time to live of the application restarts only when
+// its status changes, and the application does not become more recently used.
+func (s *BoundedStore) Update(appID string, update func(app *model.SparkAppInstance) bool) (*model.SparkAppInstance, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	app, updated := s.Store.Update(appID, update)
+	if !updated {
+		return app, false
+	}
+	if element, tracked := s.entries[appID]; tracked && element.Value.(*lruEntry).status != app.Status {
+		element.Value = &lruEntry{appID: appID, status: app.Status, updatedAt: s.now()}
+	}
+	return app, true
+}
+
+// Delete implements Store.
+func (s *BoundedStore) Delete(appID string) (*model.SparkAppInstance, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if element, tracked := s.entries[appID]; tracked {
+		s.untrack(element)
+	}
+	Size.Set(float64(s.lru.Len()))
+	return s.Store.Delete(appID)
+}
+
+// RunJanitor periodically evicts the expired applications until the context is done.
+func (s *BoundedStore) RunJanitor(ctx context.Context) {
+	if len(s.ttl) == 0 {
+		return
+	}
+
+	log.Info("Running store janitor every %s with the TTLs: %v", s.janitorInterval, s.ttl)
+	ticker := time.NewTicker(s.janitorInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.EvictExpired()
+		}
+	}
+}
+
+// EvictExpired evicts the applications whose status time to live has elapsed
+// since their last update.
+func (s *BoundedStore) EvictExpired() {
+	now := s.now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	victims := make([]*lruEntry, 0)
+	for element := s.lru.Back(); element != nil; {
+		previous := element.Prev()
+		entry := element.Value.(*lruEntry)
+		if ttl := s.ttl[strings.ToLower(entry.status)]; ttl > 0 && now.Sub(entry.updatedAt) > ttl {
+			victims = append(victims, s.untrack(element))
+		}
+		element = previous
+	}
+	Size.Set(float64(s.lru.Len()))
+	s.evict(victims, EvictionTTL)
+}
+
+// leastRecentlyUsed returns the least recently used completed application,
+// or nil if none is completed.
+func (s *BoundedStore) leastRecentlyUsed() *list.Element {
+	for element := s.lru.Back(); element != nil; element = element.Prev() {
+		if model.SparkAppStatus(element.Value.(*lruEntry).status).IsCompleted() {
+			return element
+		}
+	}
+	return nil
+}
+
+// untrack removes the element from the LRU list and returns its entry.
+func (s *BoundedStore) untrack(element *list.Element) *lruEntry {
+	entry := element.Value.(*lruEntry)
+	s.lru.Remove(element)
+	delete(s.entries, entry.appID)
+	return entry
+}
+
+// evict removes the untracked applications from the underlying store. It is called
+// under the lock, so that an application put again concurrently is not deleted.
+func (s *BoundedStore) evict(victims []*lruEntry, reason string) {
+	for _, victim := range victims {
+		s.Store.Delete(victim.appID)
+		Evictions.WithLabelValues(reason, victim.status).Inc()
+		log.Debug("The application '%s' (%s) was evicted from the store: %s", victim.appID, victim.status, reason)
+	}
+}
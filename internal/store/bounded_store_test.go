@@ -0,0 +1,165 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/okdp/spark-web-proxy/internal/config"
+	log "github.com/okdp/spark-web-proxy/internal/logging"
+	"github.com/okdp/spark-web-proxy/internal/model"
+)
+
+func Test_BoundedStore_LRU(t *testing.T) {
+	// Given
+	log.SetupGlobalLogger(config.Logging{Level: "info"})
+	s := NewBoundedStore(NewMemoryStore(), config.Store{MaxSize: 3})
+	evictions := testutil.ToFloat64(Evictions.WithLabelValues(EvictionSize, "Unknown"))
+	s.Put(&model.SparkAppInstance{AppID: "spark-running", Status: "Running"})
+	s.Put(&model.SparkAppInstance{AppID: "spark-1", Status: "Unknown"})
+	s.Put(&model.SparkAppInstance{AppID: "spark-2", Status: "Unknown"})
+
+	// When: spark-1 is used, spark-2 is read in the background, then the store is full
+	s.Touch("spark-1")
+	s.Get("spark-2")
+	s.Put(&model.SparkAppInstance{AppID: "spark-3", Status: "Unknown"})
+
+	// Then: the least recently used completed application is evicted
+	_, found := s.Get("spark-2")
+	assert.False(t, found, "spark-2 should be evicted")
+	for _, appID := range []string{"spark-running", "spark-1", "spark-3"} {
+		_, found = s.Get(appID)
+		assert.True(t, found, appID)
+	}
+	assert.Equal(t, evictions+1, testutil.ToFloat64(Evictions.WithLabelValues(EvictionSize, "Unknown")), "evictions")
+}
+
+func Test_BoundedStore_LRU_Live_Applications(t *testing.T) {
+	// Given: a full store of live applications
+	log.SetupGlobalLogger(config.Logging{Level: "info"})
+	s := NewBoundedStore(NewMemoryStore(), config.Store{MaxSize: 2})
+	overflows := testutil.ToFloat64(Overflows)
+	s.Put(&model.SparkAppInstance{AppID: "spark-running", Status: "Running"})
+	s.Put(&model.SparkAppInstance{AppID: "spark-pending", Status: "Pending"})
+
+	// When
+	s.Put(&model.SparkAppInstance{AppID: "spark-starting", Status: "Starting"})
+
+	// Then: the live applications are kept beyond the maximum size
+	assert.Len(t, s.List(Filter{}), 3, "applications")
+	assert.Equal(t, overflows+1, testutil.ToFloat64(Overflows), "overflows")
+}
+
+func Test_BoundedStore_TTL(t *testing.T) {
+	// Given
+	log.SetupGlobalLogger(config.Logging{Level: "info"})
+	s := NewBoundedStore(NewMemoryStore(), config.Store{TTL: map[string]time.Duration{"Unknown": time.Minute}})
+	now := time.Now()
+	s.now = func() time.Time { return now }
+	evictions := testutil.ToFloat64(Evictions.WithLabelValues(EvictionTTL, "Unknown"))
+	s.Put(&model.SparkAppInstance{AppID: "spark-running", Status: "Running"})
+	s.Put(&model.SparkAppInstance{AppID: "spark-unknown", Status: "Unknown"})
+
+	// When: the time to live is not elapsed
+	now = now.Add(30 * time.Second)
+	s.EvictExpired()
+
+	// Then
+	_, found := s.Get("spark-unknown")
+	assert.True(t, found, "spark-unknown should not be expired yet")
+
+	// When
+	now = now.Add(time.Minute)
+	s.EvictExpired()
+
+	// Then
+	_, found = s.Get("spark-unknown")
+	assert.False(t, found, "spark-unknown should be expired")
+	_, found = s.Get("spark-running")
+	assert.True(t, found, "the running applications have no TTL")
+	assert.Equal(t, evictions+1, testutil.ToFloat64(Evictions.WithLabelValues(EvictionTTL, "Unknown")), "evictions")
+}
+
+func Test_BoundedStore_TTL_Update(t *testing.T) {
+	// Given: an application lost a while ago
+	log.SetupGlobalLogger(config.Logging{Level: "info"})
+	s := NewBoundedStore(NewMemoryStore(), config.Store{TTL: map[string]time.Duration{"Lost": time.Minute}})
+	now := time.Now()
+	s.now = func() time.Time { return now }
+	s.Put(&model.SparkAppInstance{AppID: "spark-lost", Status: "Lost"})
+
+	// When: the application is updated without changing its status (e.g. health probes)
+	for i := 0; i < 3; i++ {
+		now = now.Add(30 * time.Second)
+		s.Update("spark-lost", func(app *model.SparkAppInstance) bool {
+			app.Health = &model.Health{LastProbeEpoch: now.UnixMilli()}
+			return true
+		})
+	}
+	s.EvictExpired()
+
+	// Then: the time to live is not restarted
+	_, found := s.Get("spark-lost")
+	assert.False(t, found, "spark-lost should be expired")
+}
+
+// deleteHookStore runs a hook before deleting an application.
+type deleteHookStore struct {
+	Store
+	hook func(appID string)
+}
+
+func (s *deleteHookStore) Delete(appID string) (*model.SparkAppInstance, bool) {
+	s.hook(appID)
+	return s.Store.Delete(appID)
+}
+
+func Test_BoundedStore_Eviction_Concurrent_Put(t *testing.T) {
+	// Given: an expired application
+	log.SetupGlobalLogger(config.Logging{Level: "info"})
+	backend := &deleteHookStore{Store: NewMemoryStore()}
+	s := NewBoundedStore(backend, config.Store{TTL: map[string]time.Duration{"Unknown": time.Minute}})
+	now := time.Now()
+	s.now = func() time.Time { return now }
+	s.Put(&model.SparkAppInstance{AppID: "spark-1", Status: "Unknown"})
+	now = now.Add(2 * time.Minute)
+
+	// When: the application is put again while it is evicted
+	put := make(chan struct{})
+	backend.hook = func(appID string) {
+		go func() {
+			s.Put(&model.SparkAppInstance{AppID: appID, Status: "Running"})
+			close(put)
+		}()
+		select {
+		case <-put:
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+	s.EvictExpired()
+	<-put
+
+	// Then: the application put again is kept
+	app, found := s.Get("spark-1")
+	assert.True(t, found, "spark-1 should be kept")
+	assert.Equal(t, "Running", app.Status, "Status")
+	assert.Len(t, s.entries, 1, "tracked applications")
+}
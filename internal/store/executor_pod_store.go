@@ -0,0 +1,191 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package store
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	log "github.com/okdp/spark-web-proxy/internal/logging"
+	"github.com/okdp/spark-web-proxy/internal/model"
+)
+
+// ExecutorPodStore is the in-memory registry of the executor pods of the Spark
+// applications, keyed by application ID and executor ID.
+//
+// The driver deletes the pods of the failed executors (e.g. OOMKilled), so the
+// deleted executor pods are kept with their last state, up to maxDeletedPods per
+// application. The executor pods of an application are forgotten once the
+// application is no longer tracked (see Prune).
+type ExecutorPodStore struct {
+	mu             sync.RWMutex
+	pods           map[string]map[string]*model.ExecutorPod
+	byPod          map[string]*model.ExecutorPod
+	maxDeletedPods int
+}
+
+// NewExecutorPodStore creates an empty executor pod store keeping up to
+// maxDeletedPods deleted executor pods per application.
+func NewExecutorPodStore(maxDeletedPods int) *ExecutorPodStore {
+	if maxDeletedPods < 0 {
+		maxDeletedPods = 0
+	}
+	return &ExecutorPodStore{
+		pods:           make(map[string]map[string]*model.ExecutorPod),
+		byPod:          make(map[string]*model.ExecutorPod),
+		maxDeletedPods: maxDeletedPods,
+	}
+}
+
+// Put adds an executor pod or replaces the executor pod with the same
+// application and executor IDs. A deleted executor pod (DeletedAtEpoch set)
+// evicts the oldest deleted executor pods of the application beyond the limit.
+func (s *ExecutorPodStore) Put(pod *model.ExecutorPod) {
+	stored := *pod
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pods, found := s.pods[pod.AppID]
+	if !found {
+		pods = make(map[string]*model.ExecutorPod)
+		s.pods[pod.AppID] = pods
+	}
+	if previous, found := pods[pod.ExecutorID]; found {
+		delete(s.byPod, executorPodKey(previous))
+	}
+	pods[pod.ExecutorID] = &stored
+	s.byPod[executorPodKey(&stored)] = &stored
+
+	if pod.DeletedAtEpoch != 0 {
+		s.evictDeleted(pods)
+	}
+}
+
+// List returns the executor pods of an application sorted by executor ID.
+func (s *ExecutorPodStore) List(appID string) []*model.ExecutorPod {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	pods := make([]*model.ExecutorPod, 0, len(s.pods[appID]))
+	for _, pod := range s.pods[appID] {
+		copied := *pod
+		pods = append(pods, &copied)
+	}
+	sort.Slice(pods, func(i, j int) bool { return LessExecutorID(pods[i].ExecutorID, pods[j].ExecutorID) })
+	return pods
+}
+
+// GetPod retrieves an executor pod by cluster, namespace and pod name.
+func (s *ExecutorPodStore) GetPod(cluster string, namespace string, podName string) (*model.ExecutorPod, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	pod, found := s.byPod[podKey(cluster, namespace, podName)]
+	if !found {
+		return nil, false
+	}
+	copied := *pod
+	return &copied, true
+}
+
+// Forget removes the executor pods of an application.
+func (s *ExecutorPodStore) Forget(appID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, pod := range s.pods[appID] {
+		delete(s.byPod, executorPodKey(pod))
+	}
+	delete(s.pods, appID)
+}
+
+// Prune forgets the executor pods of the applications which are no longer in
+// the given application store.
+func (s *ExecutorPodStore) Prune(sparkApps Store) {
+	s.mu.RLock()
+	appIDs := make([]string, 0, len(s.pods))
+	for appID := range s.pods {
+		appIDs = append(appIDs, appID)
+	}
+	s.mu.RUnlock()
+
+	for _, appID := range appIDs {
+		if _, found := sparkApps.Get(appID); !found {
+			s.Forget(appID)
+			log.Debug("Forgot the executor pods of the application '%s'", appID)
+		}
+	}
+}
+
+// RunJanitor prunes the executor pods of the untracked applications at the
+// given interval until the context is done.
+func (s *ExecutorPodStore) RunJanitor(ctx context.Context, sparkApps Store, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.Prune(sparkApps)
+		}
+	}
+}
+
+// evictDeleted removes the oldest deleted executor pods beyond maxDeletedPods.
+func (s *ExecutorPodStore) evictDeleted(pods map[string]*model.ExecutorPod) {
+	deleted := make([]*model.ExecutorPod, 0)
+	for _, pod := range pods {
+		if pod.DeletedAtEpoch != 0 {
+			deleted = append(deleted, pod)
+		}
+	}
+	if len(deleted) <= s.maxDeletedPods {
+		return
+	}
+
+	sort.Slice(deleted, func(i, j int) bool { return deleted[i].DeletedAtEpoch < deleted[j].DeletedAtEpoch })
+	for _, pod := range deleted[:len(deleted)-s.maxDeletedPods] {
+		delete(pods, pod.ExecutorID)
+		delete(s.byPod, executorPodKey(pod))
+	}
+}
+
+// executorPodKey returns the key of an executor pod in the pod index.
+func executorPodKey(pod *model.ExecutorPod) string {
+	return podKey(pod.Cluster, pod.Namespace, pod.PodName)
+}
+
+// LessExecutorID orders the executor IDs numerically, the non numeric IDs
+// (e.g. "driver") first.
+func LessExecutorID(a string, b string) bool {
+	na, errA := strconv.Atoi(a)
+	nb, errB := strconv.Atoi(b)
+	switch {
+	case errA == nil && errB == nil:
+		return na < nb
+	case errA != nil && errB != nil:
+		return a < b
+	default:
+		return errA != nil
+	}
+}
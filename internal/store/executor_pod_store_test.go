@@ -0,0 +1,74 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/okdp/spark-web-proxy/internal/model"
+)
+
+func Test_ExecutorPodStore(t *testing.T) {
+	// Given: a store keeping 2 deleted executor pods per application
+	executorPods := NewExecutorPodStore(2)
+	executorPods.Put(&model.ExecutorPod{AppID: "spark-1", ExecutorID: "10"})
+	executorPods.Put(&model.ExecutorPod{AppID: "spark-1", ExecutorID: "2", Phase: "Pending"})
+	executorPods.Put(&model.ExecutorPod{AppID: "spark-2", ExecutorID: "1", Cluster: "default", Namespace: "spark", PodName: "spark-2-exec-1"})
+
+	// When
+	executorPods.Put(&model.ExecutorPod{AppID: "spark-1", ExecutorID: "2", Phase: "Running"})
+
+	// Then: the executor pods are sorted by numeric executor ID
+	pods := executorPods.List("spark-1")
+	if assert.Len(t, pods, 2, "executor pods") {
+		assert.Equal(t, "2", pods[0].ExecutorID, "first executor")
+		assert.Equal(t, "Running", pods[0].Phase, "replaced executor pod")
+		assert.Equal(t, "10", pods[1].ExecutorID, "second executor")
+	}
+
+	// When: 3 executor pods are deleted
+	executorPods.Put(&model.ExecutorPod{AppID: "spark-1", ExecutorID: "2", DeletedAtEpoch: 2000})
+	executorPods.Put(&model.ExecutorPod{AppID: "spark-1", ExecutorID: "10", DeletedAtEpoch: 1000})
+	executorPods.Put(&model.ExecutorPod{AppID: "spark-1", ExecutorID: "11", DeletedAtEpoch: 3000})
+
+	// Then: the oldest deleted executor pod is evicted
+	pods = executorPods.List("spark-1")
+	if assert.Len(t, pods, 2, "executor pods") {
+		assert.Equal(t, "2", pods[0].ExecutorID, "first executor")
+		assert.Equal(t, "11", pods[1].ExecutorID, "second executor")
+	}
+
+	// Then: the executor pods are indexed by pod
+	pod, found := executorPods.GetPod("default", "spark", "spark-2-exec-1")
+	if assert.True(t, found, "spark-2 executor pod") {
+		assert.Equal(t, "spark-2", pod.AppID, "AppID")
+		assert.Equal(t, "1", pod.ExecutorID, "ExecutorID")
+	}
+
+	// When: spark-2 is no longer tracked
+	sparkApps := NewMemoryStore()
+	sparkApps.Put(&model.SparkAppInstance{AppID: "spark-1"})
+	executorPods.Prune(sparkApps)
+
+	// Then
+	assert.Len(t, executorPods.List("spark-1"), 2, "spark-1 executor pods")
+	assert.Empty(t, executorPods.List("spark-2"), "spark-2 executor pods")
+	_, found = executorPods.GetPod("default", "spark", "spark-2-exec-1")
+	assert.False(t, found, "spark-2 executor pod")
+}
@@ -0,0 +1,256 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package store
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	log "github.com/okdp/spark-web-proxy/internal/logging"
+	"github.com/okdp/spark-web-proxy/internal/model"
+)
+
+// watchBufferSize is the number of events buffered per watcher. The events
+// are dropped when a watcher does not keep up.
+const watchBufferSize = 256
+
+// index is a secondary index of the application IDs by key.
+type index map[string]map[string]struct{}
+
+func (idx index) add(key string, appID string) {
+	if key == "" {
+		return
+	}
+	ids, found := idx[key]
+	if !found {
+		ids = make(map[string]struct{})
+		idx[key] = ids
+	}
+	ids[appID] = struct{}{}
+}
+
+func (idx index) remove(key string, appID string) {
+	ids, found := idx[key]
+	if !found {
+		return
+	}
+	delete(ids, appID)
+	if len(ids) == 0 {
+		delete(idx, key)
+	}
+}
+
+// MemoryStore is the default in-memory Store, with secondary indexes by
+// pod, namespace and user.
+type MemoryStore struct {
+	mu          sync.RWMutex
+	apps        map[string]*model.SparkAppInstance
+	byPod       index
+	byNamespace index
+	byUser      index
+
+	watchersMu sync.Mutex
+	watchers   map[chan Event]struct{}
+}
+
+// NewMemoryStore creates an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		apps:        make(map[string]*model.SparkAppInstance),
+		byPod:       make(index),
+		byNamespace: make(index),
+		byUser:      make(index),
+		watchers:    make(map[chan Event]struct{}),
+	}
+}
+
+func podKey(cluster string, namespace string, podName string) string {
+	if namespace == "" || podName == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/%s/%s", cluster, namespace, podName)
+}
+
+func namespaceKey(cluster string, namespace string) string {
+	if namespace == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/%s", cluster, namespace)
+}
+
+// Get implements Store.
+func (s *MemoryStore) Get(appID string) (*model.SparkAppInstance, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	app, found := s.apps[appID]
+	if !found {
+		return &model.SparkAppInstance{}, false
+	}
+	copied := *app
+	return &copied, true
+}
+
+// Put implements Store.
+func (s *MemoryStore) Put(app *model.SparkAppInstance) {
+	copied := *app
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, found := s.apps[app.AppID]
+	if found {
+		s.unindex(previous)
+	}
+	s.apps[app.AppID] = &copied
+	s.index(&copied)
+
+	eventType := Added
+	if found {
+		eventType = Updated
+	}
+	s.notify(eventType, copied)
+}
+
+// Update implements Store.
+func (s *MemoryStore) Update(appID string, update func(app *model.SparkAppInstance) bool) (*model.SparkAppInstance, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, found := s.apps[appID]
+	if !found {
+		return &model.SparkAppInstance{}, false
+	}
+	copied := *previous
+	if !update(&copied) {
+		return &copied, false
+	}
+	// The application ID is the store key
+	copied.AppID = appID
+	s.unindex(previous)
+	s.apps[appID] = &copied
+	s.index(&copied)
+
+	s.notify(Updated, copied)
+	updated := copied
+	return &updated, true
+}
+
+// Touch implements Store. The in-memory store does not track the usage.
+func (s *MemoryStore) Touch(string) {}
+
+// Delete implements Store.
+func (s *MemoryStore) Delete(appID string) (*model.SparkAppInstance, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	app, found := s.apps[appID]
+	if !found {
+		return &model.SparkAppInstance{}, false
+	}
+	s.unindex(app)
+	delete(s.apps, appID)
+	s.notify(Deleted, *app)
+	return app, true
+}
+
+// List implements Store. The lookups by pod (cluster, namespace and pod name),
+// by namespace (cluster and namespace) or by user use the secondary indexes.
+func (s *MemoryStore) List(filter Filter) []*model.SparkAppInstance {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var ids map[string]struct{}
+	indexed := true
+	switch {
+	case filter.Cluster != "" && filter.Namespace != "" && filter.PodName != "":
+		ids = s.byPod[podKey(filter.Cluster, filter.Namespace, filter.PodName)]
+	case filter.Cluster != "" && filter.Namespace != "":
+		ids = s.byNamespace[namespaceKey(filter.Cluster, filter.Namespace)]
+	case filter.User != "":
+		ids = s.byUser[filter.User]
+	default:
+		indexed = false
+	}
+
+	apps := make([]*model.SparkAppInstance, 0)
+	appendIfMatches := func(app *model.SparkAppInstance) {
+		if filter.Matches(app) {
+			copied := *app
+			apps = append(apps, &copied)
+		}
+	}
+
+	if indexed {
+		for appID := range ids {
+			appendIfMatches(s.apps[appID])
+		}
+		return apps
+	}
+	for _, app := range s.apps {
+		appendIfMatches(app)
+	}
+	return apps
+}
+
+// Watch implements Store.
+func (s *MemoryStore) Watch(ctx context.Context) <-chan Event {
+	events := make(chan Event, watchBufferSize)
+
+	s.watchersMu.Lock()
+	s.watchers[events] = struct{}{}
+	s.watchersMu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		s.watchersMu.Lock()
+		delete(s.watchers, events)
+		close(events)
+		s.watchersMu.Unlock()
+	}()
+
+	return events
+}
+
+func (s *MemoryStore) index(app *model.SparkAppInstance) {
+	s.byPod.add(podKey(app.Cluster, app.Namespace, app.PodName), app.AppID)
+	s.byNamespace.add(namespaceKey(app.Cluster, app.Namespace), app.AppID)
+	s.byUser.add(app.User, app.AppID)
+}
+
+func (s *MemoryStore) unindex(app *model.SparkAppInstance) {
+	s.byPod.remove(podKey(app.Cluster, app.Namespace, app.PodName), app.AppID)
+	s.byNamespace.remove(namespaceKey(app.Cluster, app.Namespace), app.AppID)
+	s.byUser.remove(app.User, app.AppID)
+}
+
+// notify sends the change to the watchers without blocking the writers. It is
+// called under the write lock so that the watchers see the changes in order.
+func (s *MemoryStore) notify(eventType EventType, app model.SparkAppInstance) {
+	s.watchersMu.Lock()
+	defer s.watchersMu.Unlock()
+
+	for events := range s.watchers {
+		copied := app
+		select {
+		case events <- Event{Type: eventType, App: &copied}:
+		default:
+			log.Warn("The store watcher does not keep up, dropping the event %s of the application '%s'", eventType, app.AppID)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package store
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/okdp/spark-web-proxy/internal/model"
+)
+
+func appIDs(apps []*model.SparkAppInstance) []string {
+	ids := make([]string, 0, len(apps))
+	for _, app := range apps {
+		ids = append(ids, app.AppID)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func Test_MemoryStore_List(t *testing.T) {
+	// Given
+	s := NewMemoryStore()
+	s.Put(&model.SparkAppInstance{AppID: "spark-1", Cluster: "paris", Namespace: "spark", PodName: "kernel", User: "alice", Status: "Running"})
+	s.Put(&model.SparkAppInstance{AppID: "spark-2", Cluster: "paris", Namespace: "spark", PodName: "kernel", User: "bob", Status: "Running"})
+	s.Put(&model.SparkAppInstance{AppID: "spark-3", Cluster: "paris", Namespace: "spark", PodName: "spark-pi-driver", User: "alice", Status: "Succeeded"})
+	s.Put(&model.SparkAppInstance{AppID: "spark-4", Cluster: "london", Namespace: "spark", PodName: "kernel", User: "alice", Status: "Running"})
+
+	// Then
+	assert.Equal(t, []string{"spark-1", "spark-2"}, appIDs(s.List(Filter{Cluster: "paris", Namespace: "spark", PodName: "kernel"})), "by pod")
+	assert.Equal(t, []string{"spark-1", "spark-2", "spark-3"}, appIDs(s.List(Filter{Cluster: "paris", Namespace: "spark"})), "by namespace")
+	assert.Equal(t, []string{"spark-1", "spark-3", "spark-4"}, appIDs(s.List(Filter{User: "alice"})), "by user")
+	assert.Equal(t, []string{"spark-1", "spark-4"}, appIDs(s.List(Filter{User: "alice", Running: true})), "running by user")
+	assert.Equal(t, []string{"spark-1", "spark-2", "spark-4"}, appIDs(s.List(Filter{Running: true})), "running")
+
+	// When: an application moves to another pod
+	s.Put(&model.SparkAppInstance{AppID: "spark-2", Cluster: "paris", Namespace: "spark", PodName: "connect", User: "bob", Status: "Running"})
+
+	// Then: the indexes are updated
+	assert.Equal(t, []string{"spark-1"}, appIDs(s.List(Filter{Cluster: "paris", Namespace: "spark", PodName: "kernel"})), "by pod")
+	assert.Equal(t, []string{"spark-2"}, appIDs(s.List(Filter{Cluster: "paris", Namespace: "spark", PodName: "connect"})), "by new pod")
+
+	// When
+	deleted := DeleteAll(s, Filter{Cluster: "paris", Namespace: "spark"})
+
+	// Then
+	assert.Equal(t, []string{"spark-1", "spark-2", "spark-3"}, appIDs(deleted), "deleted")
+	assert.Empty(t, s.List(Filter{User: "bob"}), "by user after delete")
+	assert.Equal(t, []string{"spark-4"}, appIDs(s.List(Filter{})), "all")
+}
+
+func Test_MemoryStore_Copies(t *testing.T) {
+	// Given
+	s := NewMemoryStore()
+	app := &model.SparkAppInstance{AppID: "spark-1", Status: "Running", Source: model.SourcePod}
+	s.Put(app)
+
+	// When
+	app.Status = "Failed"
+	stored, _ := s.Get("spark-1")
+	stored.Status = "Succeeded"
+
+	// Then
+	stored, found := s.Get("spark-1")
+	assert.True(t, found, "found")
+	assert.Equal(t, "Running", stored.Status, "Status")
+
+	// When
+	MarkLost(s, "spark-1")
+
+	// Then
+	stored, _ = s.Get("spark-1")
+	assert.True(t, stored.IsCompleted(), "completed")
+	assert.Equal(t, "Lost", stored.Status, "Status")
+	assert.True(t, stored.StatusOverride, "StatusOverride")
+	assert.Equal(t, model.SourcePod, stored.Source, "Source")
+}
+
+func Test_MemoryStore_Watch(t *testing.T) {
+	// Given
+	s := NewMemoryStore()
+	ctx, cancel := context.WithCancel(context.Background())
+	events := s.Watch(ctx)
+
+	// When
+	s.Put(&model.SparkAppInstance{AppID: "spark-1", Status: "Pending"})
+	s.Put(&model.SparkAppInstance{AppID: "spark-1", Status: "Running"})
+	s.Delete("spark-1")
+	s.Delete("spark-unknown")
+
+	// Then
+	event := <-events
+	assert.Equal(t, Added, event.Type, "Added")
+	assert.Equal(t, "Pending", event.App.Status, "Added status")
+	event = <-events
+	assert.Equal(t, Updated, event.Type, "Updated")
+	assert.Equal(t, "Running", event.App.Status, "Updated status")
+	event = <-events
+	assert.Equal(t, Deleted, event.Type, "Deleted")
+	assert.Equal(t, "spark-1", event.App.AppID, "Deleted app")
+
+	// When
+	cancel()
+
+	// Then: the channel is closed
+	for range events {
+	}
+}
+
+func Test_MemoryStore_Update(t *testing.T) {
+	// Given
+	s := NewMemoryStore()
+	s.Put(&model.SparkAppInstance{AppID: "spark-1", Status: "Pending", Cluster: "paris", Namespace: "spark", PodName: "driver"})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := s.Watch(ctx)
+
+	// When: the update function declines the change
+	app, updated := s.Update("spark-1", func(app *model.SparkAppInstance) bool {
+		app.Status = "Failed"
+		return false
+	})
+
+	// Then
+	assert.False(t, updated, "declined")
+	stored, _ := s.Get("spark-1")
+	assert.Equal(t, "Pending", stored.Status, "Status")
+
+	// When
+	app, updated = s.Update("spark-1", func(app *model.SparkAppInstance) bool {
+		app.Status = "Running"
+		app.PodName = "driver-2"
+		return true
+	})
+
+	// Then: the application and its indexes are updated
+	assert.True(t, updated, "updated")
+	assert.Equal(t, "Running", app.Status, "returned status")
+	stored, _ = s.Get("spark-1")
+	assert.Equal(t, "Running", stored.Status, "Status")
+	assert.Empty(t, s.List(Filter{Cluster: "paris", Namespace: "spark", PodName: "driver"}), "previous pod")
+	assert.Equal(t, []string{"spark-1"}, appIDs(s.List(Filter{Cluster: "paris", Namespace: "spark", PodName: "driver-2"})), "new pod")
+	event := <-events
+	assert.Equal(t, Updated, event.Type, "Updated")
+
+	// When: the application is not stored
+	_, updated = s.Update("spark-unknown", func(*model.SparkAppInstance) bool {
+		return true
+	})
+
+	// Then
+	assert.False(t, updated, "unknown")
+	_, found := s.Get("spark-unknown")
+	assert.False(t, found, "not added")
+}
+
+func Test_MemoryStore_Watch_Order(t *testing.T) {
+	// Given
+	s := NewMemoryStore()
+	s.Put(&model.SparkAppInstance{AppID: "spark-1"})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := s.Watch(ctx)
+
+	// When: concurrent writers update the application
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 16; j++ {
+				s.Update("spark-1", func(app *model.SparkAppInstance) bool {
+					app.StartTimeEpoch++
+					return true
+				})
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Then: the watcher sees the updates in order
+	for i := int64(1); i <= 128; i++ {
+		event := <-events
+		assert.Equal(t, i, event.App.StartTimeEpoch, "Update order")
+	}
+}
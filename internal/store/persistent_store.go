@@ -0,0 +1,212 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/okdp/spark-web-proxy/internal/config"
+	log "github.com/okdp/spark-web-proxy/internal/logging"
+	"github.com/okdp/spark-web-proxy/internal/model"
+)
+
+// persistLockStripes is the number of the locks serializing the writes of the applications.
+const persistLockStripes = 64
+
+// sparkAppsBucket is the bbolt bucket of the Spark application records, keyed by application ID.
+var sparkAppsBucket = []byte("sparkapps")
+
+// persistentRecord is the on-disk representation of a Spark application.
+type persistentRecord struct {
+	App       *model.SparkAppInstance `json:"app"`
+	CreatedAt time.Time               `json:"createdAt"`
+	UpdatedAt time.Time               `json:"updatedAt"`
+	// Registrant is persisted apart, as it is not serialized with the application.
+	Registrant string `json:"registrant,omitempty"`
+}
+
+// PersistentStore persists the Spark applications of a store in an embedded
+// bbolt database file, and reloads them on startup.
+//
+// The applications discovered by the informers that are not completed are not
+// reloaded, as the informers list them again on startup and the driver pods
+// may have gone away in the meantime.
+type PersistentStore struct {
+	Store
+	db *bolt.DB
+	// locks serialize the in-memory and on-disk writes per application, so that the
+	// records are written in order and a deleted application is not written again.
+	locks [persistLockStripes]sync.Mutex
+}
+
+// NewPersistentStore opens (or creates) the database file of the persistence
+// configuration and reloads its applications into the given store.
+func NewPersistentStore(local Store, conf config.Persistence) (*PersistentStore, error) {
+	if err := os.MkdirAll(filepath.Dir(conf.Path), 0o750); err != nil {
+		return nil, err
+	}
+
+	db, err := bolt.Open(conf.Path, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("unable to open the application registry %s: %w", conf.Path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(sparkAppsBucket)
+		return err
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	s := &PersistentStore{Store: local, db: db}
+	if err := s.reload(); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Put implements Store.
+func (s *PersistentStore) Put(app *model.SparkAppInstance) {
+	unlock := s.lock(app.AppID)
+	defer unlock()
+
+	s.Store.Put(app)
+	s.persist(app)
+}
+
+// Update implements Store.
+func (s *PersistentStore) Update(appID string, update func(app *model.SparkAppInstance) bool) (*model.SparkAppInstance, bool) {
+	unlock := s.lock(appID)
+	defer unlock()
+
+	app, updated := s.Store.Update(appID, update)
+	if updated {
+		s.persist(app)
+	}
+	return app, updated
+}
+
+// persist writes the application record to the database file.
+func (s *PersistentStore) persist(app *model.SparkAppInstance) {
+	copied := *app
+	err := s.db.Batch(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(sparkAppsBucket)
+		now := time.Now()
+		record := &persistentRecord{App: &copied, CreatedAt: now, UpdatedAt: now, Registrant: app.Registrant}
+
+		existing := &persistentRecord{}
+		if value := bucket.Get([]byte(app.AppID)); value != nil && json.Unmarshal(value, existing) == nil {
+			record.CreatedAt = existing.CreatedAt
+		}
+
+		value, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+		return bucket.Put([]byte(app.AppID), value)
+	})
+	if err != nil {
+		log.Error("Unable to persist the application '%s': %+v", app.AppID, err)
+	}
+}
+
+// Delete implements Store.
+func (s *PersistentStore) Delete(appID string) (*model.SparkAppInstance, bool) {
+	unlock := s.lock(appID)
+	defer unlock()
+
+	app, found := s.Store.Delete(appID)
+
+	err := s.db.Batch(func(tx *bolt.Tx) error {
+		return tx.Bucket(sparkAppsBucket).Delete([]byte(appID))
+	})
+	if err != nil {
+		log.Error("Unable to remove the persisted application '%s': %+v", appID, err)
+	}
+	return app, found
+}
+
+// lock locks the writes of the given application and returns the unlock function.
+func (s *PersistentStore) lock(appID string) func() {
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(appID))
+	mu := &s.locks[hash.Sum32()%persistLockStripes]
+	mu.Lock()
+	return mu.Unlock
+}
+
+// Close closes the database file.
+func (s *PersistentStore) Close() error {
+	return s.db.Close()
+}
+
+// reload loads the persisted applications into the local store. The applications
+// discovered by the informers that are not completed are dropped.
+func (s *PersistentStore) reload() error {
+	var reloaded, dropped int
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(sparkAppsBucket)
+		stale := make([][]byte, 0)
+
+		err := bucket.ForEach(func(key, value []byte) error {
+			record := &persistentRecord{}
+			if err := json.Unmarshal(value, record); err != nil || record.App == nil {
+				log.Warn("Dropping the invalid persisted application '%s': %v", string(key), err)
+				stale = append(stale, key)
+				return nil
+			}
+			if isWatched(record.App) {
+				stale = append(stale, key)
+				return nil
+			}
+			record.App.Registrant = record.Registrant
+			s.Store.Put(record.App)
+			reloaded++
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, key := range stale {
+			if err := bucket.Delete(key); err != nil {
+				return err
+			}
+		}
+		dropped = len(stale)
+		return nil
+	})
+
+	log.Info("Reloaded %d application(s) from the application registry %s (%d dropped)", reloaded, s.db.Path(), dropped)
+	return err
+}
+
+// isWatched reports whether the application is kept up to date by the informers.
+func isWatched(app *model.SparkAppInstance) bool {
+	return (app.Source == model.SourcePod || app.Source == model.SourceOperator) && !app.IsCompleted()
+}
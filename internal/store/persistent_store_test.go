@@ -0,0 +1,109 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package store
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/okdp/spark-web-proxy/internal/config"
+	log "github.com/okdp/spark-web-proxy/internal/logging"
+	"github.com/okdp/spark-web-proxy/internal/model"
+)
+
+func Test_PersistentStore_Reload(t *testing.T) {
+	// Given
+	log.SetupGlobalLogger(config.Logging{Level: "info"})
+	conf := config.Persistence{Enabled: true, Path: filepath.Join(t.TempDir(), "data", "registry.db")}
+	s, err := NewPersistentStore(NewMemoryStore(), conf)
+	assert.NoError(t, err)
+
+	s.Put(&model.SparkAppInstance{AppID: "spark-client", Status: "Unknown", Source: model.SourceHistory, BaseURL: "http://10.0.0.1:4040"})
+	s.Put(&model.SparkAppInstance{AppID: "spark-override", Status: "Lost", Source: model.SourcePod, StatusOverride: true, PodName: "spark-pi-driver", Namespace: "spark"})
+	s.Put(&model.SparkAppInstance{AppID: "spark-succeeded", Status: "Succeeded", Source: model.SourcePod, PodName: "spark-pi-driver", Namespace: "spark"})
+	s.Put(&model.SparkAppInstance{AppID: "spark-registered", Status: "Running", Source: model.SourceRegistration, Registrant: "launcher-a"})
+	s.Put(&model.SparkAppInstance{AppID: "spark-running", Status: "Running", Source: model.SourcePod})
+	s.Put(&model.SparkAppInstance{AppID: "spark-deleted", Status: "Unknown", Source: model.SourceHistory})
+	s.Delete("spark-deleted")
+	assert.NoError(t, s.Close())
+
+	// When: the proxy restarts
+	s, err = NewPersistentStore(NewMemoryStore(), conf)
+	assert.NoError(t, err)
+	defer func() { _ = s.Close() }()
+
+	// Then
+	assert.Equal(t, []string{"spark-client", "spark-override", "spark-registered", "spark-succeeded"}, appIDs(s.List(Filter{})), "reloaded")
+	app, _ := s.Get("spark-client")
+	assert.Equal(t, "http://10.0.0.1:4040", app.BaseURL, "BaseURL")
+	app, _ = s.Get("spark-override")
+	assert.Equal(t, "spark-pi-driver", app.PodName, "PodName")
+	assert.Equal(t, "spark", app.Namespace, "Namespace")
+	app, _ = s.Get("spark-registered")
+	assert.Equal(t, "launcher-a", app.Registrant, "Registrant")
+}
+
+// putHookStore runs a hook after putting an application.
+type putHookStore struct {
+	Store
+	hook func(appID string)
+}
+
+func (s *putHookStore) Put(app *model.SparkAppInstance) {
+	s.Store.Put(app)
+	if s.hook != nil {
+		s.hook(app.AppID)
+	}
+}
+
+func Test_PersistentStore_Concurrent_Delete(t *testing.T) {
+	// Given
+	log.SetupGlobalLogger(config.Logging{Level: "info"})
+	conf := config.Persistence{Enabled: true, Path: filepath.Join(t.TempDir(), "registry.db")}
+	backend := &putHookStore{Store: NewMemoryStore()}
+	s, err := NewPersistentStore(backend, conf)
+	assert.NoError(t, err)
+	defer func() { _ = s.Close() }()
+
+	// When: the application is deleted while it is put
+	deleted := make(chan struct{})
+	backend.hook = func(appID string) {
+		go func() {
+			s.Delete(appID)
+			close(deleted)
+		}()
+		select {
+		case <-deleted:
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+	s.Put(&model.SparkAppInstance{AppID: "spark-1", Status: "Unknown", Source: model.SourceHistory})
+	<-deleted
+
+	// Then: the deleted application is not persisted
+	_, found := s.Get("spark-1")
+	assert.False(t, found, "spark-1 should be deleted")
+	err = s.db.View(func(tx *bolt.Tx) error {
+		assert.Nil(t, tx.Bucket(sparkAppsBucket).Get([]byte("spark-1")), "persisted spark-1")
+		return nil
+	})
+	assert.NoError(t, err)
+}
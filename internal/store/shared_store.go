@@ -0,0 +1,444 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/retry"
+
+	"github.com/okdp/spark-web-proxy/internal/config"
+	log "github.com/okdp/spark-web-proxy/internal/logging"
+	"github.com/okdp/spark-web-proxy/internal/model"
+)
+
+// invalidConfigMapKeyChars matches the characters not allowed in a ConfigMap key.
+var invalidConfigMapKeyChars = regexp.MustCompile(`[^-._a-zA-Z0-9]`)
+
+// maxSharedDataSize bounds the size of the ConfigMap data, below the 1 MiB limit
+// of the Kubernetes objects. The oldest entries are pruned beyond it.
+const maxSharedDataSize = 768 << 10
+
+// sharedEntry is the ConfigMap representation of a shared application.
+type sharedEntry struct {
+	App       *model.SparkAppInstance `json:"app"`
+	UpdatedAt time.Time               `json:"updatedAt"`
+	Replica   string                  `json:"replica"`
+	// Registrant is shared apart, as it is not serialized with the application.
+	Registrant string `json:"registrant,omitempty"`
+}
+
+// SharedStore shares a subset of the store between the proxy replicas through
+// a Kubernetes ConfigMap: the application statuses set by the proxy (e.g. an
+// unreachable driver UI), the applications resolved from Spark History and the
+// applications registered through the registration API.
+//
+// The applications discovered by the informers are not shared, as every replica
+// watches them: only their status overrides are. The local changes are written
+// to the ConfigMap every sync interval, without the transitions, events and health
+// of the applications, and the changes of the other replicas are applied to the
+// local store. The oldest entries are pruned when the ConfigMap grows too large.
+type SharedStore struct {
+	Store
+	clientset    kubernetes.Interface
+	namespace    string
+	name         string
+	replica      string
+	syncInterval time.Duration
+	maxAge       time.Duration
+
+	mu sync.Mutex
+	// pending holds the local changes not written yet, nil for a deletion.
+	pending map[string]*sharedEntry
+	// remote holds the last seen ConfigMap entries.
+	remote map[string]*sharedEntry
+}
+
+// NewSharedStore shares the given store between the proxy replicas using the
+// ConfigMap of the store configuration. The shared entries older than the
+// longest store TTL are pruned.
+func NewSharedStore(local Store, clientset kubernetes.Interface, conf config.Store) *SharedStore {
+	namespace := conf.Shared.Namespace
+	if namespace == "" {
+		namespace = os.Getenv("POD_NAMESPACE")
+	}
+	if namespace == "" {
+		namespace = metav1.NamespaceDefault
+	}
+
+	replica := os.Getenv("POD_NAME")
+	if replica == "" {
+		replica, _ = os.Hostname()
+	}
+
+	syncInterval := conf.Shared.SyncInterval
+	if syncInterval <= 0 {
+		syncInterval = time.Second
+	}
+
+	var maxAge time.Duration
+	for _, ttl := range conf.TTL {
+		if ttl > maxAge {
+			maxAge = ttl
+		}
+	}
+
+	return &SharedStore{
+		Store:        local,
+		clientset:    clientset,
+		namespace:    namespace,
+		name:         conf.Shared.Name,
+		replica:      replica,
+		syncInterval: syncInterval,
+		maxAge:       maxAge,
+		pending:      make(map[string]*sharedEntry),
+		remote:       make(map[string]*sharedEntry),
+	}
+}
+
+// isShared reports whether the application or its status override is shared between the replicas.
+func isShared(app *model.SparkAppInstance) bool {
+	return app.StatusOverride || isSharedSource(app.Source)
+}
+
+// isSharedSource reports whether the applications of the source are shared between the replicas.
+func isSharedSource(source string) bool {
+	return source == model.SourceProxy || source == model.SourceHistory || source == model.SourceRegistration
+}
+
+// sharedApp returns the shared representation of an application: the status
+// override of the applications discovered by the informers, or the application
+// without its transitions, events, health and driver details.
+func sharedApp(app *model.SparkAppInstance) *model.SparkAppInstance {
+	if !isSharedSource(app.Source) {
+		return &model.SparkAppInstance{AppID: app.AppID, Status: app.Status, StatusOverride: true, Source: app.Source}
+	}
+	copied := *app
+	copied.Transitions = nil
+	copied.Events = nil
+	copied.Health = nil
+	copied.Termination = nil
+	copied.DriverState = ""
+	copied.DriverReady = false
+	return &copied
+}
+
+// sharedKey returns the ConfigMap key of an application.
+func sharedKey(appID string) string {
+	return invalidConfigMapKeyChars.ReplaceAllString(appID, "_")
+}
+
+// Put implements Store. The shared applications are queued for the ConfigMap,
+// and an application discovered again by the informers removes its shared entry.
+func (s *SharedStore) Put(app *model.SparkAppInstance) {
+	s.Store.Put(app)
+	s.queue(app)
+}
+
+// Update implements Store. The shared applications are queued for the ConfigMap.
+func (s *SharedStore) Update(appID string, update func(app *model.SparkAppInstance) bool) (*model.SparkAppInstance, bool) {
+	app, updated := s.Store.Update(appID, update)
+	if updated {
+		s.queue(app)
+	}
+	return app, updated
+}
+
+// queue queues a shared application for the ConfigMap, or the removal of the
+// shared entry of an application discovered again by the informers. A status
+// override is only removed when the status of the application changed, so that
+// the local changes keeping the status (e.g. pod resyncs) do not remove it.
+func (s *SharedStore) queue(app *model.SparkAppInstance) {
+	key := sharedKey(app.AppID)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	remote, found := s.remote[key]
+	entry, queued := s.pending[key]
+	// The shared entry of another source or status is replaced
+	stale := found && (isSharedSource(remote.App.Source) != isSharedSource(app.Source) || remote.App.Status != app.Status)
+	switch {
+	case isSharedSource(app.Source) || app.StatusOverride && (queued || !found || stale):
+		s.pending[key] = &sharedEntry{App: sharedApp(app), UpdatedAt: time.Now(), Replica: s.replica, Registrant: app.Registrant}
+	case app.StatusOverride:
+		// The status override is already shared
+	case stale:
+		s.pending[key] = nil
+	case !found && queued && entry != nil:
+		// The status override was not written yet
+		delete(s.pending, key)
+	}
+}
+
+// Delete implements Store. The deletion of a shared application is queued for the ConfigMap.
+func (s *SharedStore) Delete(appID string) (*model.SparkAppInstance, bool) {
+	app, found := s.Store.Delete(appID)
+
+	key := sharedKey(appID)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, remote := s.remote[key]; remote || (found && isShared(app)) {
+		s.pending[key] = nil
+	}
+	return app, found
+}
+
+// Run watches the ConfigMap and writes the local changes every sync interval
+// until the context is done.
+func (s *SharedStore) Run(ctx context.Context) {
+	log.Info("Sharing the store through the ConfigMap %s/%s (replica: %s)", s.namespace, s.name, s.replica)
+
+	factory := informers.NewSharedInformerFactoryWithOptions(s.clientset, 5*time.Minute,
+		informers.WithNamespace(s.namespace),
+		informers.WithTweakListOptions(func(opts *metav1.ListOptions) {
+			opts.FieldSelector = fields.OneTermEqualSelector("metadata.name", s.name).String()
+		}),
+	)
+	configMapInformer := factory.Core().V1().ConfigMaps().Informer()
+
+	_, err := configMapInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: s.configMapAddedOrUpdated,
+		UpdateFunc: func(_, newObj interface{}) {
+			s.configMapAddedOrUpdated(newObj)
+		},
+		DeleteFunc: func(interface{}) {
+			s.apply(map[string]string{})
+		},
+	})
+	if err != nil {
+		log.Error("Failed to add shared store event handler: %+v", err)
+		return
+	}
+
+	factory.Start(ctx.Done())
+
+	ticker := time.NewTicker(s.syncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.flush(context.Background())
+			factory.Shutdown()
+			return
+		case <-ticker.C:
+			s.flush(ctx)
+		}
+	}
+}
+
+func (s *SharedStore) configMapAddedOrUpdated(obj interface{}) {
+	configMap, ok := obj.(*corev1.ConfigMap)
+	if !ok || configMap.Name != s.name {
+		return
+	}
+	s.apply(configMap.Data)
+}
+
+// apply applies the ConfigMap entries changed by the other replicas to the local store.
+func (s *SharedStore) apply(data map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	remote := make(map[string]*sharedEntry, len(data))
+	for key, value := range data {
+		entry := &sharedEntry{}
+		if err := json.Unmarshal([]byte(value), entry); err != nil || entry.App == nil {
+			log.Warn("Ignoring the invalid shared application '%s': %v", key, err)
+			continue
+		}
+		entry.App.Registrant = entry.Registrant
+		remote[key] = entry
+
+		if _, changed := s.pending[key]; changed || s.isExpired(entry) {
+			continue
+		}
+		if previous, found := s.remote[key]; found && previous.UpdatedAt.Equal(entry.UpdatedAt) {
+			continue
+		}
+		if entry.Replica != s.replica {
+			s.applyEntry(entry)
+			log.Debug("The shared application '%s' (%s) was updated by the replica %s", entry.App.AppID, entry.App.Status, entry.Replica)
+		}
+	}
+
+	for key, previous := range s.remote {
+		if _, found := remote[key]; found {
+			continue
+		}
+		if _, changed := s.pending[key]; changed {
+			continue
+		}
+		if app, found := s.Store.Get(previous.App.AppID); found && isSharedSource(app.Source) {
+			s.Store.Delete(app.AppID)
+			log.Debug("The shared application '%s' was removed by another replica", app.AppID)
+		}
+	}
+
+	s.remote = remote
+}
+
+// applyEntry applies a shared entry of another replica to the local store. A
+// status override is applied to the application tracked locally, if any.
+func (s *SharedStore) applyEntry(entry *sharedEntry) {
+	if !isSharedSource(entry.App.Source) {
+		_, updated := s.Store.Update(entry.App.AppID, func(app *model.SparkAppInstance) bool {
+			if app.StatusOverride && app.Status == entry.App.Status {
+				return false
+			}
+			if !app.TransitionTo(model.SparkAppStatus(entry.App.Status), entry.UpdatedAt) {
+				return false
+			}
+			app.StatusOverride = true
+			return true
+		})
+		if updated {
+			return
+		}
+		if _, tracked := s.Store.Get(entry.App.AppID); tracked {
+			return
+		}
+	}
+	s.Store.Put(entry.App)
+}
+
+// flush writes the pending local changes to the ConfigMap.
+func (s *SharedStore) flush(ctx context.Context) {
+	s.mu.Lock()
+	pending := s.pending
+	s.pending = make(map[string]*sharedEntry)
+	s.mu.Unlock()
+
+	if len(pending) == 0 {
+		return
+	}
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		configMaps := s.clientset.CoreV1().ConfigMaps(s.namespace)
+		configMap, err := configMaps.Get(ctx, s.name, metav1.GetOptions{})
+		create := apierrors.IsNotFound(err)
+		if err != nil && !create {
+			return err
+		}
+		if create {
+			configMap = &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: s.name, Namespace: s.namespace}}
+		}
+		if configMap.Data == nil {
+			configMap.Data = make(map[string]string)
+		}
+
+		s.merge(configMap.Data, pending)
+
+		if create {
+			_, err = configMaps.Create(ctx, configMap, metav1.CreateOptions{})
+			if apierrors.IsAlreadyExists(err) {
+				return apierrors.NewConflict(corev1.Resource("configmaps"), s.name, err)
+			}
+			return err
+		}
+		_, err = configMaps.Update(ctx, configMap, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		log.Error("Failed to write the shared store ConfigMap %s/%s: %+v", s.namespace, s.name, err)
+		s.requeue(pending)
+	}
+}
+
+// merge applies the pending changes to the ConfigMap data, keeping the most
+// recent entries, and prunes the expired entries and the oldest entries beyond
+// the maximum data size.
+func (s *SharedStore) merge(data map[string]string, pending map[string]*sharedEntry) {
+	for key, entry := range pending {
+		if entry == nil {
+			delete(data, key)
+			continue
+		}
+		current := &sharedEntry{}
+		if value, found := data[key]; found && json.Unmarshal([]byte(value), current) == nil && current.UpdatedAt.After(entry.UpdatedAt) {
+			continue
+		}
+		value, err := json.Marshal(entry)
+		if err != nil {
+			log.Warn("Unable to share the application '%s': %+v", entry.App.AppID, err)
+			continue
+		}
+		data[key] = string(value)
+	}
+
+	size := 0
+	entries := make(map[string]*sharedEntry, len(data))
+	for key, value := range data {
+		entry := &sharedEntry{}
+		if json.Unmarshal([]byte(value), entry) != nil || s.isExpired(entry) {
+			delete(data, key)
+			continue
+		}
+		entries[key] = entry
+		size += len(key) + len(value)
+	}
+
+	if size <= maxSharedDataSize {
+		return
+	}
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return entries[keys[i]].UpdatedAt.Before(entries[keys[j]].UpdatedAt) })
+	pruned := 0
+	for _, key := range keys {
+		if size <= maxSharedDataSize {
+			break
+		}
+		size -= len(key) + len(data[key])
+		delete(data, key)
+		pruned++
+	}
+	log.Warn("Pruned the %d oldest shared application(s) of the ConfigMap %s/%s", pruned, s.namespace, s.name)
+}
+
+// requeue queues again the changes that could not be written, unless they were changed since.
+func (s *SharedStore) requeue(pending map[string]*sharedEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, entry := range pending {
+		if _, changed := s.pending[key]; !changed {
+			s.pending[key] = entry
+		}
+	}
+}
+
+// isExpired reports whether the shared entry is older than the longest store TTL.
+func (s *SharedStore) isExpired(entry *sharedEntry) bool {
+	return s.maxAge > 0 && time.Since(entry.UpdatedAt) > s.maxAge
+}
@@ -0,0 +1,187 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/okdp/spark-web-proxy/internal/config"
+	log "github.com/okdp/spark-web-proxy/internal/logging"
+	"github.com/okdp/spark-web-proxy/internal/model"
+)
+
+func newReplica(ctx context.Context, clientset *fake.Clientset, replica string) *SharedStore {
+	s := NewSharedStore(NewMemoryStore(), clientset, config.Store{
+		TTL: map[string]time.Duration{"unknown": time.Hour},
+		Shared: config.SharedStore{
+			Enabled:      true,
+			Namespace:    "spark-web-proxy",
+			Name:         "spark-web-proxy-state",
+			SyncInterval: 10 * time.Millisecond,
+		},
+	})
+	s.replica = replica
+	go s.Run(ctx)
+	return s
+}
+
+func Test_SharedStore_Replicas(t *testing.T) {
+	// Given: two replicas tracking the same running application
+	log.SetupGlobalLogger(config.Logging{Level: "info"})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	clientset := fake.NewSimpleClientset()
+	replicaA := newReplica(ctx, clientset, "replica-a")
+	replicaB := newReplica(ctx, clientset, "replica-b")
+
+	running := &model.SparkAppInstance{AppID: "spark-1", Status: "Running", Source: model.SourcePod}
+	replicaA.Put(running)
+	replicaB.Put(running)
+
+	// When: the driver UI is unreachable from the replica A
+	MarkLost(replicaA, "spark-1")
+
+	// Then: the replica B agrees
+	assert.Eventually(t, func() bool {
+		app, found := replicaB.Get("spark-1")
+		return found && app.IsCompleted()
+	}, 5*time.Second, 10*time.Millisecond, "completed on replica B")
+
+	// When: an application is resolved from Spark History by the replica B
+	replicaB.Put(&model.SparkAppInstance{AppID: "spark-2", Status: "Unknown", Source: model.SourceHistory})
+
+	// Then
+	assert.Eventually(t, func() bool {
+		_, found := replicaA.Get("spark-2")
+		return found
+	}, 5*time.Second, 10*time.Millisecond, "history application on replica A")
+
+	// When: the application is removed by the replica B
+	replicaB.Delete("spark-2")
+
+	// Then
+	assert.Eventually(t, func() bool {
+		_, found := replicaA.Get("spark-2")
+		return !found
+	}, 5*time.Second, 10*time.Millisecond, "history application removed on replica A")
+
+	// Then: the ConfigMap only holds the status override
+	configMap, err := clientset.CoreV1().ConfigMaps("spark-web-proxy").Get(ctx, "spark-web-proxy-state", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, configMap.Data, 1, "shared entries")
+	assert.Contains(t, configMap.Data, "spark-1", "shared entries")
+}
+
+func Test_SharedStore_Registrant(t *testing.T) {
+	// Given
+	log.SetupGlobalLogger(config.Logging{Level: "info"})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	clientset := fake.NewSimpleClientset()
+	replicaA := newReplica(ctx, clientset, "replica-a")
+	replicaB := newReplica(ctx, clientset, "replica-b")
+
+	// When: an application is registered through the replica A
+	replicaA.Put(&model.SparkAppInstance{AppID: "spark-1", Status: "Running", Source: model.SourceRegistration, Registrant: "launcher-a"})
+
+	// Then: the replica B knows the token which registered it
+	assert.Eventually(t, func() bool {
+		app, found := replicaB.Get("spark-1")
+		return found && app.Registrant == "launcher-a"
+	}, 5*time.Second, 10*time.Millisecond, "registrant on replica B")
+}
+
+func Test_SharedStore_Entries(t *testing.T) {
+	// Given
+	log.SetupGlobalLogger(config.Logging{Level: "info"})
+	s := NewSharedStore(NewMemoryStore(), fake.NewSimpleClientset(), config.Store{})
+	lost := &model.SparkAppInstance{AppID: "spark-1", Status: "Lost", StatusOverride: true, Source: model.SourcePod,
+		BaseURL: "http://10.0.0.1:4040", Events: []model.PodEvent{{PodName: "driver", Reason: "BackOff"}}}
+	registered := &model.SparkAppInstance{AppID: "spark-2", Status: "Running", Source: model.SourceRegistration,
+		BaseURL: "http://10.0.0.2:4040", Transitions: []model.StateTransition{{Status: "Running"}}, Health: &model.Health{Reachable: true}}
+
+	// When
+	s.Put(lost)
+	s.Put(registered)
+
+	// Then: only the status override of the discovered application is shared
+	assert.Equal(t, &model.SparkAppInstance{AppID: "spark-1", Status: "Lost", StatusOverride: true, Source: model.SourcePod}, s.pending["spark-1"].App, "status override")
+	// Then: the shared application is shared without its transitions and health
+	shared := s.pending["spark-2"].App
+	assert.Equal(t, "http://10.0.0.2:4040", shared.BaseURL, "BaseURL")
+	assert.Nil(t, shared.Transitions, "Transitions")
+	assert.Nil(t, shared.Health, "Health")
+}
+
+func Test_SharedStore_Queue_Status_Override(t *testing.T) {
+	// Given: the shared status override of a discovered application
+	log.SetupGlobalLogger(config.Logging{Level: "info"})
+	s := NewSharedStore(NewMemoryStore(), fake.NewSimpleClientset(), config.Store{})
+	s.remote["spark-1"] = &sharedEntry{App: &model.SparkAppInstance{AppID: "spark-1", Status: "Lost", StatusOverride: true, Source: model.SourcePod}}
+
+	// When: the application is changed locally without changing its status
+	s.Put(&model.SparkAppInstance{AppID: "spark-1", Status: "Lost", StatusOverride: true, Source: model.SourcePod, PodPhase: "Running"})
+	s.Put(&model.SparkAppInstance{AppID: "spark-1", Status: "Lost", Source: model.SourcePod})
+
+	// Then: the status override is kept
+	assert.NotContains(t, s.pending, "spark-1", "pending changes")
+
+	// When: the status of the application changes
+	s.Put(&model.SparkAppInstance{AppID: "spark-1", Status: "Running", Source: model.SourcePod})
+
+	// Then: the status override is removed
+	entry, queued := s.pending["spark-1"]
+	assert.True(t, queued, "queued removal")
+	assert.Nil(t, entry, "removal")
+}
+
+func Test_SharedStore_Merge_Prune(t *testing.T) {
+	// Given: more entries than the ConfigMap can hold
+	log.SetupGlobalLogger(config.Logging{Level: "info"})
+	s := NewSharedStore(NewMemoryStore(), fake.NewSimpleClientset(), config.Store{})
+	padding := strings.Repeat("x", 1024)
+	now := time.Now()
+	pending := make(map[string]*sharedEntry)
+	for i := 0; i < 1000; i++ {
+		appID := fmt.Sprintf("spark-%04d", i)
+		pending[appID] = &sharedEntry{
+			App:       &model.SparkAppInstance{AppID: appID, AppName: padding, Status: "Unknown", Source: model.SourceHistory},
+			UpdatedAt: now.Add(time.Duration(i) * time.Second),
+		}
+	}
+	data := make(map[string]string)
+
+	// When
+	s.merge(data, pending)
+
+	// Then: the oldest entries are pruned
+	size := 0
+	for key, value := range data {
+		size += len(key) + len(value)
+	}
+	assert.LessOrEqual(t, size, maxSharedDataSize, "size")
+	assert.NotContains(t, data, "spark-0000", "oldest entry")
+	assert.Contains(t, data, "spark-0999", "most recent entry")
+}
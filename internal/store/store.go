@@ -0,0 +1,161 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+// Package store provides the registry of the Spark applications discovered
+// by the informers and resolvers, and served by the controllers.
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/okdp/spark-web-proxy/internal/model"
+)
+
+// EventType is the type of a store change.
+type EventType string
+
+const (
+	// Added is sent when an application is added to the store.
+	Added EventType = "ADDED"
+	// Updated is sent when a stored application is replaced.
+	Updated EventType = "UPDATED"
+	// Deleted is sent when an application is removed from the store.
+	Deleted EventType = "DELETED"
+)
+
+// Event is a change of a stored Spark application.
+type Event struct {
+	Type EventType
+	App  *model.SparkAppInstance
+}
+
+// Filter selects the Spark applications returned by Store.List.
+// The empty fields match any value.
+type Filter struct {
+	Cluster   string
+	Namespace string
+	PodName   string
+	User      string
+	Source    string
+	// Running restricts the selection to the running applications.
+	Running bool
+}
+
+// Store is the registry of the Spark applications, keyed by application ID.
+//
+// The applications are stored and returned by value: mutating a returned
+// application does not change the store until it is put again.
+type Store interface {
+	// Get retrieves an application by ID.
+	Get(appID string) (*model.SparkAppInstance, bool)
+	// Put adds an application or replaces the application with the same ID.
+	Put(app *model.SparkAppInstance)
+	// Update atomically changes a stored application: the update function is
+	// called with a copy of the application under the store lock, and the copy
+	// replaces the application when the function returns true. It returns the
+	// updated application and whether it was updated. The update function must
+	// not call the store.
+	Update(appID string, update func(app *model.SparkAppInstance) bool) (*model.SparkAppInstance, bool)
+	// Touch records that an application was used by a user request.
+	Touch(appID string)
+	// Delete removes an application by ID and returns the removed application.
+	Delete(appID string) (*model.SparkAppInstance, bool)
+	// List returns the applications matching the filter.
+	List(filter Filter) []*model.SparkAppInstance
+	// Watch returns the channel of the store changes, closed when the context is done.
+	Watch(ctx context.Context) <-chan Event
+}
+
+// Matches reports whether the application matches the filter.
+func (f Filter) Matches(app *model.SparkAppInstance) bool {
+	return (f.Cluster == "" || app.Cluster == f.Cluster) &&
+		(f.Namespace == "" || app.Namespace == f.Namespace) &&
+		(f.PodName == "" || app.PodName == f.PodName) &&
+		(f.User == "" || app.User == f.User) &&
+		(f.Source == "" || app.Source == f.Source) &&
+		(!f.Running || app.IsRunning())
+}
+
+// DeleteAll removes the applications matching the filter and returns them.
+func DeleteAll(s Store, filter Filter) []*model.SparkAppInstance {
+	deletedApps := make([]*model.SparkAppInstance, 0)
+	for _, app := range s.List(filter) {
+		if deleted, found := s.Delete(app.AppID); found {
+			deletedApps = append(deletedApps, deleted)
+		}
+	}
+	return deletedApps
+}
+
+// Upsert atomically merges an application into the store. The merge function is
+// called with a copy of the stored application, or with nil when the application is
+// not stored, and returns the application to store, or nil to leave the store
+// unchanged. A stored application is changed with Update, so that the concurrent
+// changes (e.g. the recorded events) are not lost, and the application is put only
+// when it is not stored yet. It returns the stored application and whether the store
+// changed. As with Update, the merge function must not call the store.
+func Upsert(s Store, appID string, merge func(existing *model.SparkAppInstance) *model.SparkAppInstance) (*model.SparkAppInstance, bool) {
+	found := false
+	app, updated := s.Update(appID, func(app *model.SparkAppInstance) bool {
+		found = true
+		merged := merge(app)
+		if merged == nil {
+			return false
+		}
+		*app = *merged
+		return true
+	})
+	if found {
+		return app, updated
+	}
+
+	app = merge(nil)
+	if app == nil {
+		return &model.SparkAppInstance{}, false
+	}
+	app.AppID = appID
+	s.Put(app)
+	return app, true
+}
+
+// Add puts an application when no application with the same ID is stored, and
+// reports whether it was added.
+func Add(s Store, app *model.SparkAppInstance) bool {
+	_, added := Upsert(s, app.AppID, func(existing *model.SparkAppInstance) *model.SparkAppInstance {
+		if existing != nil {
+			return nil
+		}
+		return app
+	})
+	return added
+}
+
+// MarkLost marks an application as lost (e.g. unreachable driver UI), adding it
+// to the store if it is not found, so that the requests are redirected to Spark History.
+// The source of a stored application is kept, and the status is flagged as overridden.
+func MarkLost(s Store, appID string) {
+	Upsert(s, appID, func(app *model.SparkAppInstance) *model.SparkAppInstance {
+		if app == nil {
+			app = &model.SparkAppInstance{AppID: appID, Source: model.SourceProxy}
+			app.TransitionTo(model.AppLost, time.Now())
+		} else if !app.TransitionTo(model.AppLost, time.Now()) {
+			return nil
+		}
+		app.StatusOverride = true
+		return app
+	})
+}
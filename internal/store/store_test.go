@@ -0,0 +1,70 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/okdp/spark-web-proxy/internal/model"
+)
+
+func Test_Upsert(t *testing.T) {
+	// Given
+	s := NewMemoryStore()
+
+	// When: the application is not stored
+	app, changed := Upsert(s, "spark-1", func(existing *model.SparkAppInstance) *model.SparkAppInstance {
+		assert.Nil(t, existing, "existing")
+		return &model.SparkAppInstance{Status: "Pending"}
+	})
+
+	// Then: the application is put under its ID
+	assert.True(t, changed, "put")
+	assert.Equal(t, "spark-1", app.AppID, "AppID")
+	stored, found := s.Get("spark-1")
+	assert.True(t, found, "stored")
+	assert.Equal(t, "Pending", stored.Status, "Status")
+
+	// When: the application is changed concurrently, then merged
+	s.Update("spark-1", func(app *model.SparkAppInstance) bool {
+		app.Events = []model.PodEvent{{Reason: "Scheduled"}}
+		return true
+	})
+	app, changed = Upsert(s, "spark-1", func(existing *model.SparkAppInstance) *model.SparkAppInstance {
+		merged := &model.SparkAppInstance{Status: "Running"}
+		merged.InheritState(existing)
+		merged.Status = "Running"
+		return merged
+	})
+
+	// Then: the concurrent change is kept
+	assert.True(t, changed, "updated")
+	assert.Equal(t, "Running", app.Status, "Status")
+	assert.Len(t, app.Events, 1, "Events")
+
+	// When: the merge function leaves the store unchanged
+	_, changed = Upsert(s, "spark-1", func(*model.SparkAppInstance) *model.SparkAppInstance {
+		return nil
+	})
+
+	// Then
+	assert.False(t, changed, "unchanged")
+	stored, _ = s.Get("spark-1")
+	assert.Equal(t, "Running", stored.Status, "Status")
+}
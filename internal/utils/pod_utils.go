@@ -18,15 +18,25 @@
 package utils
 
 import (
+	"strconv"
 	"strings"
 
 	corev1 "k8s.io/api/core/v1"
+
+	"github.com/okdp/spark-web-proxy/internal/constants"
 )
 
 // GetSparkUIPort returns the Spark UI port exposed by the given pod.
-// It looks for a container port whose name contains "ui" (case-insensitive)
+// The port is read from the spark-web-proxy.okdp.io/ui-port annotation when set.
+// Otherwise, it looks for a container port whose name contains "ui" (case-insensitive)
 // and falls back to port 4040 if none is found.
 func GetSparkUIPort(pod *corev1.Pod) int32 {
+	if value, found := pod.Annotations[constants.AnnotationUIPort]; found {
+		port, err := strconv.ParseInt(strings.TrimSpace(value), 10, 32)
+		if err == nil && port > 0 {
+			return int32(port)
+		}
+	}
 	for _, container := range pod.Spec.Containers {
 		for _, port := range container.Ports {
 			if strings.Contains(strings.ToLower(port.Name), "ui") {
@@ -34,13 +44,88 @@ func GetSparkUIPort(pod *corev1.Pod) int32 {
 			}
 		}
 	}
-	return 4040
+	return constants.DefaultSparkUIPort
+}
+
+// maxSparkUIPorts is the maximum number of Spark UI ports probed for a single pod.
+const maxSparkUIPorts = 100
+
+// GetSparkUIPorts returns all the Spark UI ports advertised by the given pod.
+// The ports are read from the spark-web-proxy.okdp.io/ui-ports annotation
+// (comma separated ports or port ranges, e.g. "4040-4045,4050") when set,
+// otherwise from the container ports whose name contains "ui" (case-insensitive).
+// At most 100 ports are returned.
+func GetSparkUIPorts(pod *corev1.Pod) []int32 {
+	ports := make([]int32, 0)
+	if value, found := pod.Annotations[constants.AnnotationUIPorts]; found {
+		for _, portRange := range strings.Split(value, ",") {
+			first, last, _ := strings.Cut(strings.TrimSpace(portRange), "-")
+			from, err := strconv.ParseInt(strings.TrimSpace(first), 10, 32)
+			if err != nil || from <= 0 {
+				continue
+			}
+			to := from
+			if last != "" {
+				if to, err = strconv.ParseInt(strings.TrimSpace(last), 10, 32); err != nil {
+					continue
+				}
+			}
+			for port := from; port <= to && len(ports) < maxSparkUIPorts; port++ {
+				ports = append(ports, int32(port))
+			}
+		}
+		return ports
+	}
+
+	for _, container := range pod.Spec.Containers {
+		for _, port := range container.Ports {
+			if strings.Contains(strings.ToLower(port.Name), "ui") && len(ports) < maxSparkUIPorts {
+				ports = append(ports, port.ContainerPort)
+			}
+		}
+	}
+	return ports
+}
+
+// HasMultipleSparkUIs reports whether the given pod may host several Spark
+// applications (e.g. notebook kernels or Spark Connect servers), i.e. it has
+// the spark-web-proxy.okdp.io/ui-ports annotation or several Spark UI ports.
+func HasMultipleSparkUIs(pod *corev1.Pod) bool {
+	if _, found := pod.Annotations[constants.AnnotationUIPorts]; found {
+		return true
+	}
+	return len(GetSparkUIPorts(pod)) > 1
+}
+
+// GetSparkUIScheme returns the Spark UI scheme of the given pod.
+// The scheme is read from the spark-web-proxy.okdp.io/ui-scheme annotation
+// and defaults to "http".
+func GetSparkUIScheme(pod *corev1.Pod) string {
+	scheme := strings.ToLower(strings.TrimSpace(pod.Annotations[constants.AnnotationUIScheme]))
+	if scheme == "https" {
+		return scheme
+	}
+	return "http"
+}
+
+// GetSparkUIBasePath returns the path under which the Spark UI is served by the given pod.
+// The path is read from the spark-web-proxy.okdp.io/ui-base-path annotation and defaults to "".
+func GetSparkUIBasePath(pod *corev1.Pod) string {
+	basePath := strings.Trim(strings.TrimSpace(pod.Annotations[constants.AnnotationUIBasePath]), "/")
+	if basePath == "" {
+		return ""
+	}
+	return "/" + basePath
 }
 
 // GetSparkAppID returns the Spark application ID from the given pod.
-// The value is read from the SPARK_APPLICATION_ID environment variable.
-// If the variable is not found, "-1" is returned.
+// The value is read from the spark-web-proxy.okdp.io/app-id annotation when set,
+// otherwise from the SPARK_APPLICATION_ID environment variable.
+// If none is found, "-1" is returned.
 func GetSparkAppID(pod *corev1.Pod) string {
+	if appID := strings.TrimSpace(pod.Annotations[constants.AnnotationAppID]); appID != "" {
+		return appID
+	}
 	for _, container := range pod.Spec.Containers {
 		for _, envVar := range container.Env {
 			if envVar.Name == "SPARK_APPLICATION_ID" {
@@ -48,5 +133,63 @@ func GetSparkAppID(pod *corev1.Pod) string {
 			}
 		}
 	}
-	return "-1"
+	return constants.UnknownSparkAppID
+}
+
+// GetSparkAppName returns the display name of the Spark application from the given pod.
+// The value is read from the spark-web-proxy.okdp.io/app-name annotation,
+// otherwise from the spark-app-name label set by spark-submit.
+func GetSparkAppName(pod *corev1.Pod) string {
+	if appName := strings.TrimSpace(pod.Annotations[constants.AnnotationAppName]); appName != "" {
+		return appName
+	}
+	return pod.Labels["spark-app-name"]
+}
+
+// GetSparkUser returns the user running the Spark application of the given pod,
+// read from the SPARK_USER environment variable set by spark-submit.
+func GetSparkUser(pod *corev1.Pod) string {
+	for _, container := range pod.Spec.Containers {
+		for _, envVar := range container.Env {
+			if envVar.Name == "SPARK_USER" {
+				return envVar.Value
+			}
+		}
+	}
+	return ""
+}
+
+// GetSparkConf returns the value of a Spark configuration property passed to the
+// containers of the given pod, as a "--conf key=value" argument or a "-Dkey=value"
+// Java option, in the container command and arguments or in the environment
+// variables (e.g. SPARK_SUBMIT_OPTS, PYSPARK_SUBMIT_ARGS). The last value wins.
+func GetSparkConf(pod *corev1.Pod, key string) (string, bool) {
+	value, found := "", false
+	lookup := func(tokens []string) {
+		for i, token := range tokens {
+			var property string
+			switch {
+			case token == "--conf" && i+1 < len(tokens):
+				property = tokens[i+1]
+			case strings.HasPrefix(token, "--conf="):
+				property = strings.TrimPrefix(token, "--conf=")
+			case strings.HasPrefix(token, "-D"):
+				property = strings.TrimPrefix(token, "-D")
+			default:
+				continue
+			}
+			if name, propertyValue, ok := strings.Cut(strings.Trim(property, `"'`), "="); ok && name == key {
+				value, found = propertyValue, true
+			}
+		}
+	}
+
+	for _, container := range pod.Spec.Containers {
+		lookup(container.Command)
+		lookup(container.Args)
+		for _, envVar := range container.Env {
+			lookup(strings.Fields(envVar.Value))
+		}
+	}
+	return value, found
 }
@@ -0,0 +1,178 @@
+/*
+ *    Copyright 2026 okdp.io
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package utils
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newDriverPod(annotations map[string]string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "spark-pi-driver",
+			Labels:      map[string]string{"spark-app-name": "spark-pi"},
+			Annotations: annotations,
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name:  "spark-kubernetes-driver",
+				Ports: []corev1.ContainerPort{{Name: "spark-ui", ContainerPort: 4041}},
+				Env: []corev1.EnvVar{
+					{Name: "SPARK_APPLICATION_ID", Value: "spark-123"},
+					{Name: "SPARK_USER", Value: "alice"},
+				},
+			}},
+		},
+	}
+}
+
+func TestSparkDriverPodAnnotations(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		port        int32
+		scheme      string
+		basePath    string
+		appID       string
+		appName     string
+	}{
+		{
+			name:     "No annotations",
+			port:     4041,
+			scheme:   "http",
+			basePath: "",
+			appID:    "spark-123",
+			appName:  "spark-pi",
+		},
+		{
+			name: "All annotations",
+			annotations: map[string]string{
+				"spark-web-proxy.okdp.io/ui-port":      "4050",
+				"spark-web-proxy.okdp.io/ui-scheme":    "HTTPS",
+				"spark-web-proxy.okdp.io/ui-base-path": "/notebook/ui/",
+				"spark-web-proxy.okdp.io/app-id":       "spark-456",
+				"spark-web-proxy.okdp.io/app-name":     "My notebook",
+			},
+			port:     4050,
+			scheme:   "https",
+			basePath: "/notebook/ui",
+			appID:    "spark-456",
+			appName:  "My notebook",
+		},
+		{
+			name: "Invalid port annotation",
+			annotations: map[string]string{
+				"spark-web-proxy.okdp.io/ui-port":   "ui",
+				"spark-web-proxy.okdp.io/ui-scheme": "ftp",
+			},
+			port:     4041,
+			scheme:   "http",
+			basePath: "",
+			appID:    "spark-123",
+			appName:  "spark-pi",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := newDriverPod(tt.annotations)
+			if got := GetSparkUIPort(pod); got != tt.port {
+				t.Errorf("GetSparkUIPort() = %d, want %d", got, tt.port)
+			}
+			if got := GetSparkUIScheme(pod); got != tt.scheme {
+				t.Errorf("GetSparkUIScheme() = %q, want %q", got, tt.scheme)
+			}
+			if got := GetSparkUIBasePath(pod); got != tt.basePath {
+				t.Errorf("GetSparkUIBasePath() = %q, want %q", got, tt.basePath)
+			}
+			if got := GetSparkAppID(pod); got != tt.appID {
+				t.Errorf("GetSparkAppID() = %q, want %q", got, tt.appID)
+			}
+			if got := GetSparkAppName(pod); got != tt.appName {
+				t.Errorf("GetSparkAppName() = %q, want %q", got, tt.appName)
+			}
+			if got := GetSparkUser(pod); got != "alice" {
+				t.Errorf("GetSparkUser() = %q, want %q", got, "alice")
+			}
+		})
+	}
+}
+
+func TestGetSparkUIPorts(t *testing.T) {
+	tests := []struct {
+		name     string
+		pod      *corev1.Pod
+		expected []int32
+		multiple bool
+	}{
+		{
+			name:     "Single spark ui port",
+			pod:      newDriverPod(nil),
+			expected: []int32{4041},
+			multiple: false,
+		},
+		{
+			name:     "Ports and port ranges annotation",
+			pod:      newDriverPod(map[string]string{"spark-web-proxy.okdp.io/ui-ports": "4040-4042, 4050,invalid"}),
+			expected: []int32{4040, 4041, 4042, 4050},
+			multiple: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := GetSparkUIPorts(tt.pod)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("GetSparkUIPorts() = %v, want %v", got, tt.expected)
+			}
+			if multiple := HasMultipleSparkUIs(tt.pod); multiple != tt.multiple {
+				t.Errorf("HasMultipleSparkUIs() = %v, want %v", multiple, tt.multiple)
+			}
+		})
+	}
+}
+
+func TestGetSparkConf(t *testing.T) {
+	pod := newDriverPod(nil)
+	pod.Spec.Containers[0].Args = []string{"driver", "--conf", "spark.ui.reverseProxy=true", "--conf=spark.ui.proxyBase=/ui"}
+	pod.Spec.Containers[0].Env = append(pod.Spec.Containers[0].Env,
+		corev1.EnvVar{Name: "SPARK_SUBMIT_OPTS", Value: "-Xmx1g -Dspark.ui.reverseProxyUrl=https://gateway/spark"})
+
+	tests := []struct {
+		key   string
+		value string
+		found bool
+	}{
+		{key: "spark.ui.reverseProxy", value: "true", found: true},
+		{key: "spark.ui.proxyBase", value: "/ui", found: true},
+		{key: "spark.ui.reverseProxyUrl", value: "https://gateway/spark", found: true},
+		{key: "spark.ui.port", value: "", found: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			value, found := GetSparkConf(pod, tt.key)
+			if value != tt.value || found != tt.found {
+				t.Errorf("GetSparkConf() = (%q, %v), want (%q, %v)", value, found, tt.value, tt.found)
+			}
+		})
+	}
+}